@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bringauto/modules/bringauto_config"
+	"bringauto/modules/bringauto_context"
+	"bringauto/modules/bringauto_docker"
+	"bringauto/modules/bringauto_package"
+	"slices"
+)
+
+// contextPackages
+// Information about Packages in the context needed by the build.
+type contextPackages struct {
+	// imageId ID of the docker image the Packages are built in
+	imageId string
+	// cacheKeys cache keys of Packages which are built for the image, the key of the map is jobKey
+	cacheKeys map[string]string
+	// cacheKeyErrors errors of Packages whose cache key cannot be computed (e.g. a patch file is
+	// missing), the key of the map is jobKey. Builds of these Packages fail.
+	cacheKeyErrors map[string]error
+	// configs all Configs in the context
+	configs []*bringauto_config.Config
+}
+
+// loadContextPackages
+// Loads all Packages in the context and computes cache keys of Packages which are built for
+// the image given in cmdLine (see computeCacheKeys).
+func loadContextPackages(
+	cmdLine        *BuildPackageCmdLineArgs,
+	contextPath    string,
+	platformString *bringauto_package.PlatformString,
+) (*contextPackages, error) {
+	dockerImage := bringauto_docker.DockerImage{
+		ImageName: *cmdLine.DockerImageName,
+	}
+	imageId, err := dockerImage.GetImageId()
+	if err != nil {
+		return nil, err
+	}
+
+	contextManager := bringauto_context.ContextManager{
+		ContextPath: contextPath,
+	}
+	packageJsonPathMap, err := contextManager.GetAllPackagesJsonDefPaths()
+	if err != nil {
+		return nil, err
+	}
+	defsMap := make(ConfigMapType)
+	for _, packageJsonPathList := range packageJsonPathMap {
+		addConfigsToDefsMap(&defsMap, packageJsonPathList)
+	}
+	depsList := buildDepList{}
+	configList, err := depsList.TopologicalSort(defsMap)
+	if err != nil {
+		return nil, err
+	}
+
+	packages := contextPackages{
+		imageId: imageId,
+		configs: configList,
+	}
+	packages.computeCacheKeys(cmdLine, platformString)
+	return &packages, nil
+}
+
+// computeCacheKeys
+// Computes cache keys of Packages which are built for the image given in cmdLine. The cache key
+// of a Package depends on cache keys of its dependencies and on the build options from cmdLine,
+// so Packages are processed in topological order. If the cache key of a Package cannot be
+// computed, the error is stored to cacheKeyErrors and other Packages are not affected.
+func (packages *contextPackages) computeCacheKeys(
+	cmdLine        *BuildPackageCmdLineArgs,
+	platformString *bringauto_package.PlatformString,
+) {
+	packages.cacheKeys = make(map[string]string)
+	packages.cacheKeyErrors = make(map[string]error)
+	for _, config := range packages.configs {
+		if !slices.Contains(config.DockerMatrix.ImageNames, *cmdLine.DockerImageName) {
+			continue
+		}
+		var dependencyKeys []string
+		for _, dependency := range config.GetDependencies() {
+			depPack, found := packages.getDependency(config, dependency)
+			if !found {
+				// Dependency is not in the context, only its name identifies it
+				dependencyKeys = append(dependencyKeys, dependency.Name)
+				continue
+			}
+			depKey, found := packages.cacheKeys[jobKey(depPack)]
+			if !found {
+				// Dependency is not built for the image, only its name and version identify it
+				depKey = jobKey(depPack)
+			}
+			dependencyKeys = append(dependencyKeys, depKey)
+		}
+		key := jobKey(config.Package)
+		options, err := getBuildOptions(cmdLine, config)
+		if err != nil {
+			packages.cacheKeyErrors[key] = err
+			continue
+		}
+		cacheKey, err := config.GetCacheKey(packages.imageId, platformString, dependencyKeys, options)
+		if err != nil {
+			packages.cacheKeyErrors[key] = err
+			continue
+		}
+		packages.cacheKeys[key] = cacheKey
+	}
+}
+
+// getBuildOptions
+// Returns options given in cmdLine which change the build result of the config.
+func getBuildOptions(cmdLine *BuildPackageCmdLineArgs, config *bringauto_config.Config) (bringauto_config.BuildOptions, error) {
+	var options bringauto_config.BuildOptions
+	if *cmdLine.Reproducible {
+		epoch, err := getSourceDateEpoch(config.Env)
+		if err != nil {
+			return options, err
+		}
+		options.Reproducible = true
+		options.SourceDateEpoch = epoch
+	}
+	// Debug Packages keep their debug symbols
+	options.SplitDebugSymbols = *cmdLine.SplitDebugSymbols && !config.Package.IsDebug
+	return options, nil
+}
+
+// getDependency
+// Returns the Package of the dependency version selected for the config from the context. Returns
+// false if the dependency is not in the context.
+func (packages *contextPackages) getDependency(
+	config     *bringauto_config.Config,
+	dependency bringauto_config.Dependency,
+) (bringauto_package.Package, bool) {
+	return selectDependency(config, dependency, packages.configs)
+}
+
+// getSysrootPackages
+// Returns Packages which are in the build sysroot of the config - the selected versions of its
+// dependencies and, recursively, the selected versions of their runtime dependencies. Dependencies
+// which are not in the context are not returned.
+func (packages *contextPackages) getSysrootPackages(config *bringauto_config.Config) []bringauto_package.Package {
+	var sysrootPackages []bringauto_package.Package
+	added := make(map[string]struct{})
+	var addDependencies func(config *bringauto_config.Config, dependencies []bringauto_config.Dependency)
+	addDependencies = func(config *bringauto_config.Config, dependencies []bringauto_config.Dependency) {
+		for _, dependency := range dependencies {
+			selected, err := bringauto_context.SelectDependency(config, dependency, packages.configs)
+			if err != nil || len(selected) == 0 {
+				continue
+			}
+			depConfig := selected[0]
+			key := jobKey(depConfig.Package)
+			if _, found := added[key]; found {
+				continue
+			}
+			added[key] = struct{}{}
+			sysrootPackages = append(sysrootPackages, depConfig.Package)
+			addDependencies(depConfig, depConfig.GetRuntimeDependencies())
+		}
+	}
+	addDependencies(config, config.GetDependencies())
+	return sysrootPackages
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const (
+	// Prefix of the build journal file name, the file is stored in the working directory
+	buildJournalPrefix = "build_journal_"
+)
+
+// buildJournal
+// Records Packages which were successfully built and committed to the Git LFS repository, so an
+// interrupted or failed build can be resumed. The journal is stored after each recorded Package.
+type buildJournal struct {
+	// ImageName name of the docker image the Packages are built for
+	ImageName string
+	// Completed maps jobKey of the successfully built Package to its cache key
+	Completed map[string]string
+	path      string
+	lock      sync.Mutex
+}
+
+// getBuildJournalPath
+// Returns path of the build journal for imageName.
+func getBuildJournalPath(imageName string) string {
+	return buildJournalPrefix + imageName + ".json"
+}
+
+// newBuildJournal
+// Returns empty build journal for imageName. Journal of previous build is overwritten when the
+// first Package is recorded.
+func newBuildJournal(imageName string) *buildJournal {
+	return &buildJournal{
+		ImageName: imageName,
+		Completed: map[string]string{},
+		path:      getBuildJournalPath(imageName),
+	}
+}
+
+// loadBuildJournal
+// Loads build journal of previous build for imageName. If there is no journal, empty journal is
+// returned.
+func loadBuildJournal(imageName string) (*buildJournal, error) {
+	journal := newBuildJournal(imageName)
+	mbytes, err := os.ReadFile(journal.path)
+	if os.IsNotExist(err) {
+		return journal, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(mbytes, journal)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse build journal %s - %s", journal.path, err)
+	}
+	if journal.ImageName != imageName {
+		return nil, fmt.Errorf("build journal %s is for image '%s'", journal.path, journal.ImageName)
+	}
+	if journal.Completed == nil {
+		journal.Completed = map[string]string{}
+	}
+	return journal, nil
+}
+
+// isCompleted
+// Returns true if the Package identified by key was successfully built with the same cacheKey.
+func (journal *buildJournal) isCompleted(key string, cacheKey string) bool {
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+	completedCacheKey, found := journal.Completed[key]
+	return found && completedCacheKey == cacheKey
+}
+
+// markCompleted
+// Records the Package identified by key as successfully built and stores the journal.
+func (journal *buildJournal) markCompleted(key string, cacheKey string) error {
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+	journal.Completed[key] = cacheKey
+	mbytes, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(journal.path, mbytes, 0644)
+}
+
+// hasCompleted
+// Returns true if at least one Package is recorded as successfully built.
+func (journal *buildJournal) hasCompleted() bool {
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+	return len(journal.Completed) > 0
+}
+
+// remove
+// Removes the stored journal. Should be called when all Packages are successfully built.
+func (journal *buildJournal) remove() error {
+	err := os.Remove(journal.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
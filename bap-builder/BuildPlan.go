@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bringauto/modules/bringauto_config"
+	"bringauto/modules/bringauto_log"
+	"bringauto/modules/bringauto_package"
+	"bringauto/modules/bringauto_repository"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+const (
+	// Build plan is printed as human readable text
+	PlanFormatText = "text"
+	// Build plan is printed as JSON
+	PlanFormatJSON = "json"
+	// Prefix of the file name with the platform string of the image determined by the last build,
+	// the file is stored in the working directory
+	platformStringPrefix = "platform_string_"
+)
+
+// PlanEntry
+// One Package in the build plan.
+type PlanEntry struct {
+	// Name of the Package
+	Name string
+	// FullPackageName name of the Package archive without extension
+	FullPackageName string
+	IsDebug         bool
+	DependsOn       []string
+	// RepositoryPath path of the Package archive in the Package Repository
+	RepositoryPath string
+	// Skipped true if the Package would not be built
+	Skipped bool
+	// SkipReason reason why the Package would not be built, empty if the Package would be built
+	SkipReason string
+}
+
+// BuildPlan
+// List of Packages in the order in which they would be built by build-package.
+type BuildPlan struct {
+	ImageName      string
+	PlatformString string
+	Packages       []PlanEntry
+}
+
+// PlanBuild
+// Resolves Packages selected by cmdLine and prints the build plan without building anything. No
+// container of the image is started, the platform string stored by the last build for the image
+// is used (see loadPlatformString).
+func PlanBuild(cmdLine *BuildPackageCmdLineArgs, contextPath string) error {
+	platformString, err := loadPlatformString(*cmdLine.DockerImageName)
+	if err != nil {
+		return err
+	}
+	configList, err := prepareBuildConfigs(cmdLine, contextPath, platformString)
+	if err != nil {
+		return err
+	}
+	packageFormat, err := getPackageFormat(contextPath)
+	if err != nil {
+		return err
+	}
+	repo := bringauto_repository.GitLFSRepository{
+		GitRepoPath: *cmdLine.OutputDir,
+		Format:      packageFormat,
+	}
+	var cacheKeys map[string]string
+	if *cmdLine.UseCache || *cmdLine.Resume {
+		packages, err := loadContextPackages(cmdLine, contextPath, platformString)
+		if err != nil {
+			return err
+		}
+		cacheKeys = packages.cacheKeys
+	}
+	var journal *buildJournal
+	if *cmdLine.Resume {
+		journal, err = loadBuildJournal(*cmdLine.DockerImageName)
+		if err != nil {
+			return err
+		}
+	}
+	plan := createBuildPlan(configList, *cmdLine.DockerImageName, platformString, &repo, cacheKeys, *cmdLine.UseCache, journal)
+	return plan.print(os.Stdout, *cmdLine.PlanFormat)
+}
+
+// getPlatformStringPath
+// Returns path of the file with the platform string of imageName.
+func getPlatformStringPath(imageName string) string {
+	return platformStringPrefix + imageName + ".json"
+}
+
+// savePlatformString
+// Stores platformString determined for imageName, so the build plan for the image can be created
+// without starting a container.
+func savePlatformString(imageName string, platformString *bringauto_package.PlatformString) error {
+	mbytes, err := json.MarshalIndent(platformString.String, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(getPlatformStringPath(imageName), mbytes, 0644)
+}
+
+// loadPlatformString
+// Returns the platform string of imageName stored by savePlatformString. If there is none (no
+// Package was built for the image yet), the platform string is unknown and a warning is printed.
+func loadPlatformString(imageName string) (*bringauto_package.PlatformString, error) {
+	platformString := bringauto_package.PlatformString{
+		Mode: bringauto_package.ModeExplicit,
+	}
+	platformStringPath := getPlatformStringPath(imageName)
+	mbytes, err := os.ReadFile(platformStringPath)
+	if os.IsNotExist(err) {
+		logger := bringauto_log.GetLogger()
+		logger.Warn("Platform string of image %s is not known, run build-package for the image first", imageName)
+		platformString.String = bringauto_package.PlatformStringExplicit{
+			DistroName:    "unknown",
+			DistroRelease: "unknown",
+			Machine:       "unknown",
+		}
+		return &platformString, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(mbytes, &platformString.String)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse platform string %s - %s", platformStringPath, err)
+	}
+	return &platformString, nil
+}
+
+// createBuildPlan
+// Creates build plan for configList which must be topologically sorted. Packages which are not
+// built for imageName are marked as skipped. Packages which are in the repo with the same cache
+// key are marked as skipped too if useCache is true or if they are recorded in the journal of the
+// resumed build (journal is nil if the build is not resumed).
+func createBuildPlan(
+	configList     []*bringauto_config.Config,
+	imageName      string,
+	platformString *bringauto_package.PlatformString,
+	repo           *bringauto_repository.GitLFSRepository,
+	cacheKeys      map[string]string,
+	useCache       bool,
+	journal        *buildJournal,
+) BuildPlan {
+	plan := BuildPlan{
+		ImageName:      imageName,
+		PlatformString: platformString.Serialize(),
+		Packages:       []PlanEntry{},
+	}
+	for _, config := range configList {
+		pack := config.Package
+		pack.PlatformString = *platformString
+		key := jobKey(pack)
+		entry := PlanEntry{
+			Name:            pack.Name,
+			FullPackageName: pack.GetFullPackageName(),
+			IsDebug:         pack.IsDebug,
+			DependsOn:       config.GetDependsOnNames(),
+			RepositoryPath:  repo.GetPackageArchivePath(pack),
+		}
+		if !slices.Contains(config.DockerMatrix.ImageNames, imageName) {
+			entry.Skipped = true
+			entry.SkipReason = "not built for image " + imageName
+		} else if repo.IsPackageCached(pack, cacheKeys[key]) {
+			if journal != nil && journal.isCompleted(key, cacheKeys[key]) {
+				entry.Skipped = true
+				entry.SkipReason = "already built by the resumed build"
+			} else if useCache {
+				entry.Skipped = true
+				entry.SkipReason = "already in Package Repository with the same cache key"
+			}
+		}
+		plan.Packages = append(plan.Packages, entry)
+	}
+	return plan
+}
+
+// print
+// Prints the plan to writer in the given format.
+func (plan *BuildPlan) print(writer io.Writer, format string) error {
+	switch format {
+	case PlanFormatJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(plan)
+	case PlanFormatText, "":
+		return plan.printText(writer)
+	default:
+		return fmt.Errorf("unsupported plan format: %s", format)
+	}
+}
+
+// printText
+// Prints the plan to writer as human readable text.
+func (plan *BuildPlan) printText(writer io.Writer) error {
+	_, err := fmt.Fprintf(writer, "Build plan for image %s (%s):\n", plan.ImageName, plan.PlatformString)
+	if err != nil {
+		return err
+	}
+	order := 0
+	skippedCount := 0
+	for _, entry := range plan.Packages {
+		if entry.Skipped {
+			skippedCount++
+			_, err = fmt.Fprintf(writer, "  -  %s (skipped: %s)\n", entry.FullPackageName, entry.SkipReason)
+		} else {
+			order++
+			_, err = fmt.Fprintf(writer, "  %d. %s -> %s\n", order, entry.FullPackageName, entry.RepositoryPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(writer, "%d packages to build, %d skipped\n", order, skippedCount)
+	return err
+}
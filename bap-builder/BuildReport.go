@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bringauto/modules/bringauto_build"
+	"bringauto/modules/bringauto_log"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	// Package was built in the Docker container
+	BuildStatusBuilt = "built"
+	// Package was not built because it is already in the Package Repository (cache or resume)
+	BuildStatusCached = "cached"
+	// Build of the Package failed
+	BuildStatusFailed = "failed"
+	// Package was not built because some of its dependencies failed
+	BuildStatusSkipped = "skipped-dependency-failed"
+)
+
+const (
+	// Copy of the built Package to the Package Repository and to the sysroot, complements
+	// build steps of the bringauto_build package
+	BuildStepCopy = "copy"
+)
+
+// ReportEntry
+// Result of one Package build.
+type ReportEntry struct {
+	Name            string
+	FullPackageName string
+	IsDebug         bool
+	// Status one of BuildStatusBuilt, BuildStatusCached, BuildStatusFailed, BuildStatusSkipped
+	Status string
+	// Error message of the failed build or name of the failed dependency, empty otherwise
+	Error string
+	// FailedStep step in which the build failed (clone, cmake, make, install, download, ...),
+	// empty if the build did not fail
+	FailedStep string
+	// LogFilePath path of the build log of the Package, empty if the Package was not built
+	LogFilePath string
+	// Duration of the build in seconds
+	Duration float64
+	// order position of the Package in the build order
+	order int
+}
+
+// BuildReport
+// Results of all Package builds of one build-package run.
+type BuildReport struct {
+	ImageName string
+	Built     int
+	Cached    int
+	Failed    int
+	Skipped   int
+	Packages  []ReportEntry
+	lock      sync.Mutex
+}
+
+// newBuildReport
+// Returns empty report for imageName.
+func newBuildReport(imageName string) *BuildReport {
+	return &BuildReport{
+		ImageName: imageName,
+		Packages:  []ReportEntry{},
+	}
+}
+
+// add
+// Adds entry to the report. Entries can be added from multiple goroutines.
+func (report *BuildReport) add(entry ReportEntry) {
+	report.lock.Lock()
+	defer report.lock.Unlock()
+	switch entry.Status {
+	case BuildStatusBuilt:
+		report.Built++
+	case BuildStatusCached:
+		report.Cached++
+	case BuildStatusFailed:
+		report.Failed++
+	case BuildStatusSkipped:
+		report.Skipped++
+	}
+	report.Packages = append(report.Packages, entry)
+	sort.SliceStable(report.Packages, func(i, j int) bool {
+		return report.Packages[i].order < report.Packages[j].order
+	})
+}
+
+// newReportEntry
+// Returns report entry of the job with the given status.
+func newReportEntry(job *buildJob, order int, status string, err error, duration time.Duration) ReportEntry {
+	entry := ReportEntry{
+		Name:     job.config.Package.Name,
+		IsDebug:  job.config.Package.IsDebug,
+		Status:   status,
+		Duration: duration.Seconds(),
+		order:    order,
+	}
+	if len(job.builds) > 0 {
+		build := job.builds[0]
+		entry.FullPackageName = build.Package.GetFullPackageName()
+		if status == BuildStatusBuilt || status == BuildStatusFailed {
+			contextLogger := bringauto_log.GetLogger().CreateContextLogger(build.Docker.ImageName,
+				build.Package.GetFullPackageName(), bringauto_log.BuildChainContext)
+			entry.LogFilePath = contextLogger.GetFilePath()
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	var buildError *bringauto_build.BuildError
+	if status == BuildStatusFailed && errors.As(err, &buildError) {
+		entry.FailedStep = buildError.Step
+	}
+	return entry
+}
+
+// printSummary
+// Prints table with results of all Package builds.
+func (report *BuildReport) printSummary() {
+	report.lock.Lock()
+	defer report.lock.Unlock()
+	logger := bringauto_log.GetLogger()
+
+	var buffer bytes.Buffer
+	writer := tabwriter.NewWriter(&buffer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "PACKAGE\tSTATUS\tLOG FILE / REASON")
+	for _, entry := range report.Packages {
+		detail := entry.LogFilePath
+		if entry.Status == BuildStatusSkipped {
+			detail = entry.Error
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", entry.FullPackageName, entry.Status, detail)
+	}
+	writer.Flush()
+
+	logger.Info("Build summary (built: %d, cached: %d, failed: %d, skipped: %d):",
+		report.Built, report.Cached, report.Failed, report.Skipped)
+	for _, line := range strings.Split(strings.TrimRight(buffer.String(), "\n"), "\n") {
+		logger.InfoIndent("%s", line)
+	}
+}
+
+// saveJSON
+// Stores the report as JSON to reportPath.
+func (report *BuildReport) saveJSON(reportPath string) error {
+	report.lock.Lock()
+	defer report.lock.Unlock()
+	mbytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(reportPath, mbytes, 0644)
+}
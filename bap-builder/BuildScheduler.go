@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bringauto/modules/bringauto_build"
+	"bringauto/modules/bringauto_config"
+	"bringauto/modules/bringauto_context"
+	"bringauto/modules/bringauto_log"
+	"bringauto/modules/bringauto_package"
+	"bringauto/modules/bringauto_repository"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Prefix of the local install directory used by builds running in parallel
+	localInstallDirPrefix = "localInstall"
+	// Environment variable with the timestamp of reproducible Package archives
+	sourceDateEpochEnvConst = "SOURCE_DATE_EPOCH"
+)
+
+// buildJob
+// Represents all builds of one Package Config scheduled by buildScheduler.
+type buildJob struct {
+	config *bringauto_config.Config
+	builds []bringauto_build.Build
+	// dependsOn keys of jobs which must be successfully finished before the job is started
+	dependsOn []string
+}
+
+// jobRunner
+// Builds the job in the given slot. Returns status of the build.
+type jobRunner func(job *buildJob, slot int) (string, error)
+
+// buildResult
+// Result of one finished buildJob.
+type buildResult struct {
+	job    *buildJob
+	slot   int
+	status string
+	err    error
+}
+
+// buildScheduler
+// Builds Packages in Docker containers. Up to jobsCount Packages are built at the same time. Each
+// running build has its own slot which determines the local install directory. A Package is
+// started only when all its dependencies are built and copied to the Git LFS repository and to
+// the sysroot.
+type buildScheduler struct {
+	// jobsCount maximum number of Packages built at the same time
+	jobsCount      int
+	platformString *bringauto_package.PlatformString
+	repo           bringauto_repository.GitLFSRepository
+	// copyLock serializes copying of built Packages to the Git LFS repository and to the sysroot
+	copyLock       sync.Mutex
+	// imageName name of the docker image the Packages are built in
+	imageName      string
+	// packages information about Packages in the context (cache keys, versions)
+	packages       *contextPackages
+	// useCache if true, Packages already built with the same cache key are not built again
+	useCache       bool
+	// journal records successfully built Packages
+	journal        *buildJournal
+	// resume if true, Packages recorded in the journal are not built again
+	resume         bool
+	// keepGoing if true, a failed build does not stop other builds, only Packages which depend
+	// on the failed Package are skipped
+	keepGoing      bool
+	// report results of all Package builds
+	report         *BuildReport
+	// forbidBranchRevision if true, builds of Packages with a Git Revision which is a branch fail
+	forbidBranchRevision bool
+	// reproducible if true, Package archives are created reproducibly
+	reproducible   bool
+	// nativeFormat format of the native package created next to the Package archive, no native
+	// package if empty
+	nativeFormat   bringauto_package.NativeFormat
+	// nativePrefix installation prefix of the native package files
+	nativePrefix   string
+	// splitDebugSymbols if true, debug symbols of Release Packages are split to the debug
+	// symbols archive
+	splitDebugSymbols bool
+}
+
+// newBuildScheduler
+// Creates buildScheduler based on cmdLine. Loads all Packages in the context and computes cache
+// keys of Packages which are built for the image.
+func newBuildScheduler(
+	cmdLine        *BuildPackageCmdLineArgs,
+	contextPath    string,
+	platformString *bringauto_package.PlatformString,
+	repo           bringauto_repository.GitLFSRepository,
+	journal        *buildJournal,
+) (*buildScheduler, error) {
+	packages, err := loadContextPackages(cmdLine, contextPath, platformString)
+	if err != nil {
+		return nil, err
+	}
+	scheduler := buildScheduler{
+		jobsCount:      *cmdLine.Jobs,
+		platformString: platformString,
+		repo:           repo,
+		imageName:      *cmdLine.DockerImageName,
+		packages:       packages,
+		useCache:       *cmdLine.UseCache,
+		journal:        journal,
+		resume:         *cmdLine.Resume,
+		keepGoing:      *cmdLine.KeepGoing,
+		report:         newBuildReport(*cmdLine.DockerImageName),
+		forbidBranchRevision: *cmdLine.ForbidBranchRevision,
+		reproducible:   *cmdLine.Reproducible,
+		nativePrefix:   *cmdLine.NativePrefix,
+		splitDebugSymbols: *cmdLine.SplitDebugSymbols,
+	}
+	if *cmdLine.NativePackage != "" {
+		scheduler.nativeFormat, err = bringauto_package.ParseNativeFormat(*cmdLine.NativePackage, platformString)
+		if err != nil {
+			return nil, err
+		}
+		if !path.IsAbs(scheduler.nativePrefix) {
+			return nil, fmt.Errorf("native package prefix '%s' is not an absolute path", scheduler.nativePrefix)
+		}
+	}
+	return &scheduler, nil
+}
+
+// jobKey
+// Returns key which identifies Package build in the scheduler - the Package name, version and build
+// type. Different versions of one Package are built separately. Debug and Release Packages are
+// built into different sysroots, so they are independent of each other.
+func jobKey(pack bringauto_package.Package) string {
+	return pack.Name + ":" + pack.VersionTag + ":" + strconv.FormatBool(pack.IsDebug)
+}
+
+// selectDependency
+// Returns the Package of the dependency version selected for the config from candidates
+// by bringauto_context.SelectDependency. Returns false if there is no such Package in candidates.
+func selectDependency(
+	config     *bringauto_config.Config,
+	dependency bringauto_config.Dependency,
+	candidates []*bringauto_config.Config,
+) (bringauto_package.Package, bool) {
+	selected, err := bringauto_context.SelectDependency(config, dependency, candidates)
+	if err != nil || len(selected) == 0 {
+		return bringauto_package.Package{}, false
+	}
+	return selected[0].Package, true
+}
+
+// createBuildJobs
+// Creates build jobs for all configs which have a build for imageName. The configList must be
+// topologically sorted. Dependencies which are not part of configList are considered as already
+// built.
+func createBuildJobs(configList []*bringauto_config.Config, imageName string, platformString *bringauto_package.PlatformString) []*buildJob {
+	var jobs []*buildJob
+	for _, config := range configList {
+		builds := config.GetBuildStructure(imageName, platformString)
+		if len(builds) == 0 {
+			continue
+		}
+		jobs = append(jobs, &buildJob{config: config, builds: builds})
+	}
+	setJobDependencies(jobs, configList)
+	return jobs
+}
+
+// setJobDependencies
+// Sets dependsOn of all jobs to the jobs of dependency versions selected from configList.
+// Dependencies which have no job are considered as already built.
+func setJobDependencies(jobs []*buildJob, configList []*bringauto_config.Config) {
+	jobKeys := make(map[string]struct{})
+	for _, job := range jobs {
+		jobKeys[jobKey(job.config.Package)] = struct{}{}
+	}
+	for _, job := range jobs {
+		for _, dependency := range job.config.GetDependencies() {
+			depPack, found := selectDependency(job.config, dependency, configList)
+			if !found {
+				continue
+			}
+			depKey := jobKey(depPack)
+			_, found = jobKeys[depKey]
+			if found && !slices.Contains(job.dependsOn, depKey) {
+				job.dependsOn = append(job.dependsOn, depKey)
+			}
+		}
+	}
+}
+
+// failedDependency
+// Returns key of the first dependency of the job which is in failed, returns empty string if there
+// is none.
+func (job *buildJob) failedDependency(failed map[string]struct{}) string {
+	for _, dep := range job.dependsOn {
+		_, found := failed[dep]
+		if found {
+			return dep
+		}
+	}
+	return ""
+}
+
+// isReady
+// Returns true if all dependencies of the job are finished.
+func (job *buildJob) isReady(finished map[string]struct{}) bool {
+	for _, dep := range job.dependsOn {
+		_, found := finished[dep]
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// run
+// Builds all given jobs by runJobs.
+func (scheduler *buildScheduler) run(jobs []*buildJob) error {
+	return scheduler.runJobs(jobs, scheduler.runJob)
+}
+
+// runJobs
+// Runs all given jobs by runner. Jobs are started in the given order as soon as their dependencies
+// are finished and there is a free slot. If any build fails, no other job is started, running jobs
+// are waited for and the first error is returned. If keepGoing is set, other jobs are still
+// started, only jobs which depend on the failed job (directly or indirectly) are skipped. Results
+// of all jobs are added to the report.
+func (scheduler *buildScheduler) runJobs(jobs []*buildJob, runner jobRunner) error {
+	jobsCount := scheduler.jobsCount
+	if jobsCount < 1 {
+		jobsCount = 1
+	}
+	var freeSlots []int
+	for slot := 0; slot < jobsCount; slot++ {
+		freeSlots = append(freeSlots, slot)
+	}
+	jobOrder := make(map[*buildJob]int, len(jobs))
+	for i, job := range jobs {
+		jobOrder[job] = i
+	}
+
+	pending := slices.Clone(jobs)
+	finished := make(map[string]struct{})
+	failed := make(map[string]struct{})
+	results := make(chan buildResult)
+	running := 0
+	var firstErr error
+
+	for {
+		for i := 0; (firstErr == nil || scheduler.keepGoing) && i < len(pending) && len(freeSlots) > 0; {
+			job := pending[i]
+			failedDep := job.failedDependency(failed)
+			if failedDep != "" {
+				pending = slices.Delete(pending, i, i+1)
+				failed[jobKey(job.config.Package)] = struct{}{}
+				scheduler.report.add(newReportEntry(job, jobOrder[job], BuildStatusSkipped,
+					fmt.Errorf("dependency %s failed", failedDep), 0))
+				continue
+			}
+			if !job.isReady(finished) {
+				i++
+				continue
+			}
+			pending = slices.Delete(pending, i, i+1)
+			slot := freeSlots[0]
+			freeSlots = freeSlots[1:]
+			running++
+			go func() {
+				start := time.Now()
+				status, err := runner(job, slot)
+				scheduler.report.add(newReportEntry(job, jobOrder[job], status, err, time.Since(start)))
+				results <- buildResult{job: job, slot: slot, status: status, err: err}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+		result := <-results
+		running--
+		freeSlots = append(freeSlots, result.slot)
+		slices.Sort(freeSlots)
+		key := jobKey(result.job.config.Package)
+		if result.err != nil {
+			logger := bringauto_log.GetLogger()
+			logger.Error("Build of package '%s' failed - %s", result.job.config.Package.Name, result.err)
+			failed[key] = struct{}{}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("cannot build package '%s' - %s", result.job.config.Package.Name, result.err)
+			}
+			continue
+		}
+		finished[key] = struct{}{}
+	}
+
+	if firstErr != nil {
+		if scheduler.keepGoing {
+			return fmt.Errorf("%d packages failed, %d packages skipped", scheduler.report.Failed, scheduler.report.Skipped)
+		}
+		return firstErr
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("cannot schedule %d packages - unresolved dependencies", len(pending))
+	}
+	return nil
+}
+
+// runJob
+// Builds the job in the given slot. Returns status of the build.
+func (scheduler *buildScheduler) runJob(job *buildJob, slot int) (string, error) {
+	err, found := scheduler.packages.cacheKeyErrors[jobKey(job.config.Package)]
+	if found {
+		return BuildStatusFailed, &bringauto_build.BuildError{Step: bringauto_build.BuildStepPrepare, Err: err}
+	}
+	for i := range job.builds {
+		if slot > 0 {
+			job.builds[i].SetLocalInstallDirName(localInstallDirPrefix + "_" + strconv.Itoa(slot))
+		}
+		job.builds[i].ForbidBranchRevision = scheduler.forbidBranchRevision
+		// Debug Packages keep their debug symbols
+		job.builds[i].SplitDebugSymbols = scheduler.splitDebugSymbols && !job.builds[i].Package.IsDebug
+		if scheduler.reproducible {
+			err := setReproducible(&job.builds[i])
+			if err != nil {
+				return BuildStatusFailed, &bringauto_build.BuildError{Step: bringauto_build.BuildStepPrepare, Err: err}
+			}
+		}
+		if scheduler.nativeFormat != "" {
+			job.builds[i].Package.Native = scheduler.createNativePackage(job.config)
+		}
+	}
+	cacheKey := scheduler.packages.cacheKeys[jobKey(job.config.Package)]
+	return scheduler.buildAndCopyPackage(job, cacheKey, slot)
+}
+
+// createNativePackage
+// Returns native package settings of the Package built from the config. Dependencies are the
+// runtime dependencies (DependsOn and RuntimeDependsOn) of the selected version with the same
+// build type.
+func (scheduler *buildScheduler) createNativePackage(config *bringauto_config.Config) *bringauto_package.NativePackage {
+	native := bringauto_package.NativePackage{
+		Format:       scheduler.nativeFormat,
+		Prefix:       scheduler.nativePrefix,
+		Dependencies: []bringauto_package.Package{},
+	}
+	for _, dependency := range config.GetRuntimeDependencies() {
+		depPack, found := scheduler.packages.getDependency(config, dependency)
+		if found {
+			native.Dependencies = append(native.Dependencies, depPack)
+		}
+	}
+	return &native
+}
+
+// hasSidecarPackages
+// Returns true if the native package, the debug symbols archive and the signatures of pack and
+// of its native package are stored in the Package Repository (or are not requested), else returns
+// false.
+func (scheduler *buildScheduler) hasSidecarPackages(pack *bringauto_package.Package) bool {
+	if scheduler.repo.SigningKey != nil && !scheduler.repo.IsPackageSigned(*pack) {
+		return false
+	}
+	if scheduler.nativeFormat != "" {
+		_, err := os.Stat(path.Join(scheduler.repo.CreatePackagePath(*pack), pack.GetNativeArchiveName(scheduler.nativeFormat)))
+		if err != nil {
+			return false
+		}
+		if scheduler.repo.SigningKey != nil && !scheduler.repo.IsNativePackageSigned(*pack, scheduler.nativeFormat) {
+			return false
+		}
+	}
+	if scheduler.splitDebugSymbols && !pack.IsDebug {
+		_, err := os.Stat(scheduler.repo.GetDbgsymArchivePath(*pack))
+		if err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// setReproducible
+// Sets the build to create reproducible Package archive. SOURCE_DATE_EPOCH is determined by
+// getSourceDateEpoch. The value is passed into the build container, so the build tools can use it
+// too.
+func setReproducible(build *bringauto_build.Build) error {
+	env := map[string]string{}
+	if build.Env != nil {
+		for key, value := range build.Env.Env {
+			env[key] = value
+		}
+	}
+	epoch, err := getSourceDateEpoch(env)
+	if err != nil {
+		return err
+	}
+	env[sourceDateEpochEnvConst] = strconv.FormatInt(epoch, 10)
+	build.Env = &bringauto_build.EnvironmentVariables{Env: env}
+	build.Package.Reproducible = true
+	build.Package.SourceDateEpoch = time.Unix(epoch, 0).UTC()
+	return nil
+}
+
+// getSourceDateEpoch
+// Returns SOURCE_DATE_EPOCH of the reproducible Package archive. It is taken from the environment
+// variables of the Package (env), from the environment of bap-builder or is 0 if not set.
+func getSourceDateEpoch(env map[string]string) (int64, error) {
+	epochString, found := env[sourceDateEpochEnvConst]
+	if !found {
+		epochString, found = os.LookupEnv(sourceDateEpochEnvConst)
+	}
+	if !found || epochString == "" {
+		return 0, nil
+	}
+	epoch, err := strconv.ParseInt(epochString, 10, 64)
+	if err != nil || epoch < 0 {
+		return 0, fmt.Errorf("invalid %s value '%s'", sourceDateEpochEnvConst, epochString)
+	}
+	return epoch, nil
+}
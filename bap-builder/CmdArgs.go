@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bringauto/modules/bringauto_package"
 	"fmt"
 	"github.com/akamensky/argparse"
+	"strconv"
 )
 
 // BuildImageCmdLineArgs
@@ -34,6 +36,40 @@ type BuildPackageCmdLineArgs struct {
 	DockerImageName *string
 	// OutputDir relative (to program working dir) ot absolute path where the Package will be stored
 	OutputDir *string
+	// Jobs maximum number of Packages built at the same time, each in its own Docker container.
+	// Packages are started only when all their dependencies are already built.
+	Jobs *int
+	// UseCache skip build of Packages which are in the Git Lfs and were built from the same
+	// definition, Docker image and dependencies
+	UseCache *bool
+	// Resume continue the previous failed or interrupted build, Packages already built by it
+	// are not built again
+	Resume *bool
+	// KeepGoing continue building Packages which do not depend on a failed Package
+	KeepGoing *bool
+	// ReportFile path of the JSON report with results of all Package builds, no report if empty
+	ReportFile *string
+	// ForbidBranchRevision if true, builds of Packages with a Git Revision which is a branch fail
+	ForbidBranchRevision *bool
+	// Reproducible if true, Package archives are bit-identical for identical inputs
+	Reproducible *bool
+	// NativePackage format of the native package created next to the Package archive (deb, rpm
+	// or auto), no native package if empty
+	NativePackage *string
+	// NativePrefix installation prefix of the native package files
+	NativePrefix *string
+	// SplitDebugSymbols if true, debug symbols of Release Packages are split to the debug symbols
+	// archive
+	SplitDebugSymbols *bool
+	// SigningKey path of the PEM encoded ed25519 key used to sign the Package archives, the key
+	// is taken from the BAP_SIGNING_KEY environment variable if empty
+	SigningKey *string
+	// JUnitFile path of the JUnit XML report with results of all Package builds, no report if empty
+	JUnitFile *string
+	// DryRun only print the build plan, no Package is built
+	DryRun *bool
+	// PlanFormat format of the build plan printed in the dry run (text or json)
+	PlanFormat *string
 }
 
 // CreateSysrootCmdLineArgs
@@ -45,14 +81,44 @@ type CreateSysrootCmdLineArgs struct {
 	Sysroot *string
 	// Name of the docker image which are the Packages build for
 	ImageName *string
+	// TrustedKeys path of the PEM file with trusted ed25519 public keys, signatures of Packages
+	// are not verified if empty
+	TrustedKeys *string
+	// Name of the Package whose runtime dependencies are in the sysroot, all Packages if empty
+	Name *string
+}
+
+// GraphCmdLineArgs
+// Options/setting for Graph mode
+type GraphCmdLineArgs struct {
+	// Format of the printed graph (dot, mermaid or json)
+	Format *string
+	// Name of the Package whose dependencies are printed. If empty, all Packages are printed
+	Name *string
+	// DepsOn print Packages which depends on the Package instead of its dependencies
+	DepsOn *bool
+	// ImageName if not empty, only Packages built for the image are printed
+	ImageName *string
+}
+
+// InspectPackageCmdLineArgs
+// Options/setting for Inspect mode
+type InspectPackageCmdLineArgs struct {
+	// Package path of the Package archive (zip, tar.gz or tar.zst)
+	Package *string
+	// Format of the printed manifest (text or json)
+	Format *string
 }
 
 // CmdLineArgs
 // Represents Cmd line arguments passed to  cmd line of the target program.
-// Program operates in three modes
+// Program operates in six modes
 // - build Docker images (Docker mode),
 // - build package (package mode)
 // - create sysroot (Sysroot mode)
+// - print dependency graph (Graph mode)
+// - validate context (Validate mode)
+// - inspect package (Inspect mode)
 // Exactly one of these modes can be active in a time.
 type CmdLineArgs struct {
 	// Absolute/relative path to config directory
@@ -65,11 +131,22 @@ type CmdLineArgs struct {
 	BuildPackage        bool
 	// If true the program is in the "Sysroot" mode
 	CreateSysroot       bool
+	// If true the program is in the "Graph" mode
+	Graph               bool
+	// If true the program is in the "Validate" mode
+	ValidateContext     bool
+	// If true the program is in the "Inspect" mode
+	InspectPackage      bool
 	BuildPackageArgs    BuildPackageCmdLineArgs
 	CreateSysrootArgs   CreateSysrootCmdLineArgs
+	GraphArgs           GraphCmdLineArgs
+	InspectPackageArgs  InspectPackageCmdLineArgs
 	buildImageParser    *argparse.Command
 	buildPackageParser  *argparse.Command
 	createSysrootParser *argparse.Command
+	graphParser         *argparse.Command
+	validateParser      *argparse.Command
+	inspectParser       *argparse.Command
 	parser              *argparse.Parser
 }
 
@@ -138,6 +215,118 @@ func (cmd *CmdLineArgs) InitFlags() {
 			"Given Packages will be build by toolchain represented by image-name",
 		},
 	)
+	cmd.BuildPackageArgs.Jobs = cmd.buildPackageParser.Int("", "jobs",
+		&argparse.Options{
+			Required: false,
+			Default:  1,
+			Validate: checkForPositive,
+			Help: "Maximum number of Packages built in parallel. Packages are built in parallel only " +
+			"if they do not depend on each other",
+		},
+	)
+	cmd.BuildPackageArgs.UseCache = cmd.buildPackageParser.Flag("", "use-cache",
+		&argparse.Options{
+			Required: false,
+			Default:  false,
+			Help: "Do not build Packages which are already in the output directory and were built " +
+			"from the same definition, Docker image and dependencies",
+		},
+	)
+	cmd.BuildPackageArgs.Resume = cmd.buildPackageParser.Flag("", "resume",
+		&argparse.Options{
+			Required: false,
+			Default:  false,
+			Help: "Continue the previous failed or interrupted build. Packages successfully built " +
+			"by the previous build are not built again",
+		},
+	)
+	cmd.BuildPackageArgs.KeepGoing = cmd.buildPackageParser.Flag("", "keep-going",
+		&argparse.Options{
+			Required: false,
+			Default:  false,
+			Help: "Do not stop when a Package build fails. Packages which depend on the failed " +
+			"Package are skipped, all other Packages are built",
+		},
+	)
+	cmd.BuildPackageArgs.ReportFile = cmd.buildPackageParser.String("", "report-file",
+		&argparse.Options{
+			Required: false,
+			Default:  "",
+			Help:     "Path of the JSON report with results of all Package builds",
+		},
+	)
+	cmd.BuildPackageArgs.JUnitFile = cmd.buildPackageParser.String("", "junit-file",
+		&argparse.Options{
+			Required: false,
+			Default:  "",
+			Help: "Path of the JUnit XML report, each Package build is one test case with the " +
+			"failed build step and the tail of the build log",
+		},
+	)
+	cmd.BuildPackageArgs.ForbidBranchRevision = cmd.buildPackageParser.Flag("", "forbid-branch-revision",
+		&argparse.Options{
+			Required: false,
+			Default:  false,
+			Help: "Fail the build of a Package whose Git Revision is a branch (or is not specified). " +
+			"Only tags and commit hashes are allowed",
+		},
+	)
+	cmd.BuildPackageArgs.Reproducible = cmd.buildPackageParser.Flag("", "reproducible",
+		&argparse.Options{
+			Required: false,
+			Default:  false,
+			Help: "Create reproducible Package archives. Entries are sorted, permissions and owners " +
+			"are normalized and timestamps are set to SOURCE_DATE_EPOCH (0 if not set)",
+		},
+	)
+	cmd.BuildPackageArgs.NativePackage = cmd.buildPackageParser.String("", "native-package",
+		&argparse.Options{
+			Required: false,
+			Default:  "",
+			Help: "Create also a native package (deb, rpm or auto - by the distribution of the image) " +
+			"next to the Package archive in the Package Repository",
+		},
+	)
+	cmd.BuildPackageArgs.NativePrefix = cmd.buildPackageParser.String("", "native-prefix",
+		&argparse.Options{
+			Required: false,
+			Default:  bringauto_package.DefaultNativePrefix,
+			Help:     "Installation prefix of the native package files on the target system",
+		},
+	)
+	cmd.BuildPackageArgs.SplitDebugSymbols = cmd.buildPackageParser.Flag("", "split-debug-symbols",
+		&argparse.Options{
+			Required: false,
+			Default:  false,
+			Help: "Strip installed ELF files of Release Packages and store their debug symbols in " +
+			"the -dbgsym archive next to the Package archive in the Package Repository",
+		},
+	)
+	cmd.BuildPackageArgs.SigningKey = cmd.buildPackageParser.String("", "signing-key",
+		&argparse.Options{
+			Required: false,
+			Default:  "",
+			Help: "Path of the PEM encoded ed25519 private key used to sign Package archives in the " +
+			"Package Repository. If not set, the key is taken from the " + signingKeyEnvConst +
+			" environment variable. Archives are not signed if no key is given",
+		},
+	)
+	cmd.BuildPackageArgs.DryRun = cmd.buildPackageParser.Flag("", "dry-run",
+		&argparse.Options{
+			Required: false,
+			Default:  false,
+			Help: "Do not build anything, only print the ordered list of Packages which would be built " +
+			"and Packages which would be skipped",
+		},
+	)
+	cmd.BuildPackageArgs.PlanFormat = cmd.buildPackageParser.Selector("", "plan-format",
+		[]string{PlanFormatText, PlanFormatJSON},
+		&argparse.Options{
+			Required: false,
+			Default:  PlanFormatText,
+			Help:     "Format of the build plan printed by --dry-run",
+		},
+	)
 
 	cmd.buildImageParser = cmd.parser.NewCommand("build-image", "Build Docker image")
 	cmd.BuildImagesArgs.All = cmd.buildImageParser.Flag("", "all",
@@ -174,6 +363,73 @@ func (cmd *CmdLineArgs) InitFlags() {
 			Help:     "Name of docker image which are the Packages built for",
 		},
 	)
+	cmd.CreateSysrootArgs.TrustedKeys = cmd.createSysrootParser.String("", "trusted-keys",
+		&argparse.Options{
+			Required: false,
+			Default:  "",
+			Help: "Path of the PEM file with trusted ed25519 public keys. If set, unsigned Packages " +
+			"and Packages with invalid signature are refused",
+		},
+	)
+	cmd.CreateSysrootArgs.Name = cmd.createSysrootParser.String("", "name",
+		&argparse.Options{
+			Required: false,
+			Default:  "",
+			Help:     "Create the sysroot only from the Package and its runtime dependencies recursively " +
+			"(DependsOn and RuntimeDependsOn), Packages needed only for the build are omitted",
+		},
+	)
+
+	cmd.graphParser = cmd.parser.NewCommand("graph", "Print dependency graph of Packages")
+	cmd.GraphArgs.Format = cmd.graphParser.Selector("", "format",
+		[]string{GraphFormatDOT, GraphFormatMermaid, GraphFormatJSON},
+		&argparse.Options{
+			Required: false,
+			Default:  GraphFormatDOT,
+			Help:     "Format of the printed graph",
+		},
+	)
+	cmd.GraphArgs.Name = cmd.graphParser.String("", "name",
+		&argparse.Options{
+			Required: false,
+			Default:  "",
+			Help:     "Print only the Package and its dependencies recursively",
+		},
+	)
+	cmd.GraphArgs.DepsOn = cmd.graphParser.Flag("", "deps-on",
+		&argparse.Options{
+			Required: false,
+			Default:  false,
+			Help:     "Print the Package given by --name and Packages which depends on it recursively " +
+			"instead of its dependencies",
+		},
+	)
+	cmd.GraphArgs.ImageName = cmd.graphParser.String("", "image-name",
+		&argparse.Options{
+			Required: false,
+			Default:  "",
+			Help:     "Print only Packages which are built for the given docker image",
+		},
+	)
+
+	cmd.validateParser = cmd.parser.NewCommand("validate-context", "Check Package definitions in the context")
+
+	cmd.inspectParser = cmd.parser.NewCommand("inspect-package", "Print manifest of a Package archive")
+	cmd.InspectPackageArgs.Package = cmd.inspectParser.String("", "package",
+		&argparse.Options{
+			Required: true,
+			Validate: checkForEmpty,
+			Help:     "Path of the Package archive (zip, tar.gz or tar.zst)",
+		},
+	)
+	cmd.InspectPackageArgs.Format = cmd.inspectParser.Selector("", "format",
+		[]string{InspectFormatText, InspectFormatJSON},
+		&argparse.Options{
+			Required: false,
+			Default:  InspectFormatText,
+			Help:     "Format of the printed manifest",
+		},
+	)
 }
 
 // checkForEmpty
@@ -187,6 +443,21 @@ func checkForEmpty(args []string) error {
 	return nil
 }
 
+// checkForPositive
+// Checks the given argument. If it is not a positive integer, returns error, else nil.
+func checkForPositive(args []string) error {
+	if len(args) == 1 {
+		value, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		if value < 1 {
+			return fmt.Errorf("must be greater than zero")
+		}
+	}
+	return nil
+}
+
 // ParseArgs
 // Parse arguments from given 'args' list of strings.
 // Return error if cmdline is not valid or nil in case of no problem.
@@ -200,6 +471,9 @@ func (cmd *CmdLineArgs) ParseArgs(args []string) error {
 	cmd.BuildImage = cmd.buildImageParser.Happened()
 	cmd.BuildPackage = cmd.buildPackageParser.Happened()
 	cmd.CreateSysroot = cmd.createSysrootParser.Happened()
+	cmd.Graph = cmd.graphParser.Happened()
+	cmd.ValidateContext = cmd.validateParser.Happened()
+	cmd.InspectPackage = cmd.inspectParser.Happened()
 
 	if *cmd.BuildPackageArgs.All {
 		if *cmd.BuildPackageArgs.BuildDeps {
@@ -217,5 +491,9 @@ func (cmd *CmdLineArgs) ParseArgs(args []string) error {
 		}
 	}
 
+	if *cmd.GraphArgs.DepsOn && *cmd.GraphArgs.Name == "" {
+		return fmt.Errorf("deps-on flag without name")
+	}
+
 	return nil
 }
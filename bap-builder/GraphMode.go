@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bringauto/modules/bringauto_context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+const (
+	// Dependency graph is printed in Graphviz DOT language
+	GraphFormatDOT = "dot"
+	// Dependency graph is printed as Mermaid flowchart
+	GraphFormatMermaid = "mermaid"
+	// Dependency graph is printed as JSON
+	GraphFormatJSON = "json"
+)
+
+// PrintDependencyGraph
+// Prints dependency graph of Packages in the Context in the format given by cmdLine. The graph can
+// be restricted to one image and to dependencies (or reverse dependencies) of one Package.
+func PrintDependencyGraph(cmdLine *GraphCmdLineArgs, contextPath string) error {
+	contextManager := bringauto_context.ContextManager{
+		ContextPath: contextPath,
+	}
+	graph, err := contextManager.GetDependencyGraph(*cmdLine.ImageName)
+	if err != nil {
+		return err
+	}
+	if *cmdLine.Name != "" {
+		if *cmdLine.DepsOn {
+			graph, err = graph.GetDepsOnSubgraph(*cmdLine.Name)
+		} else {
+			graph, err = graph.GetDepsSubgraph(*cmdLine.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return printGraph(os.Stdout, graph, *cmdLine.Format)
+}
+
+// printGraph
+// Prints graph to writer in the given format.
+func printGraph(writer io.Writer, graph *bringauto_context.DependencyGraph, format string) error {
+	switch format {
+	case GraphFormatDOT:
+		return printGraphDOT(writer, graph)
+	case GraphFormatMermaid:
+		return printGraphMermaid(writer, graph)
+	case GraphFormatJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(graph)
+	default:
+		return fmt.Errorf("unsupported graph format: %s", format)
+	}
+}
+
+// printGraphDOT
+// Prints graph to writer in Graphviz DOT language. Edges are directed from a Package to Packages
+// it depends on.
+func printGraphDOT(writer io.Writer, graph *bringauto_context.DependencyGraph) error {
+	lines := []string{"digraph packages {"}
+	for _, node := range graph.GetNodes() {
+		lines = append(lines, "    "+strconv.Quote(node)+";")
+		for _, dep := range graph.DependsOn[node] {
+			lines = append(lines, "    "+strconv.Quote(node)+" -> "+strconv.Quote(dep)+";")
+		}
+	}
+	lines = append(lines, "}")
+	return writeLines(writer, lines)
+}
+
+// printGraphMermaid
+// Prints graph to writer as Mermaid flowchart. Nodes have generated IDs, so Package names can
+// contain any characters.
+func printGraphMermaid(writer io.Writer, graph *bringauto_context.DependencyGraph) error {
+	nodes := graph.GetNodes()
+	nodeIds := make(map[string]string, len(nodes))
+	lines := []string{"graph TD"}
+	for i, node := range nodes {
+		nodeIds[node] = "p" + strconv.Itoa(i)
+		lines = append(lines, "    "+nodeIds[node]+"[\""+node+"\"]")
+	}
+	for _, node := range nodes {
+		for _, dep := range graph.DependsOn[node] {
+			lines = append(lines, "    "+nodeIds[node]+" --> "+nodeIds[dep])
+		}
+	}
+	return writeLines(writer, lines)
+}
+
+// writeLines
+// Writes lines to writer, each line is terminated by a newline.
+func writeLines(writer io.Writer, lines []string) error {
+	for _, line := range lines {
+		_, err := fmt.Fprintln(writer, line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bringauto/modules/bringauto_package"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// Package manifest is printed as human readable text
+	InspectFormatText = "text"
+	// Package manifest is printed as JSON
+	InspectFormatJSON = "json"
+)
+
+// InspectPackage
+// Prints manifest of the Package archive given by cmdLine.
+func InspectPackage(cmdLine *InspectPackageCmdLineArgs) error {
+	manifest, err := bringauto_package.ReadManifest(*cmdLine.Package)
+	if err != nil {
+		return err
+	}
+	switch *cmdLine.Format {
+	case InspectFormatJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(manifest)
+	case InspectFormatText, "":
+		return printManifestText(os.Stdout, manifest)
+	default:
+		return fmt.Errorf("unsupported inspect format: %s", *cmdLine.Format)
+	}
+}
+
+// printManifestText
+// Prints the manifest to writer as human readable text.
+func printManifestText(writer io.Writer, manifest *bringauto_package.Manifest) error {
+	tabWriter := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tabWriter, "Name:\t%s\n", manifest.Name)
+	fmt.Fprintf(tabWriter, "VersionTag:\t%s\n", manifest.VersionTag)
+	fmt.Fprintf(tabWriter, "PlatformString:\t%s\n", manifest.PlatformString)
+	fmt.Fprintf(tabWriter, "IsLibrary:\t%t\n", manifest.IsLibrary)
+	fmt.Fprintf(tabWriter, "IsDevLib:\t%t\n", manifest.IsDevLib)
+	fmt.Fprintf(tabWriter, "IsDebug:\t%t\n", manifest.IsDebug)
+	switch {
+	case manifest.GitURI != "":
+		fmt.Fprintf(tabWriter, "Git:\t%s %s\n", manifest.GitURI, manifest.GitCommit)
+	case manifest.TarballURL != "":
+		fmt.Fprintf(tabWriter, "Tarball:\t%s (sha256 %s)\n", manifest.TarballURL, manifest.TarballSHA256)
+	case manifest.LocalDirectory != "":
+		fmt.Fprintf(tabWriter, "LocalDirectory:\t%s\n", manifest.LocalDirectory)
+	}
+	fmt.Fprintf(tabWriter, "DockerImage:\t%s (%s)\n", manifest.DockerImage, manifest.DockerImageId)
+	fmt.Fprintf(tabWriter, "BuildTimestamp:\t%s\n", manifest.BuildTimestamp)
+
+	var defines []string
+	for key, value := range manifest.CMakeDefines {
+		defines = append(defines, key+"="+value)
+	}
+	sort.Strings(defines)
+	fmt.Fprintf(tabWriter, "CMakeDefines:\t%s\n", strings.Join(defines, " "))
+
+	var dependencies []string
+	for _, dependency := range manifest.Dependencies {
+		dependencies = append(dependencies, dependency.Name+" "+dependency.VersionTag)
+	}
+	fmt.Fprintf(tabWriter, "Dependencies:\t%s\n", strings.Join(dependencies, ", "))
+	return tabWriter.Flush()
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// Number of the last lines of the build chain log included in the failure of the test case
+	junitLogTailLinesConst = 50
+)
+
+// junitTestSuites
+// Root element of the JUnit XML report.
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       float64          `xml:"time,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite
+// Builds of all Packages for one docker image.
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      float64         `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase
+// Build of one Package for one docker image.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitFailure
+// Failure of the Package build. Contents holds the tail of the build chain log.
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// junitSkipped
+// Package build which was not run.
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// saveJUnit
+// Stores the report as JUnit XML to reportPath. Each Package build is one test case of the test
+// suite named after the docker image. Failed test cases contain the failed build step and the
+// tail of the build chain log.
+func (report *BuildReport) saveJUnit(reportPath string) error {
+	report.lock.Lock()
+	defer report.lock.Unlock()
+
+	suite := junitTestSuite{
+		Name:      report.ImageName,
+		TestCases: []junitTestCase{},
+	}
+	for _, entry := range report.Packages {
+		testCase := junitTestCase{
+			Name:      entry.FullPackageName,
+			ClassName: report.ImageName,
+			Time:      entry.Duration,
+		}
+		switch entry.Status {
+		case BuildStatusFailed:
+			step := entry.FailedStep
+			if step == "" {
+				step = "unknown"
+			}
+			testCase.Failure = &junitFailure{
+				Message:  fmt.Sprintf("%s step failed: %s", step, entry.Error),
+				Type:     step,
+				Contents: readLogTail(entry.LogFilePath, junitLogTailLinesConst),
+			}
+			suite.Failures++
+		case BuildStatusSkipped:
+			testCase.Skipped = &junitSkipped{Message: entry.Error}
+			suite.Skipped++
+		case BuildStatusCached:
+			testCase.SystemOut = "Package taken from the Package Repository, build skipped"
+		}
+		suite.Tests++
+		suite.Time += entry.Duration
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suites := junitTestSuites{
+		Tests:      suite.Tests,
+		Failures:   suite.Failures,
+		Skipped:    suite.Skipped,
+		Time:       suite.Time,
+		TestSuites: []junitTestSuite{suite},
+	}
+
+	mbytes, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+	mbytes = append([]byte(xml.Header), mbytes...)
+	mbytes = append(mbytes, '\n')
+	return os.WriteFile(reportPath, mbytes, 0644)
+}
+
+// readLogTail
+// Returns the last linesCount lines of the log file. Returns empty string if the log cannot be read.
+func readLogTail(logPath string, linesCount int) string {
+	if logPath == "" {
+		return ""
+	}
+	mbytes, err := os.ReadFile(logPath)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(string(mbytes), "\n"), "\n")
+	if len(lines) > linesCount {
+		lines = lines[len(lines)-linesCount:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -15,8 +15,9 @@ import (
 	"bringauto/modules/bringauto_sysroot"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
-	"strconv"
+	"time"
 )
 
 type (
@@ -33,7 +34,7 @@ func removeDuplicates(configList *[]*bringauto_config.Config) []*bringauto_confi
 	var newConfigList []*bringauto_config.Config
 	packageMap := make(map[string]bool)
 	for _, cconfig := range *configList {
-		packageName := cconfig.Package.Name + ":" + strconv.FormatBool(cconfig.Package.IsDebug)
+		packageName := jobKey(cconfig.Package)
 		exist, _ := packageMap[packageName]
 		if exist {
 			continue
@@ -86,14 +87,26 @@ func detectCycle(packageName string, dependsMap map[string]*map[string]bool, vis
 	return false, ""
 }
 
+// TopologicalSort
+// Returns Configs from buildMap sorted so that dependencies are before Packages which depend on
+// them. Returns error if there is a circular dependency or if versions of Packages in buildMap
+// do not satisfy version constraints from DependsOn.
 func (list *buildDepList) TopologicalSort(buildMap ConfigMapType) ([]*bringauto_config.Config, error) {
+	var allConfigs []*bringauto_config.Config
+	for _, configArray := range buildMap {
+		allConfigs = append(allConfigs, configArray...)
+	}
+	err := bringauto_context.CheckVersionConflicts(allConfigs)
+	if err != nil {
+		return []*bringauto_config.Config{}, err
+	}
 
 	// Map represents 'PackageName: []DependsOnPackageNames'
 	var dependsMap map[string]*map[string]bool
 	var allDependencies map[string]bool
 
 	dependsMap, allDependencies = list.createDependsMap(&buildMap)
-	err := checkForCircularDependency(dependsMap)
+	err = checkForCircularDependency(dependsMap)
 	if err != nil  {
 		return []*bringauto_config.Config{}, err
 	}
@@ -144,10 +157,7 @@ func (list *buildDepList) createDependsMap(buildMap *ConfigMapType) (dependsMapT
 			dependsMap[packageName] = item
 		}
 		for _, config := range configArray {
-			if len(config.DependsOn) == 0 {
-				continue
-			}
-			for _, v := range config.DependsOn {
+			for _, v := range config.GetDependsOnNames() {
 				(*item)[v] = true
 				allDependencies[v] = true
 			}
@@ -187,7 +197,7 @@ func checkContextDirConsistency(contextPath string) error {
 			var config bringauto_config.Config
 			err = config.LoadJSONConfig(path)
 			if err != nil {
-				return fmt.Errorf("couldn't load JSON config from %s path", path)
+				return fmt.Errorf("couldn't load JSON config from %s path - %s", path, err)
 			}
 			dirName := filepath.Base(filepath.Dir(path))
 			if config.Package.Name != dirName {
@@ -226,15 +236,45 @@ func performPreBuildChecks(contextPath string, repo *bringauto_repository.GitLFS
 	return nil
 }
 
+// getPackageFormat
+// Returns format of the Package archives selected in the settings of the Context.
+func getPackageFormat(contextPath string) (bringauto_package.ArchiveFormat, error) {
+	contextManager := bringauto_context.ContextManager{
+		ContextPath: contextPath,
+	}
+	settings, err := contextManager.GetSettings()
+	if err != nil {
+		return "", err
+	}
+	return settings.PackageFormat, nil
+}
+
 // BuildPackage
 // process Package mode of the program
 func BuildPackage(cmdLine *BuildPackageCmdLineArgs, contextPath string) error {
+	if *cmdLine.DryRun {
+		return PlanBuild(cmdLine, contextPath)
+	}
 	platformString, err := determinePlatformString(*cmdLine.DockerImageName)
 	if err != nil {
 		return err
 	}
+	err = savePlatformString(*cmdLine.DockerImageName, platformString)
+	if err != nil {
+		return err
+	}
+	packageFormat, err := getPackageFormat(contextPath)
+	if err != nil {
+		return err
+	}
+	signingKey, err := loadSigningKey(*cmdLine.SigningKey)
+	if err != nil {
+		return err
+	}
 	repo := bringauto_repository.GitLFSRepository{
 		GitRepoPath: *cmdLine.OutputDir,
+		Format:      packageFormat,
+		SigningKey:  signingKey,
 	}
 	err = bringauto_prerequisites.Initialize(&repo)
 	if err != nil {
@@ -245,35 +285,100 @@ func BuildPackage(cmdLine *BuildPackageCmdLineArgs, contextPath string) error {
 		return err
 	}
 
-	handleRemover := bringauto_process.SignalHandlerAddHandler(repo.RestoreAllChanges)
-	defer handleRemover()
-	if *cmdLine.All {
-		err = buildAllPackages(cmdLine, contextPath, platformString, repo)
+	configList, err := prepareBuildConfigs(cmdLine, contextPath, platformString)
+	if err != nil {
+		return err
+	}
+
+	var journal *buildJournal
+	if *cmdLine.Resume {
+		journal, err = loadBuildJournal(*cmdLine.DockerImageName)
+		if err != nil {
+			return err
+		}
 	} else {
-		err = buildSinglePackage(cmdLine, contextPath, platformString, repo)
+		journal = newBuildJournal(*cmdLine.DockerImageName)
 	}
+
+	handleRemover := bringauto_process.SignalHandlerAddHandler(repo.RestoreAllChanges)
+	defer handleRemover()
+	err = buildPackages(cmdLine, contextPath, platformString, repo, configList, journal)
 	if err != nil {
+		if journal.hasCompleted() {
+			logger := bringauto_log.GetLogger()
+			logger.Info("Successfully built Packages are committed, use --resume to continue the build")
+		}
 		return err
 	}
 	err = repo.CommitAllChanges()
-	return err
+	if err != nil {
+		return err
+	}
+	return journal.remove()
 }
 
-// buildAllPackages
-// Builds all packages specified in contextPath. Also takes care of building all deps for all
-// packages in correct order. It returns nil if everything is ok, or not nil in case of error.
-func buildAllPackages(
+// prepareBuildConfigs
+// Returns Configs of all Packages selected by cmdLine in the order in which they are built.
+func prepareBuildConfigs(
+	cmdLine        *BuildPackageCmdLineArgs,
+	contextPath    string,
+	platformString *bringauto_package.PlatformString,
+) ([]*bringauto_config.Config, error) {
+	if *cmdLine.All {
+		return prepareAllConfigs(contextPath)
+	}
+	return prepareSinglePackageConfigs(cmdLine, contextPath, platformString)
+}
+
+// buildPackages
+// Builds Packages of all configs in configList which are built for the image given in cmdLine.
+// The configList must be topologically sorted. Built Packages are recorded in the journal. It
+// returns nil if everything is ok, or not nil in case of error.
+func buildPackages(
 	cmdLine        *BuildPackageCmdLineArgs,
 	contextPath    string,
 	platformString *bringauto_package.PlatformString,
 	repo           bringauto_repository.GitLFSRepository,
+	configList     []*bringauto_config.Config,
+	journal        *buildJournal,
 ) error {
+	jobs := createBuildJobs(configList, *cmdLine.DockerImageName, platformString)
+	if len(jobs) == 0 {
+		logger := bringauto_log.GetLogger()
+		logger.Warn("Nothing to build. Did you enter correct image name?")
+		return nil
+	}
+	scheduler, err := newBuildScheduler(cmdLine, contextPath, platformString, repo, journal)
+	if err != nil {
+		return err
+	}
+	err = scheduler.run(jobs)
+	scheduler.report.printSummary()
+	if *cmdLine.ReportFile != "" {
+		reportErr := scheduler.report.saveJSON(*cmdLine.ReportFile)
+		if reportErr != nil {
+			return fmt.Errorf("cannot save build report - %s", reportErr)
+		}
+	}
+	if *cmdLine.JUnitFile != "" {
+		reportErr := scheduler.report.saveJUnit(*cmdLine.JUnitFile)
+		if reportErr != nil {
+			return fmt.Errorf("cannot save JUnit report - %s", reportErr)
+		}
+	}
+	return err
+}
+
+// prepareAllConfigs
+// Returns Configs of all packages specified in contextPath in correct build order (dependencies
+// first).
+func prepareAllConfigs(contextPath string) ([]*bringauto_config.Config, error) {
 	contextManager := bringauto_context.ContextManager{
 		ContextPath: contextPath,
 	}
 	packageJsonPathMap, err := contextManager.GetAllPackagesJsonDefPaths()
 	if err != nil {
-		return err
+		return []*bringauto_config.Config{}, err
 	}
 
 	defsMap := make(ConfigMapType)
@@ -281,30 +386,7 @@ func buildAllPackages(
 		addConfigsToDefsMap(&defsMap, packageJsonPathList)
 	}
 	depsList := buildDepList{}
-	configList, err := depsList.TopologicalSort(defsMap)
-	if err != nil {
-		return err
-	}
-
-	logger := bringauto_log.GetLogger()
-
-	count := int32(0)
-	for _, config := range configList {
-		buildConfigs := config.GetBuildStructure(*cmdLine.DockerImageName, platformString)
-		if len(buildConfigs) == 0 {
-			continue
-		}
-		count++
-		err = buildAndCopyPackage(&buildConfigs, platformString, repo)
-		if err != nil {
-			return fmt.Errorf("cannot build package '%s' - %s", config.Package.Name, err)
-		}
-	}
-	if count == 0 {
-		logger.Warn("Nothing to build. Did you enter correct image name?")
-	}
-
-	return nil
+	return depsList.TopologicalSort(defsMap)
 }
 
 // prepareConfigs
@@ -330,14 +412,13 @@ func prepareConfigsNoBuildDeps(packageName string, contextManager *bringauto_con
 		return []*bringauto_config.Config{}, err
 	}
 	for _, packageJsonPath := range packageJsonPaths {
-		var config bringauto_config.Config
-		err = config.LoadJSONConfig(packageJsonPath)
+		configs, err := bringauto_config.LoadJSONConfigs(packageJsonPath)
 		if err != nil {
 			logger := bringauto_log.GetLogger()
 			logger.Warn("Couldn't load JSON config from %s path - %s", packageJsonPath, err)
 			continue
 		}
-		configList = append(configList, &config)
+		configList = append(configList, configs...)
 	}
 	return configList, nil
 }
@@ -381,15 +462,15 @@ func prepareConfigsBuildDepsOrBuildDepsOn(
 	return prepareConfigs(packageJsonPaths)
 }
 
-// buildSinglePackage
-// Builds single package specified by name in cmdLine. Also takes care of building all deps for
-// given package in correct order. It returns nil if everything is ok, or not nil in case of error.
-func buildSinglePackage(
+// prepareSinglePackageConfigs
+// Returns Configs of single package specified by name in cmdLine. Based on --build-deps and
+// --build-deps-on flags also Configs of its dependencies or of packages which depends on it are
+// returned, all in correct build order.
+func prepareSinglePackageConfigs(
 	cmdLine        *BuildPackageCmdLineArgs,
 	contextPath    string,
 	platformString *bringauto_package.PlatformString,
-	repo           bringauto_repository.GitLFSRepository,
-) error {
+) ([]*bringauto_config.Config, error) {
 	contextManager := bringauto_context.ContextManager{
 		ContextPath: contextPath,
 	}
@@ -403,19 +484,12 @@ func buildSinglePackage(
 		configList, err = prepareConfigsNoBuildDeps(packageName, &contextManager)
 	}
 	if err != nil {
-		return err
+		return []*bringauto_config.Config{}, err
 	}
 	if len(configList) == 0 {
-		return fmt.Errorf("nothing to build")
-	}
-	for _, config := range configList {
-		buildConfigs := config.GetBuildStructure(*cmdLine.DockerImageName, platformString)
-		err = buildAndCopyPackage(&buildConfigs, platformString, repo)
-		if err != nil {
-			return fmt.Errorf("cannot build package '%s' - %s", packageName, err)
-		}
+		return []*bringauto_config.Config{}, fmt.Errorf("nothing to build")
 	}
-	return nil
+	return configList, nil
 }
 
 // addConfigsToDefsMap
@@ -423,59 +497,91 @@ func buildSinglePackage(
 func addConfigsToDefsMap(defsMap *ConfigMapType, packageJsonPathList []string) {
 	logger := bringauto_log.GetLogger()
 	for _, packageJsonPath := range packageJsonPathList {
-		var config bringauto_config.Config
-		err := config.LoadJSONConfig(packageJsonPath)
+		configs, err := bringauto_config.LoadJSONConfigs(packageJsonPath)
 		if err != nil {
 			logger.Error("Couldn't load JSON config from %s path - %s", packageJsonPath, err)
 			continue
 		}
-		packageName := config.Package.Name
-		_, found := (*defsMap)[packageName]
-		if !found {
-			(*defsMap)[packageName] = []*bringauto_config.Config{}
+		for _, config := range configs {
+			packageName := config.Package.Name
+			_, found := (*defsMap)[packageName]
+			if !found {
+				(*defsMap)[packageName] = []*bringauto_config.Config{}
+			}
+			(*defsMap)[packageName] = append((*defsMap)[packageName], config)
 		}
-		(*defsMap)[packageName] = append((*defsMap)[packageName], &config)
 	}
 }
 
 // buildAndCopyPackage
-// Builds single package, takes care of every step of build for single package.
-func buildAndCopyPackage(
-	build *[]bringauto_build.Build,
-	platformString *bringauto_package.PlatformString,
-	repo bringauto_repository.GitLFSRepository,
-) error {
+// Builds single package, takes care of every step of build for single package. Returns status
+// of the build (BuildStatusBuilt, BuildStatusCached or BuildStatusFailed). The copyLock of
+// the scheduler is held while the package is copied to the Git repository and to the sysroot, so
+// builds running in parallel do not interfere. Each built package is committed to the Git
+// repository and recorded in the build journal.
+//
+// The build is skipped if the Git repository already contains the package (and its native package,
+// debug symbols archive and signature if requested) built with the same cacheKey and either the
+// cache is used or the package is recorded in the journal of the resumed build. The package from
+// the Git repository is copied to the sysroot then (if it is not there yet).
+//
+// The package is built in the sysroot of the slot which holds only the dependency versions
+// selected for the package (see fillSysroot).
+func (scheduler *buildScheduler) buildAndCopyPackage(job *buildJob, cacheKey string, slot int) (string, error) {
 	var err error
 	var removeHandler func()
+	status := BuildStatusCached
 
 	logger := bringauto_log.GetLogger()
 
-	for _, buildConfig := range *build {
+	for _, buildConfig := range job.builds {
 		logger.Info("Build %s", buildConfig.Package.GetFullPackageName())
 
 		sysroot := bringauto_sysroot.Sysroot{
 			IsDebug:        buildConfig.Package.IsDebug,
-			PlatformString: platformString,
+			PlatformString: scheduler.platformString,
+			Slot:           slot,
 		}
+		scheduler.copyLock.Lock()
 		err = bringauto_prerequisites.Initialize(&sysroot)
+		scheduler.copyLock.Unlock()
+		if err != nil {
+			break
+		}
 		buildConfig.SetSysroot(&sysroot)
 
-		logger.InfoIndent("Run build inside container")
+		key := jobKey(*buildConfig.Package)
+		isCached := scheduler.repo.IsPackageCached(*buildConfig.Package, cacheKey) &&
+			scheduler.hasSidecarPackages(buildConfig.Package)
 		removeHandler = bringauto_process.SignalHandlerAddHandler(buildConfig.CleanUp)
-		err = buildConfig.RunBuild()
-		if err != nil {
-			return err
+		if isCached && scheduler.resume && scheduler.journal.isCompleted(key, cacheKey) &&
+			sysroot.IsPackageInSysroot(*buildConfig.Package) {
+			logger.InfoIndent("Package already built by the resumed build, skipping build")
+		} else if isCached && (scheduler.useCache || scheduler.resume && scheduler.journal.isCompleted(key, cacheKey)) {
+			logger.InfoIndent("Build result found in cache, skipping build")
+			err = scheduler.copyCachedToSysroot(&buildConfig, &sysroot)
+		} else {
+			logger.InfoIndent("Run build inside container")
+			status = BuildStatusBuilt
+			err = scheduler.fillSysroot(job.config, &sysroot)
+			if err != nil {
+				err = &bringauto_build.BuildError{Step: bringauto_build.BuildStepPrepare, Err: err}
+				break
+			}
+			err = buildConfig.RunBuild()
+			if err != nil {
+				break
+			}
+			buildConfig.Package.Manifest = scheduler.createManifest(job.config, &buildConfig)
+			err = scheduler.copyToRepositoryAndSysroot(&buildConfig, &sysroot, cacheKey)
+			if err != nil {
+				err = &bringauto_build.BuildError{Step: BuildStepCopy, Err: err}
+			}
 		}
-
-		logger.InfoIndent("Copying to Git repository")
-
-		err = repo.CopyToRepository(*buildConfig.Package, buildConfig.GetLocalInstallDirPath())
 		if err != nil {
 			break
 		}
-
-		logger.InfoIndent("Copying to local sysroot directory")
-		err = sysroot.CopyToSysroot(buildConfig.GetLocalInstallDirPath(), buildConfig.Package.GetShortPackageName())
+		err = scheduler.journal.markCompleted(key, cacheKey)
 		if err != nil {
 			break
 		}
@@ -487,9 +593,196 @@ func buildAndCopyPackage(
 	if removeHandler != nil {
 		removeHandler()
 	}
+	if err != nil {
+		return BuildStatusFailed, err
+	}
+	return status, nil
+}
+
+// copyToRepositoryAndSysroot
+// Copies installed files of the build to the Git repository and to the local sysroot directory.
+// The cacheKey is stored next to the package in the Git repository. The package is committed to
+// the Git repository. Returns error without copying if the package would overwrite files of its
+// dependencies in the sysroot.
+func (scheduler *buildScheduler) copyToRepositoryAndSysroot(
+	buildConfig *bringauto_build.Build,
+	sysroot     *bringauto_sysroot.Sysroot,
+	cacheKey    string,
+) error {
+	scheduler.copyLock.Lock()
+	defer scheduler.copyLock.Unlock()
+	logger := bringauto_log.GetLogger()
+
+	var err error
+	defer func() {
+		// Remove partially copied package, so it is not committed with other packages
+		if err != nil {
+			restoreErr := scheduler.repo.RestoreAllChanges()
+			if restoreErr != nil {
+				logger.Error("Cannot restore Git repository - %s", restoreErr)
+			}
+		}
+	}()
+
+	err = sysroot.CheckPackageFiles(buildConfig.GetLocalInstallDirPath())
+	if err != nil {
+		return err
+	}
+	warnChangedGitCommit(scheduler.repo.FindPackageArchivePath(*buildConfig.Package), buildConfig.Package.Metadata)
+	logger.InfoIndent("Copying %s to Git repository", buildConfig.Package.GetShortPackageName())
+	if buildConfig.SplitDebugSymbols {
+		buildConfig.Package.DebugSymbolsDir = buildConfig.GetLocalDebugSymbolsDirPath()
+	}
+	err = scheduler.repo.CopyToRepository(*buildConfig.Package, buildConfig.GetLocalInstallDirPath())
+	if err != nil {
+		return err
+	}
+	if cacheKey != "" {
+		err = scheduler.repo.SaveCacheKey(*buildConfig.Package, cacheKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.InfoIndent("Copying %s to local sysroot directory", buildConfig.Package.GetShortPackageName())
+	err = sysroot.CopyToSysroot(buildConfig.GetLocalInstallDirPath(), buildConfig.Package.GetFullPackageName())
+	if err != nil {
+		return err
+	}
+
+	err = scheduler.repo.CommitPackage(*buildConfig.Package)
 	return err
 }
 
+// createManifest
+// Returns manifest of the Package built by buildConfig from the config.
+func (scheduler *buildScheduler) createManifest(config *bringauto_config.Config, buildConfig *bringauto_build.Build) *bringauto_package.Manifest {
+	pack := buildConfig.Package
+	manifest := bringauto_package.Manifest{
+		Name:           pack.Name,
+		VersionTag:     pack.VersionTag,
+		PlatformString: pack.PlatformString.Serialize(),
+		IsLibrary:      pack.IsLibrary,
+		IsDevLib:       pack.IsDevLib,
+		IsDebug:        pack.IsDebug,
+		DockerImage:    scheduler.imageName,
+		DockerImageId:  scheduler.packages.imageId,
+		Dependencies:   []bringauto_package.ManifestDependency{},
+		BuildTimestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	if pack.Reproducible {
+		manifest.BuildTimestamp = pack.SourceDateEpoch.Format(time.RFC3339)
+	}
+	switch {
+	case buildConfig.Tarball != nil:
+		manifest.TarballURL = buildConfig.Tarball.URL
+		manifest.TarballSHA256 = buildConfig.Tarball.SHA256
+	case buildConfig.LocalDirectory != nil:
+		manifest.LocalDirectory = buildConfig.LocalDirectory.Path
+	case pack.Metadata != nil:
+		manifest.GitURI = pack.Metadata.GitURI
+		manifest.GitCommit = pack.Metadata.GitCommit
+	}
+	isCMakeBuild := buildConfig.Meson == nil && buildConfig.Autotools == nil && buildConfig.CustomBuild == nil
+	if isCMakeBuild && buildConfig.CMake != nil {
+		manifest.CMakeDefines = buildConfig.CMake.Defines
+	}
+	for _, dependency := range config.GetDependencies() {
+		depPack, _ := scheduler.packages.getDependency(config, dependency)
+		manifest.Dependencies = append(manifest.Dependencies, bringauto_package.ManifestDependency{
+			Name:       dependency.Name,
+			VersionTag: depPack.VersionTag,
+		})
+	}
+	return &manifest
+}
+
+// warnChangedGitCommit
+// Prints a warning if the package archive already stored at archivePath was built from a
+// different Git commit than the one described by metadata.
+func warnChangedGitCommit(archivePath string, metadata *bringauto_package.Metadata) {
+	if metadata == nil || metadata.GitCommit == "" || archivePath == "" {
+		return
+	}
+	previous, err := bringauto_package.ReadMetadata(archivePath)
+	if err != nil || previous == nil || previous.GitCommit == "" {
+		return
+	}
+	if previous.GitCommit != metadata.GitCommit {
+		logger := bringauto_log.GetLogger()
+		logger.Warn("Package %s was built from commit %s, the stored package from commit %s",
+			filepath.Base(archivePath), metadata.GitCommit, previous.GitCommit)
+	}
+}
+
+// copyCachedToSysroot
+// Extracts the package stored in the Git repository to the local install directory of the build
+// and copies it to the local sysroot directory.
+func (scheduler *buildScheduler) copyCachedToSysroot(
+	buildConfig *bringauto_build.Build,
+	sysroot     *bringauto_sysroot.Sysroot,
+) error {
+	scheduler.copyLock.Lock()
+	defer scheduler.copyLock.Unlock()
+	logger := bringauto_log.GetLogger()
+
+	err := scheduler.repo.CopyFromRepository(*buildConfig.Package, buildConfig.GetLocalInstallDirPath())
+	if err != nil {
+		return err
+	}
+
+	logger.InfoIndent("Copying %s to local sysroot directory", buildConfig.Package.GetShortPackageName())
+	return sysroot.CopyToSysroot(buildConfig.GetLocalInstallDirPath(), buildConfig.Package.GetFullPackageName())
+}
+
+// fillSysroot
+// Fills the sysroot with the Packages returned by getSysrootPackages for the config. Packages
+// which are not stored in the sysroot (built by older versions of the packager or on another
+// machine) are taken from the Git repository. Packages which are in neither are skipped with
+// a warning.
+func (scheduler *buildScheduler) fillSysroot(
+	config  *bringauto_config.Config,
+	sysroot *bringauto_sysroot.Sysroot,
+) error {
+	scheduler.copyLock.Lock()
+	defer scheduler.copyLock.Unlock()
+	logger := bringauto_log.GetLogger()
+
+	var packageNames []string
+	for _, pack := range scheduler.packages.getSysrootPackages(config) {
+		pack.PlatformString = *scheduler.platformString
+		packageName := pack.GetFullPackageName()
+		if !sysroot.IsPackageStored(packageName) {
+			archivePath := scheduler.repo.FindPackageArchivePath(pack)
+			if archivePath == "" {
+				logger.WarnIndent("Dependency %s is not built, it is not in the sysroot", packageName)
+				continue
+			}
+			err := storeArchiveToSysroot(archivePath, packageName, sysroot)
+			if err != nil {
+				return err
+			}
+		}
+		packageNames = append(packageNames, packageName)
+	}
+	return sysroot.FillSysroot(packageNames)
+}
+
+// storeArchiveToSysroot
+// Extracts the package archive and stores it in the sysroot as packageName.
+func storeArchiveToSysroot(archivePath string, packageName string, sysroot *bringauto_sysroot.Sysroot) error {
+	extractDir, err := os.MkdirTemp("", "bap-sysroot-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(extractDir)
+	err = bringauto_package.ExtractArchive(archivePath, extractDir)
+	if err != nil {
+		return fmt.Errorf("cannot extract %s - %s", archivePath, err)
+	}
+	return sysroot.CopyToSysroot(extractDir, packageName)
+}
+
 // determinePlatformString
 // Will construct platform string suitable for sysroot.
 func determinePlatformString(dockerImageName string) (*bringauto_package.PlatformString, error) {
@@ -507,7 +800,7 @@ func determinePlatformString(dockerImageName string) (*bringauto_package.Platfor
 }
 
 // checkSysrootDirs
-// Checks if sysroot release and debug directories are empty. If not, prints a warning.
+// Checks if sysroot contains Packages built before. If yes, prints a warning.
 func checkSysrootDirs(platformString *bringauto_package.PlatformString) (error) {
 	sysroot := bringauto_sysroot.Sysroot{
 		IsDebug:        false,
@@ -518,13 +811,9 @@ func checkSysrootDirs(platformString *bringauto_package.PlatformString) (error)
 		return err
 	}
 
-	logger := bringauto_log.GetLogger()
-	if !sysroot.IsSysrootDirectoryEmpty() {
-		logger.Warn("Sysroot release directory is not empty - the package build may fail")
-	}
-	sysroot.IsDebug = true
-	if !sysroot.IsSysrootDirectoryEmpty() {
-		logger.Warn("Sysroot debug directory is not empty - the package build may fail")
+	if !sysroot.IsEmpty() {
+		logger := bringauto_log.GetLogger()
+		logger.Warn("Sysroot directory is not empty - packages built before are used as dependencies")
 	}
 	return nil
 }
@@ -551,8 +840,9 @@ func isPackageWithDepsInSysroot(packageName string, contextManager *bringauto_co
 	}
 
 	for _, config := range configList {
-		packName := config.Package.GetShortPackageName()
-		if !sysroot.IsPackageInSysroot(packName) {
+		pack := config.Package
+		pack.PlatformString = *platformString
+		if !sysroot.IsPackageInSysroot(pack) {
 			return false, nil
 		}
 	}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bringauto/modules/bringauto_package"
+	"bringauto/modules/bringauto_repository"
+	"crypto/ed25519"
+	"fmt"
+	"os"
+)
+
+const (
+	// Environment variable with the PEM encoded signing key, used if no key file is given
+	signingKeyEnvConst = "BAP_SIGNING_KEY"
+)
+
+// loadSigningKey
+// Loads ed25519 private key used to sign the Package archives from the keyPath. If keyPath is
+// empty, the key is taken from the BAP_SIGNING_KEY environment variable. Returns nil if no key
+// is given.
+func loadSigningKey(keyPath string) (ed25519.PrivateKey, error) {
+	var pemData []byte
+	if keyPath != "" {
+		var err error
+		pemData, err = os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read signing key - %s", err)
+		}
+	} else {
+		keyString, found := os.LookupEnv(signingKeyEnvConst)
+		if !found || keyString == "" {
+			return nil, nil
+		}
+		pemData = []byte(keyString)
+	}
+	privateKey, err := bringauto_package.ParsePrivateKey(pemData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key - %s", err)
+	}
+	return privateKey, nil
+}
+
+// loadTrustedKeys
+// Loads list of trusted ed25519 public keys from the keysPath. Returns nil if keysPath is empty.
+func loadTrustedKeys(keysPath string) ([]ed25519.PublicKey, error) {
+	if keysPath == "" {
+		return nil, nil
+	}
+	pemData, err := os.ReadFile(keysPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read trusted keys - %s", err)
+	}
+	publicKeys, err := bringauto_package.ParsePublicKeys(pemData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted keys - %s", err)
+	}
+	return publicKeys, nil
+}
+
+// verifyAllPackages
+// Verifies signatures of archives of all given Packages in repo against the trustedKeys.
+// Packages which are not in repo are skipped. Returns error if any archive is unsigned or its
+// signature is not valid.
+func verifyAllPackages(
+	packages    []bringauto_package.Package,
+	repo        *bringauto_repository.GitLFSRepository,
+	trustedKeys []ed25519.PublicKey,
+) error {
+	for _, pack := range packages {
+		packPath := repo.FindPackageArchivePath(pack)
+		if packPath == "" {
+			continue
+		}
+		err := bringauto_package.VerifyArchive(packPath, trustedKeys)
+		if err != nil {
+			return fmt.Errorf("package '%s' cannot be verified - %s", pack.GetFullPackageName(), err)
+		}
+	}
+	return nil
+}
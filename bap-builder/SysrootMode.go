@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bringauto/modules/bringauto_config"
 	"bringauto/modules/bringauto_context"
 	"bringauto/modules/bringauto_log"
 	"bringauto/modules/bringauto_package"
@@ -10,8 +11,7 @@ import (
 	"io"
 	"os"
 	"path"
-
-	"github.com/mholt/archiver/v3"
+	"slices"
 )
 
 const (
@@ -20,7 +20,10 @@ const (
 )
 
 // CreateSysroot
-// Creates new sysroot based on Context and Packages in Git Lfs.
+// Creates new sysroot based on Context and Packages in Git Lfs. If trusted keys are given,
+// signatures of all Packages are verified before any Package is extracted. Packages are extracted
+// ordered by name and version. If the Package name is given, only the Package and its runtime
+// dependencies are extracted. Only the highest version of each Package is extracted.
 func CreateSysroot(cmdLine *CreateSysrootCmdLineArgs, contextPath string) error {
 	dirEmpty, err := isDirEmpty(*cmdLine.Sysroot)
 	if err != nil {
@@ -30,8 +33,17 @@ func CreateSysroot(cmdLine *CreateSysrootCmdLineArgs, contextPath string) error
 		return fmt.Errorf("given sysroot directory is not empty")
 	}
 
+	packageFormat, err := getPackageFormat(contextPath)
+	if err != nil {
+		return err
+	}
+	trustedKeys, err := loadTrustedKeys(*cmdLine.TrustedKeys)
+	if err != nil {
+		return err
+	}
 	repo := bringauto_repository.GitLFSRepository{
 		GitRepoPath: *cmdLine.Repo,
+		Format:      packageFormat,
 	}
 	err = bringauto_prerequisites.Initialize(&repo)
 	if err != nil {
@@ -50,10 +62,19 @@ func CreateSysroot(cmdLine *CreateSysrootCmdLineArgs, contextPath string) error
 	if err != nil {
 		return err
 	}
-	packages, err := contextManager.GetAllPackagesStructs(platformString)
+	packages, err := getSysrootPackages(*cmdLine.Name, &contextManager, platformString)
 	if err != nil {
 		return err
 	}
+	packages = selectHighestVersions(packages)
+
+	if trustedKeys != nil {
+		logger.Info("Verifying signatures of packages")
+		err = verifyAllPackages(packages, &repo, trustedKeys)
+		if err != nil {
+			return err
+		}
+	}
 
 	logger.Info("Creating sysroot directory from packages")
 	err = unzipAllPackagesToDir(packages, &repo, *cmdLine.Sysroot)
@@ -64,14 +85,70 @@ func CreateSysroot(cmdLine *CreateSysrootCmdLineArgs, contextPath string) error
 	return nil
 }
 
+// getSysrootPackages
+// Returns Packages which are extracted to the sysroot - all Packages in the context if packageName
+// is empty, else the Package and its runtime dependencies.
+func getSysrootPackages(
+	packageName    string,
+	contextManager *bringauto_context.ContextManager,
+	platformString *bringauto_package.PlatformString,
+) ([]bringauto_package.Package, error) {
+	if packageName == "" {
+		return contextManager.GetAllPackagesStructs(platformString)
+	}
+	packageJsonPaths, err := contextManager.GetPackageWithRuntimeDepsJsonDefPaths(packageName)
+	if err != nil {
+		return nil, err
+	}
+	var packages []bringauto_package.Package
+	for _, packageJsonPath := range packageJsonPaths {
+		configs, err := bringauto_config.LoadJSONConfigs(packageJsonPath)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't load JSON config from %s path - %s", packageJsonPath, err)
+		}
+		for _, config := range configs {
+			config.Package.PlatformString = *platformString
+			packages = append(packages, config.Package)
+		}
+	}
+	return packages, nil
+}
+
+// selectHighestVersions
+// Returns packages sorted by name and version with only the highest version of each Package (and
+// build type). Versions of one Package install the same files, so only one of them can be in the
+// sysroot. A warning is printed for each omitted version.
+func selectHighestVersions(packages []bringauto_package.Package) []bringauto_package.Package {
+	slices.SortFunc(packages, bringauto_package.ComparePackages)
+	logger := bringauto_log.GetLogger()
+	var selected []bringauto_package.Package
+	for i := 0; i < len(packages); {
+		last := i
+		for last + 1 < len(packages) && packages[last + 1].Name == packages[i].Name &&
+			packages[last + 1].IsDebug == packages[i].IsDebug {
+			last++
+		}
+		highest := packages[last]
+		for _, pack := range packages[i:last] {
+			if pack.VersionTag != highest.VersionTag {
+				logger.Warn("Package %s %s is not extracted to sysroot, only the highest version %s is extracted",
+					pack.Name, pack.VersionTag, highest.VersionTag)
+			}
+		}
+		selected = append(selected, highest)
+		i = last + 1
+	}
+	return selected
+}
+
 // unzipAllPackagesToDir
-// Unzips all given Packages in repo to specified dirPath.
+// Extracts all given Packages in repo to specified dirPath. Package archives of all supported
+// formats are extracted, the format of the repo is preferred.
 func unzipAllPackagesToDir(packages []bringauto_package.Package, repo *bringauto_repository.GitLFSRepository, dirPath string) error {
 	anyPackageCopied := false
 	for _, pack := range packages {
-		packPath := path.Join(repo.CreatePackagePath(pack), pack.GetFullPackageName() + bringauto_package.ZipExt)
-		_, err := os.Stat(packPath)
-		if err == nil { // Package exists in Git Lfs
+		packPath := repo.FindPackageArchivePath(pack)
+		if packPath != "" { // Package exists in Git Lfs
 			var sysrootPath string
 			if pack.IsDebug {
 				sysrootPath = path.Join(dirPath, DebugPath)
@@ -79,12 +156,7 @@ func unzipAllPackagesToDir(packages []bringauto_package.Package, repo *bringauto
 				sysrootPath = path.Join(dirPath, ReleasePath)
 			}
 
-			zipArchive := archiver.Zip{
-				MkdirAll:             true,
-				OverwriteExisting:    false,
-				SelectiveCompression: true,
-			}
-			err := zipArchive.Unarchive(packPath, sysrootPath)
+			err := bringauto_package.ExtractArchive(packPath, sysrootPath)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bringauto/modules/bringauto_context"
+	"bringauto/modules/bringauto_log"
+	"fmt"
+)
+
+// ValidateContext
+// Checks all Package Configs in the Context and prints all problems found. Returns error if any
+// problem is found.
+func ValidateContext(contextPath string) error {
+	contextManager := bringauto_context.ContextManager{
+		ContextPath: contextPath,
+	}
+	logger := bringauto_log.GetLogger()
+	logger.Info("Validating context %s", contextPath)
+	problems, err := contextManager.ValidateContext()
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		logger.Info("Context is valid")
+		return nil
+	}
+	logger.Error("%d problems found in context:", len(problems))
+	for _, problem := range problems {
+		logger.ErrorIndent("%s", problem.String())
+	}
+	return fmt.Errorf("context is not valid")
+}
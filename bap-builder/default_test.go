@@ -0,0 +1,663 @@
+package main
+
+import (
+	"bringauto/modules/bringauto_build"
+	"bringauto/modules/bringauto_config"
+	"bringauto/modules/bringauto_package"
+	"bringauto/modules/bringauto_repository"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testPlatformString = bringauto_package.PlatformString{
+	Mode: bringauto_package.ModeExplicit,
+	String: bringauto_package.PlatformStringExplicit{
+		DistroName:    "ubuntu",
+		DistroRelease: "2204",
+		Machine:       "x86_64",
+	},
+}
+
+// testRunner
+// Records runs of build jobs, jobs listed in fail fail in the make step. Each job runs for delay.
+type testRunner struct {
+	lock       sync.Mutex
+	fail       []string
+	delay      time.Duration
+	usedSlots  map[int]bool
+	started    []string
+	finished   []string
+	slots      []int
+	running    int
+	maxRunning int
+}
+
+func (runner *testRunner) run(job *buildJob, slot int) (string, error) {
+	name := job.config.Package.Name
+	runner.lock.Lock()
+	for _, dep := range job.dependsOn {
+		if !slices.Contains(runner.finished, dep) {
+			runner.lock.Unlock()
+			return BuildStatusFailed, fmt.Errorf("%s started before its dependency %s", name, dep)
+		}
+	}
+	if runner.usedSlots[slot] {
+		runner.lock.Unlock()
+		return BuildStatusFailed, fmt.Errorf("%s started in slot %d which is in use", name, slot)
+	}
+	if runner.usedSlots == nil {
+		runner.usedSlots = map[int]bool{}
+	}
+	runner.usedSlots[slot] = true
+	runner.started = append(runner.started, name)
+	runner.slots = append(runner.slots, slot)
+	runner.running++
+	runner.maxRunning = max(runner.maxRunning, runner.running)
+	runner.lock.Unlock()
+
+	time.Sleep(runner.delay)
+
+	runner.lock.Lock()
+	defer runner.lock.Unlock()
+	runner.running--
+	runner.usedSlots[slot] = false
+	if slices.Contains(runner.fail, name) {
+		return BuildStatusFailed, &bringauto_build.BuildError{Step: bringauto_build.BuildStepMake, Err: fmt.Errorf("%s failed", name)}
+	}
+	runner.finished = append(runner.finished, jobKey(job.config.Package))
+	return BuildStatusBuilt, nil
+}
+
+func newTestConfig(name string, versionTag string, dependsOn ...string) *bringauto_config.Config {
+	return &bringauto_config.Config{
+		Package: bringauto_package.Package{
+			Name:       name,
+			VersionTag: versionTag,
+		},
+		DependsOn: dependsOn,
+	}
+}
+
+func newTestJobs(configs ...*bringauto_config.Config) []*buildJob {
+	var jobs []*buildJob
+	for _, config := range configs {
+		jobs = append(jobs, &buildJob{config: config})
+	}
+	setJobDependencies(jobs, configs)
+	return jobs
+}
+
+func newTestScheduler(jobsCount int, keepGoing bool) *buildScheduler {
+	return &buildScheduler{
+		jobsCount: jobsCount,
+		keepGoing: keepGoing,
+		report:    newBuildReport("test"),
+	}
+}
+
+// chdirTemp
+// Changes the working directory to a temporary directory for the rest of the test.
+func chdirTemp(t *testing.T) {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("can't get working dir - %s", err)
+	}
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatalf("can't change working dir - %s", err)
+	}
+	t.Cleanup(func() {
+		err := os.Chdir(workingDir)
+		if err != nil {
+			t.Fatalf("can't change working dir back - %s", err)
+		}
+	})
+}
+
+func TestSetJobDependencies(t *testing.T) {
+	jobs := newTestJobs(
+		newTestConfig("lib", "v1.0.0"),
+		newTestConfig("lib", "v2.0.0"),
+		newTestConfig("app", "v1.0.0", "lib < 2.0", "external"),
+		newTestConfig("tool", "v1.0.0", "lib", "app"),
+	)
+
+	expected := [][]string{
+		nil,
+		nil,
+		{"lib:v1.0.0:false"},
+		{"lib:v2.0.0:false", "app:v1.0.0:false"},
+	}
+	for i, job := range jobs {
+		if !slices.Equal(job.dependsOn, expected[i]) {
+			t.Errorf("invalid dependencies of job %s: %v, expected %v", jobKey(job.config.Package), job.dependsOn, expected[i])
+		}
+	}
+}
+
+func TestBuildScheduler_DependencyOrder(t *testing.T) {
+	jobs := newTestJobs(
+		newTestConfig("a", "v1.0.0"),
+		newTestConfig("b", "v1.0.0", "a"),
+		newTestConfig("c", "v1.0.0", "a"),
+		newTestConfig("d", "v1.0.0", "b", "c"),
+	)
+	runner := testRunner{delay: 10 * time.Millisecond}
+	scheduler := newTestScheduler(4, false)
+
+	err := scheduler.runJobs(jobs, runner.run)
+	if err != nil {
+		t.Fatalf("run failed - %s", err)
+	}
+	if len(runner.started) != 4 || runner.started[0] != "a" || runner.started[3] != "d" {
+		t.Errorf("invalid build order %v", runner.started)
+	}
+	if scheduler.report.Built != 4 {
+		t.Errorf("invalid number of built packages %d", scheduler.report.Built)
+	}
+}
+
+func TestBuildScheduler_SlotReuse(t *testing.T) {
+	var configs []*bringauto_config.Config
+	for i := 0; i < 6; i++ {
+		configs = append(configs, newTestConfig("pack"+strconv.Itoa(i), "v1.0.0"))
+	}
+	jobs := newTestJobs(configs...)
+	runner := testRunner{delay: 20 * time.Millisecond}
+	scheduler := newTestScheduler(2, false)
+
+	err := scheduler.runJobs(jobs, runner.run)
+	if err != nil {
+		t.Fatalf("run failed - %s", err)
+	}
+	if len(runner.started) != 6 {
+		t.Errorf("invalid number of started jobs %d", len(runner.started))
+	}
+	if runner.maxRunning != 2 {
+		t.Errorf("%d jobs running at the same time, expected 2", runner.maxRunning)
+	}
+	for _, slot := range runner.slots {
+		if slot < 0 || slot > 1 {
+			t.Errorf("invalid slot %d", slot)
+		}
+	}
+}
+
+func TestBuildScheduler_FailureStopsBuild(t *testing.T) {
+	jobs := newTestJobs(
+		newTestConfig("a", "v1.0.0"),
+		newTestConfig("b", "v1.0.0", "a"),
+		newTestConfig("c", "v1.0.0"),
+	)
+	runner := testRunner{fail: []string{"a"}}
+	scheduler := newTestScheduler(1, false)
+
+	err := scheduler.runJobs(jobs, runner.run)
+	if err == nil {
+		t.Fatalf("run succeeded although a build failed")
+	}
+	if !slices.Equal(runner.started, []string{"a"}) {
+		t.Errorf("jobs started after the failure: %v", runner.started)
+	}
+	if scheduler.report.Failed != 1 || scheduler.report.Skipped != 0 {
+		t.Errorf("invalid report - failed %d, skipped %d", scheduler.report.Failed, scheduler.report.Skipped)
+	}
+}
+
+func TestBuildScheduler_KeepGoing(t *testing.T) {
+	jobs := newTestJobs(
+		newTestConfig("a", "v1.0.0"),
+		newTestConfig("b", "v1.0.0", "a"),
+		newTestConfig("c", "v1.0.0", "b"),
+		newTestConfig("d", "v1.0.0"),
+	)
+	runner := testRunner{fail: []string{"a"}}
+	scheduler := newTestScheduler(1, true)
+
+	err := scheduler.runJobs(jobs, runner.run)
+	if err == nil {
+		t.Fatalf("run succeeded although a build failed")
+	}
+	if !slices.Equal(runner.started, []string{"a", "d"}) {
+		t.Errorf("invalid started jobs %v, expected [a d]", runner.started)
+	}
+	if scheduler.report.Failed != 1 || scheduler.report.Skipped != 2 || scheduler.report.Built != 1 {
+		t.Errorf("invalid report - built %d, failed %d, skipped %d", scheduler.report.Built,
+			scheduler.report.Failed, scheduler.report.Skipped)
+	}
+}
+
+func TestBuildReport_SaveJSON(t *testing.T) {
+	jobs := newTestJobs(
+		newTestConfig("a", "v1.0.0"),
+		newTestConfig("b", "v1.0.0", "a"),
+		newTestConfig("c", "v1.0.0"),
+	)
+	runner := testRunner{fail: []string{"a"}}
+	scheduler := newTestScheduler(1, true)
+	_ = scheduler.runJobs(jobs, runner.run)
+
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	err := scheduler.report.saveJSON(reportPath)
+	if err != nil {
+		t.Fatalf("saveJSON failed - %s", err)
+	}
+	mbytes, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("cannot read report - %s", err)
+	}
+	var report BuildReport
+	err = json.Unmarshal(mbytes, &report)
+	if err != nil {
+		t.Fatalf("cannot parse report - %s", err)
+	}
+
+	if report.ImageName != "test" || report.Built != 1 || report.Failed != 1 || report.Skipped != 1 {
+		t.Errorf("invalid report counts - built %d, failed %d, skipped %d", report.Built, report.Failed, report.Skipped)
+	}
+	expected := []struct {
+		name       string
+		status     string
+		err        string
+		failedStep string
+	}{
+		{"a", BuildStatusFailed, "make step failed - a failed", bringauto_build.BuildStepMake},
+		{"b", BuildStatusSkipped, "dependency a:v1.0.0:false failed", ""},
+		{"c", BuildStatusBuilt, "", ""},
+	}
+	if len(report.Packages) != len(expected) {
+		t.Fatalf("invalid number of packages in report %d", len(report.Packages))
+	}
+	for i, entry := range report.Packages {
+		if entry.Name != expected[i].name || entry.Status != expected[i].status || entry.Error != expected[i].err ||
+			entry.FailedStep != expected[i].failedStep {
+			t.Errorf("invalid report entry %+v, expected %+v", entry, expected[i])
+		}
+	}
+}
+
+func TestBuildReport_SaveJUnit(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "build_chain.txt")
+	var log strings.Builder
+	for i := range junitLogTailLinesConst + 10 {
+		log.WriteString("line " + strconv.Itoa(i) + "\n")
+	}
+	err := os.WriteFile(logPath, []byte(log.String()), 0644)
+	if err != nil {
+		t.Fatalf("can't write log - %s", err)
+	}
+	report := newBuildReport("image")
+	report.add(ReportEntry{Name: "a", FullPackageName: "a_v1.0.0", Status: BuildStatusBuilt, Duration: 1, order: 0})
+	report.add(ReportEntry{Name: "b", FullPackageName: "b_v1.0.0", Status: BuildStatusFailed, Duration: 2,
+		Error: "make step failed", FailedStep: bringauto_build.BuildStepMake, LogFilePath: logPath, order: 1})
+	report.add(ReportEntry{Name: "c", FullPackageName: "c_v1.0.0", Status: BuildStatusSkipped,
+		Error: "dependency b:v1.0.0:false failed", order: 2})
+
+	reportPath := filepath.Join(t.TempDir(), "report.xml")
+	err = report.saveJUnit(reportPath)
+	if err != nil {
+		t.Fatalf("saveJUnit failed - %s", err)
+	}
+	mbytes, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("cannot read report - %s", err)
+	}
+	var suites junitTestSuites
+	err = xml.Unmarshal(mbytes, &suites)
+	if err != nil {
+		t.Fatalf("cannot parse report - %s", err)
+	}
+
+	if suites.Tests != 3 || suites.Failures != 1 || suites.Skipped != 1 || len(suites.TestSuites) != 1 {
+		t.Fatalf("invalid testsuites counts - %d tests, %d failures, %d skipped", suites.Tests, suites.Failures, suites.Skipped)
+	}
+	suite := suites.TestSuites[0]
+	if suite.Name != "image" || suite.Tests != 3 || suite.Failures != 1 || suite.Skipped != 1 || suite.Time != 3 {
+		t.Errorf("invalid testsuite %+v", suite)
+	}
+	if len(suite.TestCases) != 3 {
+		t.Fatalf("invalid number of testcases %d", len(suite.TestCases))
+	}
+	for i, name := range []string{"a_v1.0.0", "b_v1.0.0", "c_v1.0.0"} {
+		if suite.TestCases[i].Name != name || suite.TestCases[i].ClassName != "image" {
+			t.Errorf("invalid testcase %+v", suite.TestCases[i])
+		}
+	}
+	if suite.TestCases[0].Failure != nil || suite.TestCases[0].Skipped != nil {
+		t.Error("built package reported as failed or skipped")
+	}
+	failure := suite.TestCases[1].Failure
+	if failure == nil || failure.Type != bringauto_build.BuildStepMake || !strings.Contains(failure.Message, "make step failed") {
+		t.Fatalf("invalid failure %+v", failure)
+	}
+	logLines := strings.Split(failure.Contents, "\n")
+	if len(logLines) != junitLogTailLinesConst || logLines[len(logLines) - 1] != "line " + strconv.Itoa(junitLogTailLinesConst + 9) {
+		t.Errorf("invalid log tail - %d lines, last %s", len(logLines), logLines[len(logLines) - 1])
+	}
+	skipped := suite.TestCases[2].Skipped
+	if skipped == nil || skipped.Message != "dependency b:v1.0.0:false failed" {
+		t.Errorf("invalid skipped %+v", skipped)
+	}
+}
+
+func TestReadLogTail(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "build_chain.txt")
+	err := os.WriteFile(logPath, []byte("line 1\nline 2\nline 3\n"), 0644)
+	if err != nil {
+		t.Fatalf("can't write log - %s", err)
+	}
+	tests := []struct {
+		logPath    string
+		linesCount int
+		expected   string
+	}{
+		{logPath, 2, "line 2\nline 3"},
+		{logPath, 5, "line 1\nline 2\nline 3"},
+		{"", 5, ""},
+		{logPath + ".missing", 5, ""},
+	}
+	for _, test := range tests {
+		tail := readLogTail(test.logPath, test.linesCount)
+		if tail != test.expected {
+			t.Errorf("invalid tail of %s - %q, expected %q", test.logPath, tail, test.expected)
+		}
+	}
+}
+
+func TestContextPackages_GetSysrootPackages(t *testing.T) {
+	libV1 := newTestConfig("lib", "v1.0.0", "zlib")
+	libV1.BuildDependsOn = []string{"generator"}
+	packages := contextPackages{
+		configs: []*bringauto_config.Config{
+			newTestConfig("zlib", "v1.3.0"),
+			newTestConfig("generator", "v1.0.0"),
+			libV1,
+			newTestConfig("lib", "v2.0.0"),
+		},
+	}
+
+	tests := []struct {
+		config   *bringauto_config.Config
+		expected []string
+	}{
+		{newTestConfig("app", "v1.0.0", "lib < 2.0", "external"), []string{"lib:v1.0.0:false", "zlib:v1.3.0:false"}},
+		{newTestConfig("tool", "v1.0.0", "lib"), []string{"lib:v2.0.0:false"}},
+		{libV1, []string{"zlib:v1.3.0:false", "generator:v1.0.0:false"}},
+	}
+	for _, test := range tests {
+		var keys []string
+		for _, pack := range packages.getSysrootPackages(test.config) {
+			keys = append(keys, jobKey(pack))
+		}
+		if !slices.Equal(keys, test.expected) {
+			t.Errorf("invalid sysroot packages of %s: %v, expected %v", test.config.Package.Name, keys, test.expected)
+		}
+	}
+}
+
+func TestContextPackages_ComputeCacheKeys(t *testing.T) {
+	imageName := "image"
+	disabled := false
+	cmdLine := BuildPackageCmdLineArgs{
+		DockerImageName:   &imageName,
+		Reproducible:      &disabled,
+		SplitDebugSymbols: &disabled,
+	}
+	lib := newTestConfig("lib", "v1.0.0")
+	lib.Patches = []string{filepath.Join(t.TempDir(), "missing.patch")}
+	app := newTestConfig("app", "v1.0.0", "lib")
+	tool := newTestConfig("tool", "v1.0.0")
+	packages := contextPackages{
+		configs: []*bringauto_config.Config{lib, app, tool},
+	}
+	for _, config := range packages.configs {
+		config.DockerMatrix.ImageNames = []string{imageName}
+	}
+
+	packages.computeCacheKeys(&cmdLine, &testPlatformString)
+	if _, found := packages.cacheKeyErrors["lib:v1.0.0:false"]; !found {
+		t.Error("missing patch of lib not reported")
+	}
+	for _, key := range []string{"app:v1.0.0:false", "tool:v1.0.0:false"} {
+		if _, found := packages.cacheKeys[key]; !found {
+			t.Errorf("cache key of %s not computed", key)
+		}
+		if _, found := packages.cacheKeyErrors[key]; found {
+			t.Errorf("error of lib reported for %s", key)
+		}
+	}
+}
+
+func TestSelectHighestVersions(t *testing.T) {
+	var packages []bringauto_package.Package
+	for _, config := range []*bringauto_config.Config{
+		newTestConfig("lib", "v2.0.0"),
+		newTestConfig("app", "v1.0.0"),
+		newTestConfig("lib", "v1.10.0"),
+		newTestConfig("lib", "v2.0.0"),
+		newTestConfig("zlib", "v1.3.0"),
+	} {
+		packages = append(packages, config.Package)
+	}
+	debugLib := newTestConfig("lib", "v1.0.0").Package
+	debugLib.IsDebug = true
+	packages = append(packages, debugLib)
+
+	var keys []string
+	for _, pack := range selectHighestVersions(packages) {
+		keys = append(keys, jobKey(pack))
+	}
+	expected := []string{"app:v1.0.0:false", "lib:v2.0.0:false", "lib:v1.0.0:true", "zlib:v1.3.0:false"}
+	if !slices.Equal(keys, expected) {
+		t.Errorf("invalid selected packages: %v, expected %v", keys, expected)
+	}
+}
+
+func TestCreateBuildPlan(t *testing.T) {
+	imageName := "image"
+	a := newTestConfig("a", "v1.0.0")
+	b := newTestConfig("b", "v1.0.0", "a")
+	c := newTestConfig("c", "v1.0.0")
+	a.DockerMatrix.ImageNames = []string{imageName}
+	b.DockerMatrix.ImageNames = []string{imageName}
+	c.DockerMatrix.ImageNames = []string{"other"}
+	configList := []*bringauto_config.Config{a, b, c}
+
+	repo := bringauto_repository.GitLFSRepository{
+		GitRepoPath: t.TempDir(),
+		Format:      bringauto_package.FormatZip,
+	}
+	cachedPack := a.Package
+	cachedPack.PlatformString = testPlatformString
+	err := os.MkdirAll(repo.CreatePackagePath(cachedPack), 0755)
+	if err != nil {
+		t.Fatalf("can't create package directory - %s", err)
+	}
+	err = os.WriteFile(repo.GetPackageArchivePath(cachedPack), []byte{}, 0644)
+	if err != nil {
+		t.Fatalf("can't create package archive - %s", err)
+	}
+	err = repo.SaveCacheKey(cachedPack, "key-a")
+	if err != nil {
+		t.Fatalf("can't save cache key - %s", err)
+	}
+	cacheKeys := map[string]string{"a:v1.0.0:false": "key-a", "b:v1.0.0:false": "key-b"}
+
+	notForImage := "not built for image image"
+	tests := []struct {
+		name      string
+		useCache  bool
+		completed map[string]string
+		expected  []string
+	}{
+		{"build", false, nil, []string{"a", "b", notForImage}},
+		{"use cache", true, nil, []string{"already in Package Repository with the same cache key", "b", notForImage}},
+		{"resume", false, map[string]string{"a:v1.0.0:false": "key-a"}, []string{"already built by the resumed build", "b", notForImage}},
+		{"resume changed", false, map[string]string{"a:v1.0.0:false": "old-key"}, []string{"a", "b", notForImage}},
+	}
+	for _, test := range tests {
+		var journal *buildJournal
+		if test.completed != nil {
+			journal = newBuildJournal(imageName)
+			journal.Completed = test.completed
+		}
+		plan := createBuildPlan(configList, imageName, &testPlatformString, &repo, cacheKeys, test.useCache, journal)
+		var entries []string
+		for _, entry := range plan.Packages {
+			if entry.Skipped {
+				entries = append(entries, entry.SkipReason)
+			} else {
+				entries = append(entries, entry.Name)
+			}
+		}
+		if !slices.Equal(entries, test.expected) {
+			t.Errorf("%s: invalid plan %v, expected %v", test.name, entries, test.expected)
+		}
+	}
+}
+
+func TestBuildPlan_Print(t *testing.T) {
+	plan := BuildPlan{
+		ImageName:      "image",
+		PlatformString: "x86_64-ubuntu-2204",
+		Packages: []PlanEntry{
+			{Name: "a", FullPackageName: "a_v1.0.0_x86_64-ubuntu-2204", RepositoryPath: "repo/a.zip"},
+			{Name: "b", FullPackageName: "b_v1.0.0_x86_64-ubuntu-2204", Skipped: true, SkipReason: "cached"},
+			{Name: "c", FullPackageName: "c_v1.0.0_x86_64-ubuntu-2204", DependsOn: []string{"a"}, RepositoryPath: "repo/c.zip"},
+		},
+	}
+
+	var text strings.Builder
+	err := plan.print(&text, PlanFormatText)
+	if err != nil {
+		t.Fatalf("print failed - %s", err)
+	}
+	expected := "Build plan for image image (x86_64-ubuntu-2204):\n" +
+		"  1. a_v1.0.0_x86_64-ubuntu-2204 -> repo/a.zip\n" +
+		"  -  b_v1.0.0_x86_64-ubuntu-2204 (skipped: cached)\n" +
+		"  2. c_v1.0.0_x86_64-ubuntu-2204 -> repo/c.zip\n" +
+		"2 packages to build, 1 skipped\n"
+	if text.String() != expected {
+		t.Errorf("invalid text plan:\n%s\nexpected:\n%s", text.String(), expected)
+	}
+
+	var jsonText strings.Builder
+	err = plan.print(&jsonText, PlanFormatJSON)
+	if err != nil {
+		t.Fatalf("print failed - %s", err)
+	}
+	var loaded BuildPlan
+	err = json.Unmarshal([]byte(jsonText.String()), &loaded)
+	if err != nil {
+		t.Fatalf("can't parse JSON plan - %s", err)
+	}
+	if loaded.ImageName != plan.ImageName || len(loaded.Packages) != len(plan.Packages) {
+		t.Fatalf("invalid JSON plan - %s", jsonText.String())
+	}
+	for i, entry := range loaded.Packages {
+		expectedEntry := plan.Packages[i]
+		if entry.FullPackageName != expectedEntry.FullPackageName || entry.Skipped != expectedEntry.Skipped ||
+			entry.SkipReason != expectedEntry.SkipReason || !slices.Equal(entry.DependsOn, expectedEntry.DependsOn) {
+			t.Errorf("invalid JSON plan entry %v, expected %v", entry, expectedEntry)
+		}
+	}
+
+	err = plan.print(&text, "yaml")
+	if err == nil {
+		t.Error("unsupported format not reported")
+	}
+}
+
+func TestSaveLoadPlatformString(t *testing.T) {
+	chdirTemp(t)
+	platformString, err := loadPlatformString("image")
+	if err != nil {
+		t.Fatalf("loadPlatformString failed - %s", err)
+	}
+	if platformString.Serialize() != "unknown-unknown-unknown" {
+		t.Errorf("invalid platform string of not built image - %s", platformString.Serialize())
+	}
+
+	err = savePlatformString("image", &testPlatformString)
+	if err != nil {
+		t.Fatalf("savePlatformString failed - %s", err)
+	}
+	platformString, err = loadPlatformString("image")
+	if err != nil {
+		t.Fatalf("loadPlatformString failed - %s", err)
+	}
+	if platformString.Serialize() != testPlatformString.Serialize() {
+		t.Errorf("invalid loaded platform string - %s", platformString.Serialize())
+	}
+	platformString, err = loadPlatformString("other")
+	if err != nil {
+		t.Fatalf("loadPlatformString failed - %s", err)
+	}
+	if platformString.Serialize() == testPlatformString.Serialize() {
+		t.Error("platform string of another image loaded")
+	}
+}
+
+func TestBuildJournal(t *testing.T) {
+	chdirTemp(t)
+	journal, err := loadBuildJournal("image")
+	if err != nil {
+		t.Fatalf("loadBuildJournal failed - %s", err)
+	}
+	if journal.hasCompleted() {
+		t.Error("new journal has completed packages")
+	}
+
+	err = journal.markCompleted("a:v1.0.0:false", "key-a")
+	if err != nil {
+		t.Fatalf("markCompleted failed - %s", err)
+	}
+	if !journal.hasCompleted() || !journal.isCompleted("a:v1.0.0:false", "key-a") {
+		t.Error("completed package not recorded")
+	}
+	if journal.isCompleted("a:v1.0.0:false", "key-changed") || journal.isCompleted("b:v1.0.0:false", "key-a") {
+		t.Error("package completed with another cache key or not built reported as completed")
+	}
+
+	resumed, err := loadBuildJournal("image")
+	if err != nil {
+		t.Fatalf("loadBuildJournal of resumed build failed - %s", err)
+	}
+	if !resumed.isCompleted("a:v1.0.0:false", "key-a") {
+		t.Error("completed package not loaded by resumed build")
+	}
+
+	err = os.Rename(getBuildJournalPath("image"), getBuildJournalPath("other"))
+	if err != nil {
+		t.Fatalf("can't rename journal - %s", err)
+	}
+	_, err = loadBuildJournal("other")
+	if err == nil {
+		t.Error("journal of another image accepted")
+	}
+	err = os.Rename(getBuildJournalPath("other"), getBuildJournalPath("image"))
+	if err != nil {
+		t.Fatalf("can't rename journal - %s", err)
+	}
+
+	err = resumed.remove()
+	if err != nil {
+		t.Fatalf("remove failed - %s", err)
+	}
+	if _, err = os.Stat(getBuildJournalPath("image")); !os.IsNotExist(err) {
+		t.Error("journal not removed")
+	}
+	err = resumed.remove()
+	if err != nil {
+		t.Errorf("remove of removed journal failed - %s", err)
+	}
+}
@@ -49,5 +49,32 @@ func main() {
 		return
 	}
 
+	if args.Graph {
+		err = PrintDependencyGraph(&args.GraphArgs, *args.Context)
+		if err != nil {
+			logger.Error("Failed to print dependency graph: %s", err)
+			return
+		}
+		return
+	}
+
+	if args.InspectPackage {
+		err = InspectPackage(&args.InspectPackageArgs)
+		if err != nil {
+			logger.Error("Failed to inspect package: %s", err)
+			return
+		}
+		return
+	}
+
+	if args.ValidateContext {
+		err = ValidateContext(*args.Context)
+		if err != nil {
+			logger.Error("Context validation failed: %s", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	return
 }
@@ -0,0 +1,103 @@
+package bringauto_build
+
+import (
+	"bringauto/modules/bringauto_prerequisites"
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	defaultConfigureScriptConst = "configure"
+)
+
+// Autotools cmd line interface for projects configured by a configure script. The project is
+// configured in its source directory and then built and installed by GNU Make.
+type Autotools struct {
+	// Options passed to the configure script, for example "--enable-shared"
+	Options []string
+	// Name of the configure script located in ConfigureDir. Default value is "configure"
+	ConfigureScript string
+	// Directory where the configure script is located, relative to the project root.
+	// Default value is "./"
+	ConfigureDir string
+	// If true, 'autoreconf -fi' is run before configure (needed if the configure
+	// script is not part of the repository)
+	AutoReconf    bool
+	SourceDir     string `json:"-"`
+	installPrefix string
+	sysrootDir    string
+}
+
+func (autotools *Autotools) FillDefault(*bringauto_prerequisites.Args) error {
+	*autotools = Autotools{
+		Options:         []string{},
+		ConfigureScript: defaultConfigureScriptConst,
+		ConfigureDir:    "./",
+	}
+	return nil
+}
+
+func (autotools *Autotools) FillDynamic(*bringauto_prerequisites.Args) error {
+	if autotools.ConfigureScript == "" {
+		autotools.ConfigureScript = defaultConfigureScriptConst
+	}
+	return nil
+}
+
+func (autotools *Autotools) CheckPrerequisites(*bringauto_prerequisites.Args) error {
+	if strings.Contains(autotools.ConfigureScript, "/") {
+		return fmt.Errorf("configure script name cannot contain '/', use ConfigureDir instead")
+	}
+	return nil
+}
+
+func (autotools *Autotools) SetSourceDir(sourceDir string) {
+	autotools.SourceDir = sourceDir
+}
+
+func (autotools *Autotools) SetInstallPrefix(installPrefix string) error {
+	for _, option := range autotools.Options {
+		if option == "--prefix" || strings.HasPrefix(option, "--prefix=") {
+			return fmt.Errorf("do not specify --prefix configure option")
+		}
+	}
+	autotools.installPrefix = installPrefix
+	return nil
+}
+
+func (autotools *Autotools) SetSysrootDir(sysrootDir string) {
+	autotools.sysrootDir = sysrootDir
+}
+
+// ConstructCMDLine
+// Changes the working directory to the configure script directory, so the subsequent
+// GNU Make commands are run in the configured directory.
+func (autotools *Autotools) ConstructCMDLine() []string {
+	if autotools.SourceDir == "" {
+		panic(fmt.Errorf("autotools source directory does not exist"))
+	}
+	configureScript := autotools.ConfigureScript
+	if configureScript == "" {
+		configureScript = defaultConfigureScriptConst
+	}
+	commands := []string{"cd " + path.Join(autotools.SourceDir, autotools.ConfigureDir)}
+	if autotools.AutoReconf {
+		commands = append(commands, "autoreconf -fi")
+	}
+
+	cmdConfigure := []string{"./" + configureScript}
+	if autotools.installPrefix != "" {
+		cmdConfigure = append(cmdConfigure, "--prefix="+escapeVariableValue(autotools.installPrefix))
+	}
+	cmdConfigure = append(cmdConfigure, autotools.Options...)
+	if autotools.sysrootDir != "" {
+		cmdConfigure = append(cmdConfigure,
+			"CPPFLAGS="+escapeVariableValue("-I"+path.Join(autotools.sysrootDir, "include")),
+			"LDFLAGS="+escapeVariableValue("-L"+path.Join(autotools.sysrootDir, "lib")),
+			"PKG_CONFIG_PATH="+escapeVariableValue(sysrootPkgConfigPath(autotools.sysrootDir)),
+		)
+	}
+	commands = append(commands, strings.Join(cmdConfigure, " "))
+	return commands
+}
@@ -7,11 +7,14 @@ import (
 	"bringauto/modules/bringauto_const"
 	"bringauto/modules/bringauto_package"
 	"bringauto/modules/bringauto_prerequisites"
+	"bringauto/modules/bringauto_source"
 	"bringauto/modules/bringauto_ssh"
 	"bringauto/modules/bringauto_sysroot"
 	"bringauto/modules/bringauto_process"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"time"
 )
@@ -20,11 +23,25 @@ type Build struct {
 	Env            *EnvironmentVariables
 	Docker         *bringauto_docker.Docker
 	Git            *bringauto_git.Git
+	Tarball        *bringauto_source.Tarball
+	LocalDirectory *bringauto_source.LocalDirectory
+	// Patches paths of the patch files on the host, applied in the given order
+	Patches        []string
+	// ForbidBranchRevision if true, the build fails if the Git Revision is a branch
+	ForbidBranchRevision bool
+	// SplitDebugSymbols if true, debug symbols of the installed ELF files are split to the
+	// separate directory, see GetLocalDebugSymbolsDirPath
+	SplitDebugSymbols bool
 	CMake          *CMake
+	Meson          *Meson
+	Autotools      *Autotools
+	CustomBuild    *CustomBuild
 	GNUMake        *GNUMake
 	SSHCredentials *bringauto_ssh.SSHCredentials
 	Package        *bringauto_package.Package
 	sysroot        *bringauto_sysroot.Sysroot
+	// Name of the local directory where the installed files are copied from the container
+	localInstallDirName string
 }
 
 type buildInitArgs struct {
@@ -58,6 +75,9 @@ func (build *Build) FillDefault(args *bringauto_prerequisites.Args) error {
 	if build.Package == nil {
 		build.Package = bringauto_prerequisites.CreateAndInitialize[bringauto_package.Package]()
 	}
+	if build.localInstallDirName == "" {
+		build.localInstallDirName = localInstallDirNameConst
+	}
 
 	return nil
 }
@@ -67,76 +87,96 @@ func (build *Build) FillDynamic(*bringauto_prerequisites.Args) error {
 }
 
 func (build *Build) CheckPrerequisites(*bringauto_prerequisites.Args) error {
-	copyDir := build.GetLocalInstallDirPath()
-	if _, err := os.Stat(copyDir); !os.IsNotExist(err) {
-		return fmt.Errorf("package directory exist. Please delete it: %s", copyDir)
+	for _, copyDir := range build.getLocalDirPaths() {
+		if _, err := os.Stat(copyDir); !os.IsNotExist(err) {
+			return fmt.Errorf("package directory exist. Please delete it: %s", copyDir)
+		}
 	}
 
 	return nil
 }
 
 // RunBuild
-// s
+// Builds the Package in the docker container and downloads the installed files to the local
+// install directory. Returned error is *BuildError which holds the step in which the build failed.
 func (build *Build) RunBuild() error {
 	var err error
 
 	err = build.CheckPrerequisites(nil)
 	if err != nil {
-		return err
+		return &BuildError{Step: BuildStepPrepare, Err: err}
 	}
 
-	build.Git.ClonePath = dockerGitCloneDirConst
-	build.CMake.SourceDir = dockerGitCloneDirConst
+	buildSystem, buildSystemChain := build.getBuildSystem()
+	buildSystem.SetSourceDir(dockerGitCloneDirConst)
 
-	_, found := build.CMake.Defines["CMAKE_INSTALL_PREFIX"]
-	if found {
-		return fmt.Errorf("do not specify CMAKE_INSTALL_PREFIX")
+	err = buildSystem.SetInstallPrefix(bringauto_const.DockerInstallDirConst)
+	if err != nil {
+		return &BuildError{Step: BuildStepPrepare, Err: err}
 	}
-	build.CMake.Defines["CMAKE_INSTALL_PREFIX"] = bringauto_const.DockerInstallDirConst
 
 	if build.sysroot != nil {
 		build.sysroot.CreateSysrootDir()
 		sysPath := build.sysroot.GetSysrootPath()
-		build.Docker.SetVolume(sysPath, "/sysroot")
-		build.CMake.SetDefine("CMAKE_PREFIX_PATH", "/sysroot")
+		build.Docker.SetVolume(sysPath, dockerSysrootDirConst)
+		buildSystem.SetSysrootDir(dockerSysrootDirConst)
 	}
 
-	gitClone := bringauto_git.GitClone{Git: *build.Git}
-	gitCheckout := bringauto_git.GitCheckout{Git: *build.Git}
-	gitSubmoduleUpdate := bringauto_git.GitSubmoduleUpdate{Git: *build.Git}
+	sourceChain, err := build.prepareSource()
+	if err != nil {
+		return &BuildError{Step: BuildStepFetch, Err: err}
+	}
 	startupScript := bringauto_prerequisites.CreateAndInitialize[StartupScript]()
 
+	chain := []CMDLineInterface{
+		&BuildStepMarker{Step: BuildStepSetup},
+		startupScript,
+		build.Env,
+		&buildStepTrap{},
+	}
+	chain = append(chain, sourceChain...)
+	patchChain, err := build.preparePatches()
+	if err != nil {
+		return &BuildError{Step: BuildStepPrepare, Err: err}
+	}
+	chain = append(chain, patchChain...)
+	chain = append(chain, buildSystemChain...)
+	if build.SplitDebugSymbols {
+		chain = append(chain,
+			&BuildStepMarker{Step: BuildStepDbgsym},
+			build.getDebugSymbolSplit(),
+		)
+	}
 	buildChain := BuildChain{
-		Chain: []CMDLineInterface{
-			startupScript,
-			build.Env,
-			&gitClone,
-			&gitCheckout,
-			&gitSubmoduleUpdate,
-			build.CMake,
-			build.GNUMake,
-		},
+		Chain: chain,
 	}
 
 	logger := bringauto_log.GetLogger()
-	packBuildChainLogger := logger.CreateContextLogger(build.Docker.ImageName, build.Package.GetShortPackageName(), bringauto_log.BuildChainContext)
+	packBuildChainLogger := logger.CreateContextLogger(build.Docker.ImageName, build.Package.GetFullPackageName(), bringauto_log.BuildChainContext)
 	file, err := packBuildChainLogger.GetFile()
 
 	if err != nil {
 		logger.Error("Failed to open file - %s", err)
-		return err
+		return &BuildError{Step: BuildStepPrepare, Err: err}
 	}
 
 	defer file.Close()
+	// Only the part of the log written by this build is searched for the failed step
+	logOffset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return &BuildError{Step: BuildStepPrepare, Err: err}
+	}
 
 	shellEvaluator := bringauto_ssh.ShellEvaluator{
 		Commands: buildChain.GenerateCommands(),
 		StdOut:   file,
 	}
 
+	build.Docker.PublishSSHPort()
+
 	err = bringauto_prerequisites.Initialize(build.Docker)
 	if err != nil {
-		return err
+		return &BuildError{Step: BuildStepContainer, Err: err}
 	}
 
 	dockerRun := (*bringauto_docker.DockerRun)(build.Docker)
@@ -149,34 +189,256 @@ func (build *Build) RunBuild() error {
 
 	err = dockerRun.Run()
 	if err != nil {
-		return err
+		return &BuildError{Step: BuildStepContainer, Err: err}
+	}
+	build.SSHCredentials.Port, err = build.Docker.GetSSHPort()
+	if err != nil {
+		return &BuildError{Step: BuildStepContainer, Err: err}
 	}
 
 	err = shellEvaluator.RunOverSSH(*build.SSHCredentials)
 	if err != nil {
-		return err
+		step := FindFailedStep(readBuildLog(packBuildChainLogger.GetFilePath(), logOffset))
+		if step == "" {
+			step = BuildStepContainer
+		}
+		return &BuildError{Step: step, Err: err}
+	}
+	err = build.setPackageMetadata(readBuildLog(packBuildChainLogger.GetFilePath(), logOffset))
+	if err != nil {
+		return &BuildError{Step: BuildStepClone, Err: err}
 	}
 
 	logger.InfoIndent("Copying install files from container to local directory")
 
-	err = build.downloadInstalledFiles()
-	return err
+	err = build.downloadDirectory(bringauto_const.DockerInstallDirConst, build.GetLocalInstallDirPath())
+	if err != nil {
+		return &BuildError{Step: BuildStepDownload, Err: err}
+	}
+	if build.SplitDebugSymbols {
+		err = build.downloadDirectory(dockerDebugSymbolsDirConst, build.GetLocalDebugSymbolsDirPath())
+		if err != nil {
+			return &BuildError{Step: BuildStepDownload, Err: err}
+		}
+	}
+	return nil
+}
+
+// getDebugSymbolSplit
+// Returns command generator which splits debug symbols of the installed files to the debug
+// symbols directory inside the container.
+func (build *Build) getDebugSymbolSplit() *DebugSymbolSplit {
+	indexName := build.Package.GetShortPackageName() + bringauto_package.DbgsymSuffix + ".build-ids"
+	return &DebugSymbolSplit{
+		InstallDir: bringauto_const.DockerInstallDirConst,
+		DebugDir:   dockerDebugSymbolsDirConst,
+		IndexPath:  path.Join(dockerDebugSymbolsDirConst, debugSymbolsIndexDirConst, indexName),
+	}
+}
+
+// prepareSource
+// Prepares sources of the project on the host and returns command generators which place the
+// sources to the source directory inside the container. Tarball is downloaded, verified and
+// mounted to the container, local directory is mounted to the container. Git repository is
+// cloned inside the container and hash of the checked out commit is written to the build chain
+// log.
+func (build *Build) prepareSource() ([]CMDLineInterface, error) {
+	switch {
+	case build.Tarball != nil:
+		archivePath, err := build.Tarball.Fetch(build.getSourceDownloadDirPath())
+		if err != nil {
+			return nil, err
+		}
+		build.Tarball.ArchivePath = path.Join(dockerSourceArchiveDirConst, filepath.Base(archivePath))
+		build.Tarball.ExtractPath = dockerGitCloneDirConst
+		build.Docker.SetVolume(archivePath, build.Tarball.ArchivePath)
+		return []CMDLineInterface{
+			&BuildStepMarker{Step: BuildStepSource},
+			build.Tarball,
+		}, nil
+	case build.LocalDirectory != nil:
+		localPath, err := filepath.Abs(build.LocalDirectory.Path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(localPath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot use local directory as source - %s", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("local source %s is not a directory", localPath)
+		}
+		build.LocalDirectory.MountPath = dockerSourceLocalDirConst
+		build.LocalDirectory.CopyPath = dockerGitCloneDirConst
+		build.Docker.SetVolume(localPath, dockerSourceLocalDirConst)
+		return []CMDLineInterface{
+			&BuildStepMarker{Step: BuildStepSource},
+			build.LocalDirectory,
+		}, nil
+	default:
+		build.Git.ClonePath = dockerGitCloneDirConst
+		chain := []CMDLineInterface{
+			&BuildStepMarker{Step: BuildStepClone},
+			&bringauto_git.GitClone{Git: *build.Git},
+		}
+		if build.ForbidBranchRevision {
+			if build.Git.Revision == "" {
+				return nil, fmt.Errorf("Git Revision is not specified, use a tag or a commit hash")
+			}
+			chain = append(chain, &bringauto_git.GitCheckRevision{Git: *build.Git})
+		}
+		return append(chain,
+			&bringauto_git.GitCheckout{Git: *build.Git},
+			&bringauto_git.GitSubmoduleUpdate{Git: *build.Git},
+			newGitRevParse(build.Git),
+		), nil
+	}
+}
+
+// setPackageMetadata
+// Sets Metadata of the Package according to the build chain log of the successful build. For Git
+// sources the hash of the checked out commit is read from the log.
+func (build *Build) setPackageMetadata(buildLog io.Reader) error {
+	if build.Tarball != nil || build.LocalDirectory != nil {
+		return nil
+	}
+	commit := FindGitCommit(buildLog)
+	if commit == "" {
+		return fmt.Errorf("cannot resolve commit hash of Git Revision '%s'", build.Git.Revision)
+	}
+	build.Package.Metadata = &bringauto_package.Metadata{
+		GitURI:    build.Git.URI,
+		GitCommit: commit,
+	}
+	return nil
+}
+
+// preparePatches
+// Mounts all patch files to the container and returns command generators which apply them to
+// the project sources. Returns empty list if there are no patches.
+func (build *Build) preparePatches() ([]CMDLineInterface, error) {
+	if len(build.Patches) == 0 {
+		return nil, nil
+	}
+	patchApply := PatchApply{
+		SourceDir: dockerGitCloneDirConst,
+	}
+	for i, patchPath := range build.Patches {
+		hostPath, err := filepath.Abs(patchPath)
+		if err != nil {
+			return nil, err
+		}
+		_, err = os.Stat(hostPath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot use patch - %s", err)
+		}
+		containerPath := path.Join(dockerPatchDirConst, fmt.Sprintf("%03d-%s", i+1, filepath.Base(hostPath)))
+		build.Docker.SetVolume(hostPath, containerPath)
+		patchApply.PatchPaths = append(patchApply.PatchPaths, containerPath)
+	}
+	return []CMDLineInterface{
+		&BuildStepMarker{Step: BuildStepPatch},
+		&patchApply,
+	}, nil
+}
+
+// getSourceDownloadDirPath
+// Returns path of the local directory where source tarballs are downloaded. The directory is
+// shared by all builds, so each tarball is downloaded only once.
+func (build *Build) getSourceDownloadDirPath() string {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		logger := bringauto_log.GetLogger()
+		logger.Fatal("cannot call Getwd - %s", err)
+	}
+	return filepath.Join(workingDir, localSourceDownloadDirNameConst)
+}
+
+// getBuildSystem
+// Returns the build system used for the build and all command generators needed to configure,
+// build and install the project. Each build step is preceded by its BuildStepMarker. CMake is
+// used if no other build system is specified.
+func (build *Build) getBuildSystem() (BuildSystemInterface, []CMDLineInterface) {
+	switch {
+	case build.Meson != nil:
+		return build.Meson, []CMDLineInterface{
+			&BuildStepMarker{Step: BuildStepMeson},
+			cmdLineFunc(build.Meson.ConstructSetupCMDLine),
+			&BuildStepMarker{Step: BuildStepNinja},
+			cmdLineFunc(build.Meson.ConstructBuildCMDLine),
+			&BuildStepMarker{Step: BuildStepInstall},
+			cmdLineFunc(build.Meson.ConstructInstallCMDLine),
+		}
+	case build.Autotools != nil:
+		return build.Autotools, append([]CMDLineInterface{
+			&BuildStepMarker{Step: BuildStepConfigure},
+			build.Autotools,
+		}, build.getGNUMakeSteps()...)
+	case build.CustomBuild != nil:
+		return build.CustomBuild, []CMDLineInterface{
+			&BuildStepMarker{Step: BuildStepCustom},
+			build.CustomBuild,
+		}
+	default:
+		return build.CMake, append([]CMDLineInterface{
+			&BuildStepMarker{Step: BuildStepCMake},
+			build.CMake,
+		}, build.getGNUMakeSteps()...)
+	}
+}
+
+// getGNUMakeSteps
+// Returns command generators which build and install the project by GNU Make.
+func (build *Build) getGNUMakeSteps() []CMDLineInterface {
+	return []CMDLineInterface{
+		&BuildStepMarker{Step: BuildStepMake},
+		cmdLineFunc(build.GNUMake.ConstructBuildCMDLine),
+		&BuildStepMarker{Step: BuildStepInstall},
+		cmdLineFunc(build.GNUMake.ConstructInstallCMDLine),
+	}
 }
 
 func (build *Build) SetSysroot(sysroot *bringauto_sysroot.Sysroot) {
 	build.sysroot = sysroot
 }
 
+// SetLocalInstallDirName
+// Sets name of the local directory (relative to the working directory) where the installed
+// files are copied from the container. Builds running at the same time must use different names.
+func (build *Build) SetLocalInstallDirName(dirName string) {
+	build.localInstallDirName = dirName
+}
+
 func (build *Build) GetLocalInstallDirPath() string {
 	workingDir, err := os.Getwd()
 	if err != nil {
 		logger := bringauto_log.GetLogger()
 		logger.Fatal("cannot call Getwd - %s", err)
 	}
-	copyBaseDir := filepath.Join(workingDir, localInstallDirNameConst)
+	dirName := build.localInstallDirName
+	if dirName == "" {
+		dirName = localInstallDirNameConst
+	}
+	copyBaseDir := filepath.Join(workingDir, dirName)
 	return copyBaseDir
 }
 
+// GetLocalDebugSymbolsDirPath
+// Returns path of the local directory where the split debug symbols are copied from the
+// container. The directory exists only if SplitDebugSymbols is set.
+func (build *Build) GetLocalDebugSymbolsDirPath() string {
+	return build.GetLocalInstallDirPath() + localDebugSymbolsDirSuffixConst
+}
+
+// getLocalDirPaths
+// Returns paths of all local directories where the files are copied from the container.
+func (build *Build) getLocalDirPaths() []string {
+	return []string{
+		build.GetLocalInstallDirPath(),
+		build.GetLocalDebugSymbolsDirPath(),
+	}
+}
+
 func (build *Build) stopAndRemoveContainer() error {
 	var err error
 
@@ -196,21 +458,23 @@ func (build *Build) stopAndRemoveContainer() error {
 
 func (build *Build) CleanUp() error {
 	var err error
-	copyDir := build.GetLocalInstallDirPath()
-	if _, err = os.Stat(copyDir); os.IsNotExist(err) {
-		return nil
-	}
-	err = os.RemoveAll(copyDir)
-	if err != nil {
-		return err
+	for _, copyDir := range build.getLocalDirPaths() {
+		if _, err = os.Stat(copyDir); os.IsNotExist(err) {
+			continue
+		}
+		err = os.RemoveAll(copyDir)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (build *Build) downloadInstalledFiles() error {
+// downloadDirectory
+// Downloads the remoteDir from the container to the copyDir on the host.
+func (build *Build) downloadDirectory(remoteDir string, copyDir string) error {
 	var err error
 
-	copyDir := build.GetLocalInstallDirPath()
 	if _, err = os.Stat(copyDir); os.IsNotExist(err) {
 		err = os.MkdirAll(copyDir, 0766)
 		if err != nil {
@@ -218,7 +482,7 @@ func (build *Build) downloadInstalledFiles() error {
 		}
 	}
 
-	packTarLogger := bringauto_log.GetLogger().CreateContextLogger(build.Docker.ImageName, build.Package.GetShortPackageName(), bringauto_log.TarContext)
+	packTarLogger := bringauto_log.GetLogger().CreateContextLogger(build.Docker.ImageName, build.Package.GetFullPackageName(), bringauto_log.TarContext)
 	logFile, err := packTarLogger.GetFile()
 
 	if err != nil {
@@ -228,7 +492,7 @@ func (build *Build) downloadInstalledFiles() error {
 	defer logFile.Close()
 
 	sftpClient := bringauto_ssh.SFTP{
-		RemoteDir:      bringauto_const.DockerInstallDirConst,
+		RemoteDir:      remoteDir,
 		EmptyLocalDir:  copyDir,
 		SSHCredentials: build.SSHCredentials,
 		LogWriter:      logFile,
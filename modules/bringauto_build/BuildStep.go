@@ -0,0 +1,172 @@
+package bringauto_build
+
+import (
+	"bringauto/modules/bringauto_git"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// Build preparation before the container is started
+	BuildStepPrepare = "prepare"
+	// Download of the source tarball or check of the local source directory on the host
+	BuildStepFetch = "fetch"
+	// Start of the build container
+	BuildStepContainer = "container"
+	// Startup script and environment variables
+	BuildStepSetup = "setup"
+	// Git clone, checkout and submodule update
+	BuildStepClone = "clone"
+	// Extraction of the source tarball or copy of the local source directory
+	BuildStepSource = "source"
+	// Application of the patch files
+	BuildStepPatch = "patch"
+	// Configuration of the project by CMake
+	BuildStepCMake = "cmake"
+	// Configuration of the project by 'meson setup'
+	BuildStepMeson = "meson"
+	// Configuration of the project by the Autotools configure script
+	BuildStepConfigure = "configure"
+	// Custom build script
+	BuildStepCustom = "custom"
+	// Build of the project by GNU Make
+	BuildStepMake = "make"
+	// Build of the project by Ninja
+	BuildStepNinja = "ninja"
+	// Installation of the project
+	BuildStepInstall = "install"
+	// Split of the debug symbols from the installed ELF files
+	BuildStepDbgsym = "dbgsym"
+	// Download of the installed files from the container
+	BuildStepDownload = "download"
+)
+
+const (
+	// Prefix of the lines written to the build chain log which mark the build steps
+	stepMarkerPrefixConst = "### BAP-STEP "
+	// Shell variable which holds the name of the running build step
+	stepVariableConst = "BAP_BUILD_STEP"
+)
+
+// BuildError
+// Error of the build which holds the step in which the build failed.
+type BuildError struct {
+	// Step one of the BuildStep constants
+	Step string
+	Err  error
+}
+
+func (buildError *BuildError) Error() string {
+	return fmt.Sprintf("%s step failed - %s", buildError.Step, buildError.Err)
+}
+
+func (buildError *BuildError) Unwrap() error {
+	return buildError.Err
+}
+
+// BuildStepMarker
+// Marks start of the build step in the build chain. The step name is written to the build
+// chain log, so the failed step can be found after the build.
+type BuildStepMarker struct {
+	Step string
+}
+
+func (marker *BuildStepMarker) ConstructCMDLine() []string {
+	return []string{
+		stepVariableConst + "=" + marker.Step,
+		"echo \"" + stepMarkerPrefixConst + "start " + marker.Step + "\"",
+	}
+}
+
+// buildStepTrap
+// Writes the name of the running build step to the build chain log each time a command
+// fails. Commands which are checked by the shell (e.g. a left side of '&&') do not trigger it.
+type buildStepTrap struct{}
+
+func (trap *buildStepTrap) ConstructCMDLine() []string {
+	return []string{
+		"trap 'echo \"" + stepMarkerPrefixConst + "failed $" + stepVariableConst + "\"' ERR",
+	}
+}
+
+// newGitRevParse
+// Returns command generator which writes hash of the checked out commit to the build chain log.
+func newGitRevParse(git *bringauto_git.Git) *bringauto_git.GitRevParse {
+	return &bringauto_git.GitRevParse{Git: *git, Prefix: stepMarkerPrefixConst + "commit "}
+}
+
+// cmdLineFunc
+// Adapts a function which returns commands to the CMDLineInterface.
+type cmdLineFunc func() []string
+
+func (function cmdLineFunc) ConstructCMDLine() []string {
+	return function()
+}
+
+// FindFailedStep
+// Reads build chain log written by one build and returns the step in which the first command
+// failed. If no command failure is recorded, the last started step is returned. Returns empty
+// string if there is no step marker in the log.
+func FindFailedStep(reader io.Reader) string {
+	lastStarted := ""
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		marker, found := strings.CutPrefix(line, stepMarkerPrefixConst)
+		if !found {
+			continue
+		}
+		if step, failed := strings.CutPrefix(marker, "failed "); failed {
+			if step != "" {
+				return step
+			}
+			continue
+		}
+		if step, started := strings.CutPrefix(marker, "start "); started {
+			lastStarted = step
+		}
+	}
+	return lastStarted
+}
+
+// FindGitCommit
+// Reads build chain log written by one build and returns hash of the checked out Git commit.
+// Returns empty string if the commit hash is not in the log.
+func FindGitCommit(reader io.Reader) string {
+	commit := ""
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		marker, found := strings.CutPrefix(line, stepMarkerPrefixConst+"commit")
+		if found {
+			commit = strings.TrimSpace(marker)
+		}
+	}
+	return commit
+}
+
+// readBuildLog
+// Returns the part of the log file at logPath after the given offset. Returns empty reader if
+// the log cannot be read.
+func readBuildLog(logPath string, offset int64) io.Reader {
+	file, err := os.Open(logPath)
+	if err != nil {
+		return strings.NewReader("")
+	}
+	defer file.Close()
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return strings.NewReader("")
+	}
+	mbytes, err := io.ReadAll(file)
+	if err != nil {
+		return strings.NewReader("")
+	}
+	return bytes.NewReader(mbytes)
+}
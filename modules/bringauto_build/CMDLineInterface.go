@@ -15,4 +15,20 @@ type CMDLineInterface interface {
 	// ConstructCMDLine
 	// returns list of commands which can be executed by Bash
 	ConstructCMDLine() []string
-}
\ No newline at end of file
+}
+
+// BuildSystemInterface
+// Build system (CMake, Meson, ...) which configures the project.
+type BuildSystemInterface interface {
+	CMDLineInterface
+	// SetSourceDir
+	// sets the directory inside the container where the project sources are located
+	SetSourceDir(sourceDir string)
+	// SetInstallPrefix
+	// sets the directory inside the container where the project is installed.
+	// Returns error if the install prefix is already specified by the user.
+	SetInstallPrefix(installPrefix string) error
+	// SetSysrootDir
+	// sets the directory inside the container where the already built dependencies are located
+	SetSysrootDir(sysrootDir string)
+}
@@ -58,11 +58,27 @@ func (cmake *CMake) SetDefine(key string, value string) {
 	cmake.Defines[key] = value
 }
 
+func (cmake *CMake) SetSourceDir(sourceDir string) {
+	cmake.SourceDir = sourceDir
+}
+
+func (cmake *CMake) SetInstallPrefix(installPrefix string) error {
+	_, found := cmake.Defines["CMAKE_INSTALL_PREFIX"]
+	if found {
+		return fmt.Errorf("do not specify CMAKE_INSTALL_PREFIX")
+	}
+	cmake.Defines["CMAKE_INSTALL_PREFIX"] = installPrefix
+	return nil
+}
+
+func (cmake *CMake) SetSysrootDir(sysrootDir string) {
+	cmake.SetDefine("CMAKE_PREFIX_PATH", sysrootDir)
+}
+
 func validateVariableName(varName string) bool {
 	regexp, regexpErr := regexp.CompilePOSIX("^[0-9a-zA-Z_]+$")
 	if regexpErr != nil {
 		panic(fmt.Errorf("invalid regexp for CMake variable validation"))
-		return false
 	}
 	return regexp.MatchString(varName)
 }
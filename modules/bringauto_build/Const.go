@@ -7,6 +7,24 @@ import (
 const (
 	// Where to clone a git repository on the remote machine
 	dockerGitCloneDirConst = string(filepath.Separator) + "git"
+	// Where to build the project on the remote machine if the build system needs separate build directory
+	dockerBuildDirConst = string(filepath.Separator) + "build"
+	// Where the sysroot is mounted on the remote machine
+	dockerSysrootDirConst = string(filepath.Separator) + "sysroot"
+	// Where the source tarball is mounted on the remote machine
+	dockerSourceArchiveDirConst = string(filepath.Separator) + "source_archive"
+	// Where the local source directory is mounted on the remote machine
+	dockerSourceLocalDirConst = string(filepath.Separator) + "source_local"
+	// Where the patch files are mounted on the remote machine
+	dockerPatchDirConst = string(filepath.Separator) + "patches"
+	// Where the split debug symbols are stored on the remote machine
+	dockerDebugSymbolsDirConst = string(filepath.Separator) + "DBGSYM"
+	// Where to download source tarballs on the local machine, relative to the working directory
+	localSourceDownloadDirNameConst = "sourceDownload"
 	// Where to copy file from remote machine before the package is created
 	localInstallDirNameConst = string(filepath.Separator) + "localInstall"
+	// Suffix of the local install directory name where the split debug symbols are copied
+	localDebugSymbolsDirSuffixConst = "_dbgsym"
+	// Directory relative to the debug symbols root where the build-id index is stored
+	debugSymbolsIndexDirConst = "share/bap"
 )
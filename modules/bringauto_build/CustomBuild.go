@@ -0,0 +1,75 @@
+package bringauto_build
+
+import (
+	"bringauto/modules/bringauto_prerequisites"
+	"fmt"
+	"path"
+)
+
+const (
+	// Environment variable with the install prefix available for custom build steps
+	customBuildInstallPrefixEnvConst = "INSTALL_PREFIX"
+	// Environment variable with the sysroot directory available for custom build steps
+	customBuildSysrootDirEnvConst = "SYSROOT_DIR"
+)
+
+// CustomBuild runs user defined shell commands to build and install the project.
+// The commands are run by Bash in the project directory. The install prefix is available
+// in the INSTALL_PREFIX environment variable and the sysroot directory (if any) in the
+// SYSROOT_DIR environment variable.
+type CustomBuild struct {
+	// Steps shell commands which are run one after each other
+	Steps []string
+	// Directory where the commands are run, relative to the project root. Default value is "./"
+	WorkingDir    string
+	SourceDir     string `json:"-"`
+	installPrefix string
+	sysrootDir    string
+}
+
+func (customBuild *CustomBuild) FillDefault(*bringauto_prerequisites.Args) error {
+	*customBuild = CustomBuild{
+		Steps:      []string{},
+		WorkingDir: "./",
+	}
+	return nil
+}
+
+func (customBuild *CustomBuild) FillDynamic(*bringauto_prerequisites.Args) error {
+	return nil
+}
+
+func (customBuild *CustomBuild) CheckPrerequisites(*bringauto_prerequisites.Args) error {
+	if len(customBuild.Steps) == 0 {
+		return fmt.Errorf("custom build has no steps")
+	}
+	return nil
+}
+
+func (customBuild *CustomBuild) SetSourceDir(sourceDir string) {
+	customBuild.SourceDir = sourceDir
+}
+
+func (customBuild *CustomBuild) SetInstallPrefix(installPrefix string) error {
+	customBuild.installPrefix = installPrefix
+	return nil
+}
+
+func (customBuild *CustomBuild) SetSysrootDir(sysrootDir string) {
+	customBuild.sysrootDir = sysrootDir
+}
+
+func (customBuild *CustomBuild) ConstructCMDLine() []string {
+	if customBuild.SourceDir == "" {
+		panic(fmt.Errorf("custom build source directory does not exist"))
+	}
+	commands := []string{"cd " + path.Join(customBuild.SourceDir, customBuild.WorkingDir)}
+	if customBuild.installPrefix != "" {
+		commands = append(commands, "export "+customBuildInstallPrefixEnvConst+"="+escapeValue(customBuild.installPrefix))
+	}
+	if customBuild.sysrootDir != "" {
+		commands = append(commands, "export "+customBuildSysrootDirEnvConst+"="+escapeValue(customBuild.sysrootDir))
+	}
+	commands = append(commands, customBuild.Steps...)
+	return commands
+}
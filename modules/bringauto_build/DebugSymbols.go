@@ -0,0 +1,57 @@
+package bringauto_build
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// Directory relative to the debug symbols root where the debug files are stored
+	debugFileDirConst = "lib/debug"
+)
+
+// DebugSymbolSplit moves debug symbols of all ELF files in the install directory to separate
+// debug files and strips the ELF files. Debug files are stored in the DebugDir in the build-id
+// layout (lib/debug/.build-id/xx/yyyy.debug) used by debuggers, ELF files without build-id use
+// the path layout (lib/debug/<path>.debug). Each stripped file gets a .gnu_debuglink section.
+// The index of all debug files (build-id and path of the stripped file) is written to IndexPath.
+// The objcopy, strip, readelf and od tools must be present in the container.
+type DebugSymbolSplit struct {
+	// InstallDir directory inside the container where the project is installed
+	InstallDir string
+	// DebugDir directory inside the container where the debug files are stored
+	DebugDir string
+	// IndexPath path of the build-id index inside the container, must be in the DebugDir
+	IndexPath string
+}
+
+func (split *DebugSymbolSplit) ConstructCMDLine() []string {
+	if split.InstallDir == "" || split.DebugDir == "" || split.IndexPath == "" {
+		panic(fmt.Errorf("debug symbols directories are not set"))
+	}
+	installDir := strings.TrimSuffix(split.InstallDir, "/")
+	debugFileDir := split.DebugDir + "/" + debugFileDirConst
+	// Only ELF files with debug information are split
+	loop := []string{
+		`[ "$(od -An -tx1 -N4 "$file" | tr -d ' \n')" = "7f454c46" ] || continue`,
+		`readelf -S "$file" 2> /dev/null | grep -q '\.debug_info' || continue`,
+		`relPath="${file#` + installDir + `/}"`,
+		`fileMode="$(stat -c %a "$file")"`,
+		`buildId="$(readelf -n "$file" 2> /dev/null | sed -n 's/^.*Build ID: \([0-9a-f]*\).*$/\1/p' | head -n 1)"`,
+		`if [ -n "$buildId" ]; then debugFile="` + debugFileDir + `/.build-id/${buildId:0:2}/${buildId:2}.debug"; ` +
+			`else debugFile="` + debugFileDir + `/$relPath.debug"; fi`,
+		`mkdir -p "$(dirname "$debugFile")" && chmod u+w "$file"` +
+			` && objcopy --only-keep-debug "$file" "$debugFile" && chmod 644 "$debugFile"` +
+			` && strip --strip-debug --strip-unneeded "$file"` +
+			` && objcopy --add-gnu-debuglink="$debugFile" "$file"` +
+			` && chmod "$fileMode" "$file" || exit 1`,
+		`echo "${buildId:--} $relPath" >> ` + escapeVariableValue(split.IndexPath) + ` || exit 1`,
+	}
+	return []string{
+		"mkdir -p " + escapeVariableValue(split.DebugDir) + " \"$(dirname " + escapeVariableValue(split.IndexPath) + ")\"",
+		": > " + escapeVariableValue(split.IndexPath),
+		"find " + escapeVariableValue(installDir) + " -type f -print0 | sort -z | " +
+			"while IFS= read -r -d '' file; do " + strings.Join(loop, "; ") + "; done" +
+			" || { echo \"Debug symbols cannot be split\"; exit 1; }",
+	}
+}
@@ -32,10 +32,19 @@ func (make *GNUMake) CheckPrerequisites(*bringauto_prerequisites.Args) error {
 }
 
 func (make *GNUMake) ConstructCMDLine() []string {
+	return append(make.ConstructBuildCMDLine(), make.ConstructInstallCMDLine()...)
+}
+
+// ConstructBuildCMDLine
+// returns commands which build the project
+func (make *GNUMake) ConstructBuildCMDLine() []string {
 	cmdBuild := []string{"make", "-j", strconv.Itoa(make.jobsCount)}
+	return []string{strings.Join(cmdBuild, " ")}
+}
+
+// ConstructInstallCMDLine
+// returns commands which install the built project
+func (make *GNUMake) ConstructInstallCMDLine() []string {
 	cmdInstall := []string{"make", "install"}
-	return []string{
-		strings.Join(cmdBuild, " "),
-		strings.Join(cmdInstall, " "),
-	}
+	return []string{strings.Join(cmdInstall, " ")}
 }
@@ -0,0 +1,120 @@
+package bringauto_build
+
+import (
+	"bringauto/modules/bringauto_prerequisites"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// number of jobs passed to '-j' of Ninja
+	mesonJobsCountConst = 10
+)
+
+// Meson cmd line interface for the Meson build system. The project is configured by 'meson setup'
+// and then built and installed by Ninja.
+type Meson struct {
+	// Options passed to 'meson setup' by the -D switch
+	Options map[string]string
+	// Directory where the top level meson.build is located, relative to the project root.
+	// Default value is "./"
+	MesonBuildDir string
+	SourceDir     string `json:"-"`
+	installPrefix string
+	sysrootDir    string
+}
+
+func (meson *Meson) FillDefault(*bringauto_prerequisites.Args) error {
+	*meson = Meson{
+		Options:       map[string]string{},
+		MesonBuildDir: "./",
+	}
+	return nil
+}
+
+func (meson *Meson) FillDynamic(*bringauto_prerequisites.Args) error {
+	if meson.Options == nil {
+		meson.Options = map[string]string{}
+	}
+	return nil
+}
+
+func (meson *Meson) CheckPrerequisites(*bringauto_prerequisites.Args) error {
+	for key := range meson.Options {
+		if !validateVariableName(key) {
+			return fmt.Errorf("invalid Meson option: %s", key)
+		}
+	}
+	return nil
+}
+
+func (meson *Meson) SetSourceDir(sourceDir string) {
+	meson.SourceDir = sourceDir
+}
+
+func (meson *Meson) SetInstallPrefix(installPrefix string) error {
+	_, found := meson.Options["prefix"]
+	if found {
+		return fmt.Errorf("do not specify Meson prefix option")
+	}
+	meson.installPrefix = installPrefix
+	return nil
+}
+
+func (meson *Meson) SetSysrootDir(sysrootDir string) {
+	meson.sysrootDir = sysrootDir
+}
+
+func (meson *Meson) ConstructCMDLine() []string {
+	commands := meson.ConstructSetupCMDLine()
+	commands = append(commands, meson.ConstructBuildCMDLine()...)
+	return append(commands, meson.ConstructInstallCMDLine()...)
+}
+
+// ConstructSetupCMDLine
+// returns commands which configure the project by 'meson setup'
+func (meson *Meson) ConstructSetupCMDLine() []string {
+	if meson.SourceDir == "" {
+		panic(fmt.Errorf("meson source directory does not exist"))
+	}
+	cmdSetup := []string{"meson", "setup"}
+	if meson.installPrefix != "" {
+		cmdSetup = append(cmdSetup, "--prefix="+escapeVariableValue(meson.installPrefix))
+	}
+	if meson.sysrootDir != "" {
+		cmdSetup = append(cmdSetup,
+			"-Dcmake_prefix_path="+escapeVariableValue(meson.sysrootDir),
+			"-Dpkg_config_path="+escapeVariableValue(sysrootPkgConfigPath(meson.sysrootDir)),
+		)
+	}
+	for key, value := range meson.Options {
+		if !validateVariableName(key) {
+			panic(fmt.Errorf("invalid Meson option: %s", key))
+		}
+		cmdSetup = append(cmdSetup, "-D"+key+"="+escapeVariableValue(value))
+	}
+	cmdSetup = append(cmdSetup, dockerBuildDirConst, path.Join(meson.SourceDir, meson.MesonBuildDir))
+	return []string{strings.Join(cmdSetup, " ")}
+}
+
+// ConstructBuildCMDLine
+// returns commands which build the configured project by Ninja
+func (meson *Meson) ConstructBuildCMDLine() []string {
+	cmdBuild := []string{"ninja", "-C", dockerBuildDirConst, "-j", strconv.Itoa(mesonJobsCountConst)}
+	return []string{strings.Join(cmdBuild, " ")}
+}
+
+// ConstructInstallCMDLine
+// returns commands which install the built project by Ninja
+func (meson *Meson) ConstructInstallCMDLine() []string {
+	cmdInstall := []string{"ninja", "-C", dockerBuildDirConst, "install"}
+	return []string{strings.Join(cmdInstall, " ")}
+}
+
+// sysrootPkgConfigPath
+// Returns PKG_CONFIG_PATH value with all pkg-config directories of the sysroot.
+func sysrootPkgConfigPath(sysrootDir string) string {
+	return path.Join(sysrootDir, "lib", "pkgconfig") + ":" + path.Join(sysrootDir, "share", "pkgconfig")
+}
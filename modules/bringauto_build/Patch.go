@@ -0,0 +1,36 @@
+package bringauto_build
+
+import (
+	"fmt"
+	"path"
+)
+
+// PatchApply applies patch files to the project sources in the given order. The patch is applied
+// by 'git apply' if the sources are a Git repository, otherwise by 'patch -p1'. The build is
+// stopped if any patch does not apply.
+type PatchApply struct {
+	// PatchPaths paths of the patch files inside the container
+	PatchPaths []string
+	// SourceDir directory inside the container where the project sources are located
+	SourceDir string
+}
+
+func (patchApply *PatchApply) ConstructCMDLine() []string {
+	if patchApply.SourceDir == "" {
+		panic(fmt.Errorf("patch source directory is empty"))
+	}
+	commands := []string{"pushd " + escapeVariableValue(patchApply.SourceDir)}
+	for _, patchPath := range patchApply.PatchPaths {
+		patchFile := escapeVariableValue(patchPath)
+		errorMessage := escapeVariableValue("Patch " + path.Base(patchPath) + " does not apply")
+		commands = append(commands,
+			"echo "+escapeVariableValue("Applying patch "+path.Base(patchPath)),
+			"if git rev-parse --is-inside-work-tree > /dev/null 2>&1; "+
+				"then git apply --verbose "+patchFile+"; "+
+				"else patch -p1 --forward --batch -i "+patchFile+"; fi"+
+				" || { echo "+errorMessage+"; exit 1; }",
+		)
+	}
+	commands = append(commands, "popd")
+	return commands
+}
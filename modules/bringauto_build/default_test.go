@@ -0,0 +1,189 @@
+package bringauto_build_test
+
+import (
+	"bringauto/modules/bringauto_build"
+	"bringauto/modules/bringauto_prerequisites"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMeson_ConstructCMDLine(t *testing.T) {
+	meson := bringauto_prerequisites.CreateAndInitialize[bringauto_build.Meson]()
+	meson.Options["default_library"] = "shared"
+	meson.SetSourceDir("/git")
+	err := meson.SetInstallPrefix("/INSTALL")
+	if err != nil {
+		t.Fatalf("SetInstallPrefix failed - %s", err)
+	}
+	meson.SetSysrootDir("/sysroot")
+	cmdLine := meson.ConstructCMDLine()
+	validCmdLine := []string{
+		"meson setup --prefix=\"/INSTALL\" -Dcmake_prefix_path=\"/sysroot\" " +
+			"-Dpkg_config_path=\"/sysroot/lib/pkgconfig:/sysroot/share/pkgconfig\" " +
+			"-Ddefault_library=\"shared\" /build /git",
+		"ninja -C /build -j 10",
+		"ninja -C /build install",
+	}
+	if !reflect.DeepEqual(cmdLine, validCmdLine) {
+		t.Errorf("meson CMD line is not valid! %s", cmdLine)
+	}
+}
+
+func TestMeson_SetInstallPrefixSpecified(t *testing.T) {
+	meson := bringauto_prerequisites.CreateAndInitialize[bringauto_build.Meson]()
+	meson.Options["prefix"] = "/usr"
+	err := meson.SetInstallPrefix("/INSTALL")
+	if err == nil {
+		t.Error("user specified prefix not detected")
+	}
+}
+
+func TestAutotools_ConstructCMDLine(t *testing.T) {
+	autotools := bringauto_prerequisites.CreateAndInitialize[bringauto_build.Autotools]()
+	autotools.Options = []string{"--enable-shared"}
+	autotools.AutoReconf = true
+	autotools.SetSourceDir("/git")
+	err := autotools.SetInstallPrefix("/INSTALL")
+	if err != nil {
+		t.Fatalf("SetInstallPrefix failed - %s", err)
+	}
+	cmdLine := autotools.ConstructCMDLine()
+	validCmdLine := []string{
+		"cd /git",
+		"autoreconf -fi",
+		"./configure --prefix=\"/INSTALL\" --enable-shared",
+	}
+	if !reflect.DeepEqual(cmdLine, validCmdLine) {
+		t.Errorf("autotools CMD line is not valid! %s", cmdLine)
+	}
+}
+
+func TestAutotools_SetInstallPrefixSpecified(t *testing.T) {
+	autotools := bringauto_prerequisites.CreateAndInitialize[bringauto_build.Autotools]()
+	autotools.Options = []string{"--prefix=/usr"}
+	err := autotools.SetInstallPrefix("/INSTALL")
+	if err == nil {
+		t.Error("user specified prefix not detected")
+	}
+}
+
+func TestCustomBuild_ConstructCMDLine(t *testing.T) {
+	customBuild := bringauto_build.CustomBuild{
+		Steps: []string{"./Configure --prefix=$INSTALL_PREFIX", "make", "make install"},
+	}
+	err := bringauto_prerequisites.Initialize(&customBuild)
+	if err != nil {
+		t.Fatalf("Initialize failed - %s", err)
+	}
+	customBuild.SetSourceDir("/git")
+	_ = customBuild.SetInstallPrefix("/INSTALL")
+	customBuild.SetSysrootDir("/sysroot")
+	cmdLine := customBuild.ConstructCMDLine()
+	validCmdLine := []string{
+		"cd /git",
+		"export INSTALL_PREFIX=\"/INSTALL\"",
+		"export SYSROOT_DIR=\"/sysroot\"",
+		"./Configure --prefix=$INSTALL_PREFIX",
+		"make",
+		"make install",
+	}
+	if !reflect.DeepEqual(cmdLine, validCmdLine) {
+		t.Errorf("custom build CMD line is not valid! %s", cmdLine)
+	}
+}
+
+func TestCustomBuild_NoSteps(t *testing.T) {
+	customBuild := bringauto_build.CustomBuild{
+		WorkingDir: "./",
+	}
+	err := bringauto_prerequisites.Initialize(&customBuild)
+	if err == nil {
+		t.Error("custom build without steps not detected")
+	}
+}
+
+func TestFindFailedStep(t *testing.T) {
+	log := "### BAP-STEP start setup\n" +
+		"### BAP-STEP start clone\n" +
+		"Cloning into '/git'...\n" +
+		"### BAP-STEP start cmake\n" +
+		"### BAP-STEP start make\n" +
+		"error: no such file\n" +
+		"### BAP-STEP failed make\n" +
+		"### BAP-STEP start install\n" +
+		"### BAP-STEP failed install\n"
+	step := bringauto_build.FindFailedStep(strings.NewReader(log))
+	if step != bringauto_build.BuildStepMake {
+		t.Errorf("invalid failed step: %s", step)
+	}
+}
+
+func TestFindFailedStepNoFailure(t *testing.T) {
+	log := "### BAP-STEP start setup\n" +
+		"### BAP-STEP start clone\n" +
+		"fatal: repository not found\n"
+	step := bringauto_build.FindFailedStep(strings.NewReader(log))
+	if step != bringauto_build.BuildStepClone {
+		t.Errorf("invalid failed step: %s", step)
+	}
+}
+
+func TestPatchApply_ConstructCMDLine(t *testing.T) {
+	patchApply := bringauto_build.PatchApply{
+		PatchPaths: []string{"/patches/001-fix.patch"},
+		SourceDir:  "/git",
+	}
+	validCmdLine := []string{
+		"pushd \"/git\"",
+		"echo \"Applying patch 001-fix.patch\"",
+		"if git rev-parse --is-inside-work-tree > /dev/null 2>&1; " +
+			"then git apply --verbose \"/patches/001-fix.patch\"; " +
+			"else patch -p1 --forward --batch -i \"/patches/001-fix.patch\"; fi" +
+			" || { echo \"Patch 001-fix.patch does not apply\"; exit 1; }",
+		"popd",
+	}
+	cmdLine := patchApply.ConstructCMDLine()
+	if !reflect.DeepEqual(cmdLine, validCmdLine) {
+		t.Errorf("patch CMD line is not valid! %s", cmdLine)
+	}
+}
+
+func TestDebugSymbolSplit_ConstructCMDLine(t *testing.T) {
+	split := bringauto_build.DebugSymbolSplit{
+		InstallDir: "/INSTALL",
+		DebugDir:   "/DBGSYM",
+		IndexPath:  "/DBGSYM/share/bap/pack-dbgsym.build-ids",
+	}
+	cmdLine := split.ConstructCMDLine()
+	if len(cmdLine) != 3 {
+		t.Fatalf("debug symbols CMD line is not valid! %s", cmdLine)
+	}
+	if cmdLine[1] != ": > \"/DBGSYM/share/bap/pack-dbgsym.build-ids\"" {
+		t.Errorf("index is not truncated! %s", cmdLine[1])
+	}
+	for _, part := range []string{
+		"find \"/INSTALL\" -type f",
+		"relPath=\"${file#/INSTALL/}\"",
+		"debugFile=\"/DBGSYM/lib/debug/.build-id/${buildId:0:2}/${buildId:2}.debug\"",
+		"objcopy --only-keep-debug \"$file\" \"$debugFile\"",
+		"strip --strip-debug --strip-unneeded \"$file\"",
+		"objcopy --add-gnu-debuglink=\"$debugFile\" \"$file\"",
+		" >> \"/DBGSYM/share/bap/pack-dbgsym.build-ids\"",
+	} {
+		if !strings.Contains(cmdLine[2], part) {
+			t.Errorf("debug symbols CMD line does not contain %s", part)
+		}
+	}
+}
+
+func TestFindGitCommit(t *testing.T) {
+	log := "### BAP-STEP start clone\n" +
+		"Cloning into '/git'...\n" +
+		"### BAP-STEP commit 0123456789abcdef0123456789abcdef01234567\n" +
+		"### BAP-STEP start cmake\n"
+	commit := bringauto_build.FindGitCommit(strings.NewReader(log))
+	if commit != "0123456789abcdef0123456789abcdef01234567" {
+		t.Errorf("invalid commit: %s", commit)
+	}
+}
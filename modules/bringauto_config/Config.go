@@ -6,9 +6,15 @@ import (
 	"bringauto/modules/bringauto_git"
 	"bringauto/modules/bringauto_package"
 	"bringauto/modules/bringauto_prerequisites"
+	"bringauto/modules/bringauto_source"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/jinzhu/copier"
 )
@@ -16,9 +22,78 @@ import (
 // Build
 // It stores configuration for given build system
 // (CMake, autoconf, ...)
+// At most one build system can be specified. If none is specified, CMake with default
+// settings is used.
 //
 type Build struct {
-	CMake *bringauto_build.CMake
+	CMake       *bringauto_build.CMake       `json:",omitempty"`
+	Meson       *bringauto_build.Meson       `json:",omitempty"`
+	Autotools   *bringauto_build.Autotools   `json:",omitempty"`
+	CustomBuild *bringauto_build.CustomBuild `json:",omitempty"`
+}
+
+// checkBuildSystems
+// Returns error if more than one build system is specified.
+func (build *Build) checkBuildSystems() error {
+	var buildSystems []string
+	if build.CMake != nil {
+		buildSystems = append(buildSystems, "CMake")
+	}
+	if build.Meson != nil {
+		buildSystems = append(buildSystems, "Meson")
+	}
+	if build.Autotools != nil {
+		buildSystems = append(buildSystems, "Autotools")
+	}
+	if build.CustomBuild != nil {
+		buildSystems = append(buildSystems, "CustomBuild")
+	}
+	if len(buildSystems) > 1 {
+		return fmt.Errorf("only one build system can be specified, got %s", strings.Join(buildSystems, ", "))
+	}
+	return nil
+}
+
+// Source
+// It stores where the project sources are taken from.
+// At most one source can be specified. If none is specified, the top level Git section of the
+// Config is used.
+//
+type Source struct {
+	Git            *bringauto_git.Git               `json:",omitempty"`
+	Tarball        *bringauto_source.Tarball        `json:",omitempty"`
+	LocalDirectory *bringauto_source.LocalDirectory `json:",omitempty"`
+}
+
+// checkSources
+// Returns error if more than one source is specified or if the specified source is not valid.
+func (source *Source) checkSources() error {
+	var sources []string
+	if source.Git != nil {
+		sources = append(sources, "Git")
+	}
+	if source.Tarball != nil {
+		sources = append(sources, "Tarball")
+	}
+	if source.LocalDirectory != nil {
+		sources = append(sources, "LocalDirectory")
+	}
+	if len(sources) > 1 {
+		return fmt.Errorf("only one source can be specified, got %s", strings.Join(sources, ", "))
+	}
+	if source.Tarball != nil {
+		return source.Tarball.CheckPrerequisites(nil)
+	}
+	if source.LocalDirectory != nil {
+		return source.LocalDirectory.CheckPrerequisites(nil)
+	}
+	return nil
+}
+
+// isEmpty
+// Returns true if no source is specified.
+func (source *Source) isEmpty() bool {
+	return source.Git == nil && source.Tarball == nil && source.LocalDirectory == nil
 }
 
 type DockerMatrix struct {
@@ -29,21 +104,43 @@ type DockerMatrix struct {
 // Build configuration which stores how the package is build.
 //
 type Config struct {
-	Env          map[string]string
-	Git          bringauto_git.Git
-	Build        Build
-	Package      bringauto_package.Package
-	DockerMatrix DockerMatrix
-	DependsOn    []string
+	// Extends path of the base Package JSON definition which this definition extends, relative
+	// to the directory of this definition. Objects are merged recursively, other values given in
+	// this definition replace the values of the base
+	Extends          string   `json:",omitempty"`
+	// BuildTypes build types ("Debug", "Release") the definition is expanded to, one Config
+	// for each build type is loaded by LoadJSONConfigs
+	BuildTypes       []string `json:",omitempty"`
+	Env              map[string]string
+	Git              bringauto_git.Git
+	Source           Source
+	// Patches patch files applied to the sources before the build, in the given order. Relative
+	// paths are relative to the directory of the Package JSON definition
+	Patches          []string
+	Build            Build
+	Package          bringauto_package.Package
+	DockerMatrix     DockerMatrix
+	// DependsOn Packages the Package depends on at build time and at runtime, each entry is
+	// a Package name optionally followed by the version constraint (e.g. "zlib >=1.2.11 <1.3")
+	DependsOn        []string
+	// BuildDependsOn Packages needed only for the build (e.g. code generators), entries are in
+	// the same format as in DependsOn
+	BuildDependsOn   []string
+	// RuntimeDependsOn Packages needed only at runtime, entries are in the same format as in
+	// DependsOn. They are built before the Package and are in its build sysroot too
+	RuntimeDependsOn []string
 }
 
 func (config *Config) FillDefault(*bringauto_prerequisites.Args) error {
 	*config = Config{
-		Env:       map[string]string{},
-		Git:       bringauto_git.Git{},
-		Build:     Build{},
-		Package:   bringauto_package.Package{},
-		DependsOn: []string{},
+		Env:              map[string]string{},
+		Git:              bringauto_git.Git{},
+		Build:            Build{},
+		Package:          bringauto_package.Package{},
+		DependsOn:        []string{},
+		BuildDependsOn:   []string{},
+		RuntimeDependsOn: []string{},
+		Patches:          []string{},
 	}
 	return nil
 }
@@ -56,18 +153,69 @@ func (config *Config) CheckPrerequisites(*bringauto_prerequisites.Args) error {
 	return nil
 }
 
+// LoadJSONConfig
+// Loads Config from the JSON file. Fields which are not part of the Config are not allowed.
+// If the file extends a base definition, the base is merged in first. BuildTypes are not
+// expanded, use LoadJSONConfigs to get the Config for each build type.
 func (config *Config) LoadJSONConfig(configPath string) error {
-	mbytes, err := os.ReadFile(configPath)
+	mbytes, err := loadExtendedJSON(configPath, nil)
+	if err != nil {
+		return fmt.Errorf("invalid package config %s - %s", configPath, err)
+	}
+	err = unmarshalStrict(mbytes, config)
+	if err != nil {
+		return fmt.Errorf("invalid package config %s - %s", configPath, err)
+	}
+	err = config.Build.checkBuildSystems()
 	if err != nil {
 		return err
 	}
-	err = json.Unmarshal(mbytes, config)
+	err = config.Source.checkSources()
 	if err != nil {
 		return err
 	}
+	err = config.checkDependencies()
+	if err != nil {
+		return err
+	}
+	err = config.checkBuildTypes()
+	if err != nil {
+		return err
+	}
+	if !config.Source.isEmpty() && config.Git != (bringauto_git.Git{}) {
+		return fmt.Errorf("Git and Source cannot be specified together")
+	}
+	local := config.Source.LocalDirectory
+	if local != nil && !filepath.IsAbs(local.Path) {
+		local.Path = filepath.Join(filepath.Dir(configPath), local.Path)
+	}
+	for i, patchPath := range config.Patches {
+		if !filepath.IsAbs(patchPath) {
+			config.Patches[i] = filepath.Join(filepath.Dir(configPath), patchPath)
+		}
+		if slices.Contains(config.Patches[:i], config.Patches[i]) {
+			return fmt.Errorf("patch %s is specified more than once", patchPath)
+		}
+	}
 	return nil
 }
 
+// GetSourceRevision
+// Returns revision of the project sources - Git revision, SHA-256 checksum of the tarball or
+// path of the local directory.
+func (config *Config) GetSourceRevision() string {
+	switch {
+	case config.Source.Tarball != nil:
+		return config.Source.Tarball.SHA256
+	case config.Source.LocalDirectory != nil:
+		return config.Source.LocalDirectory.Path
+	case config.Source.Git != nil:
+		return config.Source.Git.Revision
+	default:
+		return config.Git.Revision
+	}
+}
+
 func (config *Config) SaveToJSONConfig(configPath string) error {
 	mbytes, err := json.Marshal(config)
 	if err != nil {
@@ -80,6 +228,100 @@ func (config *Config) SaveToJSONConfig(configPath string) error {
 	return nil
 }
 
+// BuildOptions
+// Options of the build which change the built Package archive, they are part of the cache key.
+type BuildOptions struct {
+	// Reproducible true if the Package archive is created reproducibly
+	Reproducible      bool
+	// SourceDateEpoch timestamp of the reproducible Package archive (SOURCE_DATE_EPOCH)
+	SourceDateEpoch   int64
+	// SplitDebugSymbols true if debug symbols are split from the Package to the debug symbols archive
+	SplitDebugSymbols bool
+}
+
+// cacheKeyInput
+// All inputs which determine the build result of the Package. Serialized to JSON and hashed
+// to get the cache key.
+type cacheKeyInput struct {
+	Config              Config
+	// SourceHash hash of the local source directory content, empty for other sources
+	SourceHash          string
+	// PatchHashes hashes of the patch files content
+	PatchHashes         []string
+	ImageId             string
+	PlatformString      string
+	DependencyKeys      []string
+	// RuntimeDependencies names of runtime dependencies, they are dependencies of the native package
+	RuntimeDependencies []string
+	// Build options are omitted if not used, so keys of other builds do not change
+	Reproducible        bool  `json:",omitempty"`
+	SourceDateEpoch     int64 `json:",omitempty"`
+	SplitDebugSymbols   bool  `json:",omitempty"`
+}
+
+// GetCacheKey
+// Returns key which identifies the build result of the Config. The key is a SHA-256 hash of
+// the Config (without DockerMatrix, so adding a new image does not change the key), content of
+// the local source directory (if used) and of the patch files, the ID of the docker image, the
+// platform string, build options and cache keys of all Packages the Config depends on (together
+// with names of the runtime dependencies). The key changes if any of these inputs changes. Paths
+// of the patch files and of the local source directory are not part of the key, so the key does
+// not depend on the context location.
+func (config *Config) GetCacheKey(
+	imageId        string,
+	platformString *bringauto_package.PlatformString,
+	dependencyKeys []string,
+	options        BuildOptions,
+) (string, error) {
+	input := cacheKeyInput{
+		Config:            *config,
+		ImageId:           imageId,
+		DependencyKeys:    slices.Clone(dependencyKeys),
+		Reproducible:      options.Reproducible,
+		SourceDateEpoch:   options.SourceDateEpoch,
+		SplitDebugSymbols: options.SplitDebugSymbols,
+	}
+	input.Config.DockerMatrix = DockerMatrix{}
+	input.Config.Package.PlatformString = bringauto_package.PlatformString{}
+	input.Config.DependsOn = nil
+	input.Config.BuildDependsOn = nil
+	input.Config.RuntimeDependsOn = nil
+	input.Config.Patches = nil
+	if config.Source.LocalDirectory != nil {
+		input.Config.Source.LocalDirectory = &bringauto_source.LocalDirectory{}
+	}
+	for _, dependency := range config.GetRuntimeDependencies() {
+		input.RuntimeDependencies = append(input.RuntimeDependencies, dependency.Name)
+	}
+	slices.Sort(input.RuntimeDependencies)
+	if platformString != nil {
+		input.PlatformString = platformString.Serialize()
+	}
+	if config.Source.LocalDirectory != nil {
+		sourceHash, err := config.Source.LocalDirectory.GetContentHash()
+		if err != nil {
+			return "", err
+		}
+		input.SourceHash = sourceHash
+	}
+	for _, patchPath := range config.Patches {
+		mbytes, err := os.ReadFile(patchPath)
+		if err != nil {
+			return "", fmt.Errorf("cannot read patch - %s", err)
+		}
+		patchHash := sha256.Sum256(mbytes)
+		input.PatchHashes = append(input.PatchHashes, hex.EncodeToString(patchHash[:]))
+	}
+	slices.Sort(input.DependencyKeys)
+
+	mbytes, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("cannot serialize cache key input - %s", err)
+	}
+	hash := sha256.Sum256(mbytes)
+	return hex.EncodeToString(hash[:]), nil
+}
+
 // Returns array of builds structs for specific image name. The returned array will contain max one build.
 // It is an array for simple handling of result using for loop.
 func (config *Config) GetBuildStructure(imageName string, platformString *bringauto_package.PlatformString) []bringauto_build.Build {
@@ -113,11 +355,15 @@ func (config *Config) fillBuildStructure(dockerImageName string, platformString
 	if err != nil {
 		panic(err)
 	}
-	err = bringauto_prerequisites.Initialize(&config.Git)
+	git := &config.Git
+	if config.Source.Git != nil {
+		git = config.Source.Git
+	}
+	err = bringauto_prerequisites.Initialize(git)
 	if err != nil {
 		panic(err)
 	}
-	err = bringauto_prerequisites.Initialize(config.Build.CMake)
+	err = initializeBuildSystems(&config.Build)
 	if err != nil {
 		panic(err)
 	}
@@ -133,11 +379,48 @@ func (config *Config) fillBuildStructure(dockerImageName string, platformString
 
 	build := bringauto_build.Build{
 		Env:     env,
-		Git:     &config.Git,
-		CMake:   config.Build.CMake,
-		Package: &tmpPackage,
-		Docker:  defaultDocker,
+		Git:     git,
+		Tarball:        config.Source.Tarball,
+		LocalDirectory: config.Source.LocalDirectory,
+		Patches:        config.Patches,
+		CMake:       config.Build.CMake,
+		Meson:       config.Build.Meson,
+		Autotools:   config.Build.Autotools,
+		CustomBuild: config.Build.CustomBuild,
+		Package:     &tmpPackage,
+		Docker:      defaultDocker,
 	}
 
 	return build
 }
+
+// initializeBuildSystems
+// Initializes all build systems specified in build.
+func initializeBuildSystems(build *Build) error {
+	var err error
+	if build.CMake != nil {
+		err = bringauto_prerequisites.Initialize(build.CMake)
+		if err != nil {
+			return err
+		}
+	}
+	if build.Meson != nil {
+		err = bringauto_prerequisites.Initialize(build.Meson)
+		if err != nil {
+			return err
+		}
+	}
+	if build.Autotools != nil {
+		err = bringauto_prerequisites.Initialize(build.Autotools)
+		if err != nil {
+			return err
+		}
+	}
+	if build.CustomBuild != nil {
+		err = bringauto_prerequisites.Initialize(build.CustomBuild)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
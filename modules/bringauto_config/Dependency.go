@@ -0,0 +1,124 @@
+package bringauto_config
+
+import (
+	"bringauto/modules/bringauto_package"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Dependency
+// Entry of DependsOn - name of the Package optionally followed by the version constraint
+// (e.g. "zlib >=1.2.11 <1.3").
+type Dependency struct {
+	Name string
+	// Constraint allowed versions of the Package, any version is allowed if nil
+	Constraint *bringauto_package.VersionConstraint
+}
+
+// ParseDependency
+// Parses the DependsOn entry.
+func ParseDependency(entry string) (Dependency, error) {
+	fields := strings.Fields(entry)
+	if len(fields) == 0 {
+		return Dependency{}, fmt.Errorf("empty dependency")
+	}
+	dependency := Dependency{
+		Name: fields[0],
+	}
+	if len(fields) > 1 {
+		constraint, err := bringauto_package.ParseVersionConstraint(strings.Join(fields[1:], " "))
+		if err != nil {
+			return Dependency{}, fmt.Errorf("invalid dependency '%s' - %s", entry, err)
+		}
+		dependency.Constraint = constraint
+	}
+	return dependency, nil
+}
+
+// IsSatisfiedBy
+// Returns true if the versionTag is allowed by the Constraint, else returns false. Invalid
+// versionTag satisfies only dependency without Constraint.
+func (dependency *Dependency) IsSatisfiedBy(versionTag string) bool {
+	if dependency.Constraint == nil {
+		return true
+	}
+	satisfied, err := dependency.Constraint.CheckVersionTag(versionTag)
+	return err == nil && satisfied
+}
+
+func (dependency Dependency) String() string {
+	if dependency.Constraint == nil {
+		return dependency.Name
+	}
+	return dependency.Name + " " + dependency.Constraint.String()
+}
+
+// GetDependencies
+// Returns parsed entries of DependsOn, BuildDependsOn and RuntimeDependsOn - all Packages which
+// must be built before the Package and are in its build sysroot. All entries are validated by
+// LoadJSONConfig, invalid entries of Configs created other way are returned without the constraint
+// and empty entries are skipped.
+func (config *Config) GetDependencies() []Dependency {
+	return parseDependencies(slices.Concat(config.DependsOn, config.BuildDependsOn, config.RuntimeDependsOn))
+}
+
+// GetBuildDependencies
+// Returns parsed entries of DependsOn and BuildDependsOn - Packages used by the build, so the
+// Package must be rebuilt if they change.
+func (config *Config) GetBuildDependencies() []Dependency {
+	return parseDependencies(slices.Concat(config.DependsOn, config.BuildDependsOn))
+}
+
+// GetRuntimeDependencies
+// Returns parsed entries of DependsOn and RuntimeDependsOn - Packages needed at runtime.
+func (config *Config) GetRuntimeDependencies() []Dependency {
+	return parseDependencies(slices.Concat(config.DependsOn, config.RuntimeDependsOn))
+}
+
+// parseDependencies
+// Parses the entries, invalid entries are returned without the constraint and empty entries
+// are skipped.
+func parseDependencies(entries []string) []Dependency {
+	var dependencies []Dependency
+	for _, entry := range entries {
+		dependency, err := ParseDependency(entry)
+		if err != nil {
+			fields := strings.Fields(entry)
+			if len(fields) == 0 {
+				continue
+			}
+			dependency = Dependency{Name: fields[0]}
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies
+}
+
+// GetDependsOnNames
+// Returns names of Packages from GetDependencies without the version constraints.
+func (config *Config) GetDependsOnNames() []string {
+	names := []string{}
+	for _, dependency := range config.GetDependencies() {
+		names = append(names, dependency.Name)
+	}
+	return names
+}
+
+// checkDependencies
+// Returns error if any entry of DependsOn, BuildDependsOn or RuntimeDependsOn is not valid or
+// a Package is specified more than once.
+func (config *Config) checkDependencies() error {
+	var names []string
+	for _, entry := range slices.Concat(config.DependsOn, config.BuildDependsOn, config.RuntimeDependsOn) {
+		dependency, err := ParseDependency(entry)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(names, dependency.Name) {
+			return fmt.Errorf("dependency %s is specified more than once", dependency.Name)
+		}
+		names = append(names, dependency.Name)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package bringauto_config
+
+import (
+	"bringauto/modules/bringauto_build"
+	"bringauto/modules/bringauto_prerequisites"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// BuildTypeDebug build type of the debug Package
+	BuildTypeDebug = "Debug"
+	// BuildTypeRelease build type of the release Package
+	BuildTypeRelease = "Release"
+)
+
+// LoadJSONConfigs
+// Loads all Configs defined by the JSON file. If the definition has BuildTypes, one Config
+// is returned for each build type (in the order of BuildTypes), otherwise the only returned
+// Config is the loaded one.
+func LoadJSONConfigs(configPath string) ([]*Config, error) {
+	var config Config
+	err := config.LoadJSONConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return config.ExpandBuildTypes()
+}
+
+// ExpandBuildTypes
+// Returns Config for each build type of BuildTypes. Package.IsDebug is set by the build type
+// and the build type is passed to the build system (CMAKE_BUILD_TYPE define of CMake,
+// buildtype option of Meson). Returned Configs have no BuildTypes. If BuildTypes is empty,
+// the Config itself is returned.
+func (config *Config) ExpandBuildTypes() ([]*Config, error) {
+	if len(config.BuildTypes) == 0 {
+		return []*Config{config}, nil
+	}
+	// The Config is copied through JSON, so the copy is the same as the Config loaded from the file
+	mbytes, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot copy config - %s", err)
+	}
+	var configs []*Config
+	for _, buildType := range config.BuildTypes {
+		expanded := &Config{}
+		err = json.Unmarshal(mbytes, expanded)
+		if err != nil {
+			return nil, fmt.Errorf("cannot copy config - %s", err)
+		}
+		expanded.BuildTypes = nil
+		expanded.Package.IsDebug = buildType == BuildTypeDebug
+		expanded.Build.setBuildType(buildType)
+		configs = append(configs, expanded)
+	}
+	return configs, nil
+}
+
+// checkBuildTypes
+// Returns error if BuildTypes contain unknown or duplicate build type.
+func (config *Config) checkBuildTypes() error {
+	for i, buildType := range config.BuildTypes {
+		if buildType != BuildTypeDebug && buildType != BuildTypeRelease {
+			return fmt.Errorf("invalid build type %s, allowed are %s and %s", buildType, BuildTypeDebug, BuildTypeRelease)
+		}
+		if slices.Contains(config.BuildTypes[:i], buildType) {
+			return fmt.Errorf("build type %s is specified more than once", buildType)
+		}
+	}
+	return nil
+}
+
+// setBuildType
+// Passes the build type to the build system. If no build system is specified, CMake with
+// default settings is used, so it is created. Autotools and CustomBuild have no build type.
+func (build *Build) setBuildType(buildType string) {
+	if build.CMake == nil && build.Meson == nil && build.Autotools == nil && build.CustomBuild == nil {
+		build.CMake = bringauto_prerequisites.CreateAndInitialize[bringauto_build.CMake]()
+	}
+	if build.CMake != nil {
+		if build.CMake.Defines == nil {
+			build.CMake.Defines = map[string]string{}
+		}
+		build.CMake.Defines["CMAKE_BUILD_TYPE"] = buildType
+	}
+	if build.Meson != nil {
+		if build.Meson.Options == nil {
+			build.Meson.Options = map[string]string{}
+		}
+		build.Meson.Options["buildtype"] = strings.ToLower(buildType)
+	}
+}
+
+// loadExtendedJSON
+// Reads the JSON definition and merges it onto the definition it extends (recursively). Paths
+// of already loaded definitions are in chain, they are used to detect circular Extends.
+// Returns the merged JSON without the Extends field.
+func loadExtendedJSON(configPath string, chain []string) ([]byte, error) {
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(chain, absPath) {
+		return nil, fmt.Errorf("circular Extends: %s", strings.Join(append(chain, absPath), " -> "))
+	}
+	mbytes, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]any
+	err = json.Unmarshal(mbytes, &object)
+	if err != nil {
+		return nil, err
+	}
+	extendsKey, found := findJSONKey(object, "Extends")
+	if !found {
+		return mbytes, nil
+	}
+	basePath, ok := object[extendsKey].(string)
+	if !ok {
+		return nil, fmt.Errorf("Extends must be a string")
+	}
+	delete(object, extendsKey)
+	if basePath == "" {
+		return json.Marshal(object)
+	}
+	if !filepath.IsAbs(basePath) {
+		basePath = filepath.Join(filepath.Dir(configPath), basePath)
+	}
+	baseBytes, err := loadExtendedJSON(basePath, append(chain, absPath))
+	if err != nil {
+		return nil, fmt.Errorf("cannot load extended definition %s - %s", basePath, err)
+	}
+	var base map[string]any
+	err = json.Unmarshal(baseBytes, &base)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeJSONObjects(base, object))
+}
+
+// mergeJSONObjects
+// Merges override onto base. Objects are merged recursively, other values of override replace
+// values of base. Keys are matched case-insensitively, the same way as json.Unmarshal does.
+func mergeJSONObjects(base map[string]any, override map[string]any) map[string]any {
+	for key, value := range override {
+		baseKey, found := findJSONKey(base, key)
+		if found {
+			baseObject, isBaseObject := base[baseKey].(map[string]any)
+			object, isObject := value.(map[string]any)
+			delete(base, baseKey)
+			if isBaseObject && isObject {
+				value = mergeJSONObjects(baseObject, object)
+			}
+		}
+		base[key] = value
+	}
+	return base
+}
+
+// findJSONKey
+// Returns key of the object which matches the given key. Exact match is preferred, otherwise
+// the key is matched case-insensitively.
+func findJSONKey(object map[string]any, key string) (string, bool) {
+	if _, found := object[key]; found {
+		return key, true
+	}
+	for objectKey := range object {
+		if strings.EqualFold(objectKey, key) {
+			return objectKey, true
+		}
+	}
+	return "", false
+}
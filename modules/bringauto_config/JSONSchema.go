@@ -0,0 +1,62 @@
+package bringauto_config
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+const (
+	jsonSchemaVersionConst = "https://json-schema.org/draft/2020-12/schema"
+	jsonSchemaTitleConst   = "BringAuto Packager Package Config"
+)
+
+// GenerateJSONSchema
+// Returns JSON Schema of the Package JSON definition generated from the Config type. Objects do not
+// allow additional properties, so the schema detects the same unknown fields as LoadJSONConfig.
+func GenerateJSONSchema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeFor[Config]())
+	schema["$schema"] = jsonSchemaVersionConst
+	schema["title"] = jsonSchemaTitleConst
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// typeSchema
+// Returns JSON Schema of the Go type typ as it is (un)marshalled by encoding/json.
+func typeSchema(typ reflect.Type) map[string]any {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		properties := map[string]any{}
+		for _, field := range getJSONFields(typ) {
+			properties[field.Name] = typeSchema(field.Type)
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": typeSchema(typ.Elem()),
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  "array",
+			"items": typeSchema(typ.Elem()),
+		}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	default:
+		return map[string]any{}
+	}
+}
@@ -0,0 +1,137 @@
+package bringauto_config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// unmarshalStrict
+// Unmarshals JSON data to v in the same way as json.Unmarshal, but returns error if the data
+// contain any field which does not exist in v. The error contains JSON paths of all unknown fields
+// (e.g. $.Build.CMake.Defins).
+func unmarshalStrict(data []byte, v any) error {
+	var generic any
+	err := json.Unmarshal(data, &generic)
+	if err != nil {
+		return err
+	}
+	var unknownFields []string
+	collectUnknownFields(generic, reflect.TypeOf(v), "$", &unknownFields)
+	if len(unknownFields) > 0 {
+		sort.Strings(unknownFields)
+		return fmt.Errorf("unknown fields: %s", strings.Join(unknownFields, ", "))
+	}
+	return json.Unmarshal(data, v)
+}
+
+// collectUnknownFields
+// Walks the decoded JSON value together with the Go type it is unmarshalled to and appends JSON
+// paths of fields which do not exist in the Go type to unknownFields. Field names are matched
+// case-insensitively, the same way as json.Unmarshal does.
+func collectUnknownFields(value any, typ reflect.Type, path string, unknownFields *[]string) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if reflect.PointerTo(typ).Implements(reflect.TypeFor[json.Unmarshaler]()) {
+		return
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := getJSONFields(typ)
+		for key, fieldValue := range object {
+			field, found := findJSONField(fields, key)
+			if !found {
+				*unknownFields = append(*unknownFields, path+"."+key)
+				continue
+			}
+			collectUnknownFields(fieldValue, field.Type, path+"."+key, unknownFields)
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		for key, itemValue := range object {
+			collectUnknownFields(itemValue, typ.Elem(), path+"."+key, unknownFields)
+		}
+	case reflect.Slice, reflect.Array:
+		array, ok := value.([]any)
+		if !ok {
+			return
+		}
+		for i, itemValue := range array {
+			collectUnknownFields(itemValue, typ.Elem(), path+"["+strconv.Itoa(i)+"]", unknownFields)
+		}
+	}
+}
+
+// jsonField
+// Field of a struct as seen by encoding/json.
+type jsonField struct {
+	Name      string
+	Type      reflect.Type
+	OmitEmpty bool
+}
+
+// getJSONFields
+// Returns all fields of the struct type typ which are (un)marshalled by encoding/json. Fields of
+// embedded structs are promoted.
+func getJSONFields(typ reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		tag := structField.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		tagName, tagOptions, _ := strings.Cut(tag, ",")
+		if structField.Anonymous && tagName == "" {
+			embeddedType := structField.Type
+			if embeddedType.Kind() == reflect.Pointer {
+				embeddedType = embeddedType.Elem()
+			}
+			if embeddedType.Kind() == reflect.Struct {
+				fields = append(fields, getJSONFields(embeddedType)...)
+				continue
+			}
+		}
+		if !structField.IsExported() {
+			continue
+		}
+		name := structField.Name
+		if tagName != "" {
+			name = tagName
+		}
+		fields = append(fields, jsonField{
+			Name:      name,
+			Type:      structField.Type,
+			OmitEmpty: strings.Contains(tagOptions, "omitempty"),
+		})
+	}
+	return fields
+}
+
+// findJSONField
+// Returns field with the given JSON key. Exact match is preferred, otherwise the key is matched
+// case-insensitively.
+func findJSONField(fields []jsonField, key string) (jsonField, bool) {
+	for _, field := range fields {
+		if field.Name == key {
+			return field, true
+		}
+	}
+	for _, field := range fields {
+		if strings.EqualFold(field.Name, key) {
+			return field, true
+		}
+	}
+	return jsonField{}, false
+}
@@ -0,0 +1,399 @@
+package bringauto_config
+
+import (
+	"bringauto/modules/bringauto_source"
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const (
+	TestDataDirName = "test_data"
+	ValidConfigPath = TestDataDirName + "/valid.json"
+	UnknownFieldsConfigPath = TestDataDirName + "/unknown_fields.json"
+	LocalSourceConfigPath = TestDataDirName + "/local_source.json"
+	TwoSourcesConfigPath = TestDataDirName + "/two_sources.json"
+	ExtendsConfigPath = TestDataDirName + "/extends.json"
+	BuildTypesConfigPath = TestDataDirName + "/build_types.json"
+	CircularExtendsConfigPath = TestDataDirName + "/circular_extends.json"
+	InvalidBuildTypesConfigPath = TestDataDirName + "/invalid_build_types.json"
+	PatchesConfigPath = TestDataDirName + "/patches.json"
+	PatchPath = TestDataDirName + "/fix.patch"
+	LocalSourceFilePath = TestDataDirName + "/pack1_src/CMakeLists.txt"
+	// Published JSON Schema of the Package JSON definition
+	JSONSchemaPath = "../../doc/PackageConfig.schema.json"
+)
+
+var update = flag.Bool("update", false, "update published JSON Schema")
+
+func TestLoadJSONConfig(t *testing.T) {
+	var config Config
+	err := config.LoadJSONConfig(ValidConfigPath)
+	if err != nil {
+		t.Fatalf("LoadJSONConfig failed - %s", err)
+	}
+	if config.Package.Name != "pack1" || config.Build.CMake == nil {
+		t.Fatalf("wrong loaded config - %v", config)
+	}
+}
+
+func TestLoadJSONConfigUnknownFields(t *testing.T) {
+	var config Config
+	err := config.LoadJSONConfig(UnknownFieldsConfigPath)
+	if err == nil {
+		t.Fatal("LoadJSONConfig didn't returned error")
+	}
+	for _, expected := range []string{UnknownFieldsConfigPath, "$.DependOn", "$.Build.CMake.Defins"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("error does not contain %s - %s", expected, err)
+		}
+	}
+}
+
+func TestLoadJSONConfigLocalSource(t *testing.T) {
+	var config Config
+	err := config.LoadJSONConfig(LocalSourceConfigPath)
+	if err != nil {
+		t.Fatalf("LoadJSONConfig failed - %s", err)
+	}
+	if config.Source.LocalDirectory == nil {
+		t.Fatal("local source not loaded")
+	}
+	expectedPath := filepath.Join(TestDataDirName, "pack1_src")
+	if config.Source.LocalDirectory.Path != expectedPath {
+		t.Errorf("local source path not relative to the config - %s", config.Source.LocalDirectory.Path)
+	}
+	if config.GetSourceRevision() != expectedPath {
+		t.Errorf("wrong source revision - %s", config.GetSourceRevision())
+	}
+}
+
+func TestLoadJSONConfigTwoSources(t *testing.T) {
+	var config Config
+	err := config.LoadJSONConfig(TwoSourcesConfigPath)
+	if err == nil {
+		t.Fatal("LoadJSONConfig didn't returned error")
+	}
+	if !strings.Contains(err.Error(), "only one source") {
+		t.Errorf("wrong error - %s", err)
+	}
+}
+
+func TestSourceCheckSources(t *testing.T) {
+	local := &bringauto_source.LocalDirectory{Path: "pack1_src"}
+	invalidTarball := &bringauto_source.Tarball{URL: "ftp://example.com/pack1.tar.gz"}
+	tests := []struct {
+		source   Source
+		expected string
+	}{
+		{Source{LocalDirectory: local}, ""},
+		{Source{Tarball: invalidTarball}, "unsupported tarball URL scheme"},
+		{Source{Tarball: invalidTarball, LocalDirectory: local}, "only one source"},
+		{Source{Tarball: invalidTarball, LocalDirectory: &bringauto_source.LocalDirectory{}}, "only one source"},
+	}
+	for _, test := range tests {
+		err := test.source.checkSources()
+		if test.expected == "" && err != nil {
+			t.Errorf("checkSources failed - %s", err)
+		}
+		if test.expected != "" && (err == nil || !strings.Contains(err.Error(), test.expected)) {
+			t.Errorf("wrong error - %v, expected %s", err, test.expected)
+		}
+	}
+}
+
+func TestLoadJSONConfigExtends(t *testing.T) {
+	var config Config
+	err := config.LoadJSONConfig(ExtendsConfigPath)
+	if err != nil {
+		t.Fatalf("LoadJSONConfig failed - %s", err)
+	}
+	if config.Package.Name != "pack1" || config.Package.VersionTag != "v1.1.0" || !config.Package.IsLibrary {
+		t.Errorf("Package not merged with the base - %v", config.Package)
+	}
+	if config.Git.URI != "https://github.com/bringauto/pack1.git" {
+		t.Errorf("Git not taken from the base - %v", config.Git)
+	}
+	if config.Build.CMake == nil || config.Build.CMake.Defines["BRINGAUTO_SAMPLES"] != "OFF" {
+		t.Errorf("CMake Defines not merged - %v", config.Build.CMake)
+	}
+	if !slices.Equal(config.DockerMatrix.ImageNames, []string{"image2"}) {
+		t.Errorf("ImageNames not replaced - %v", config.DockerMatrix.ImageNames)
+	}
+	if config.Extends != "" {
+		t.Errorf("Extends not removed - %s", config.Extends)
+	}
+}
+
+func TestLoadJSONConfigCircularExtends(t *testing.T) {
+	var config Config
+	err := config.LoadJSONConfig(CircularExtendsConfigPath)
+	if err == nil {
+		t.Fatal("LoadJSONConfig didn't returned error")
+	}
+	if !strings.Contains(err.Error(), "circular Extends") {
+		t.Errorf("wrong error - %s", err)
+	}
+}
+
+func TestLoadJSONConfigs(t *testing.T) {
+	configs, err := LoadJSONConfigs(BuildTypesConfigPath)
+	if err != nil {
+		t.Fatalf("LoadJSONConfigs failed - %s", err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("wrong number of configs - %d", len(configs))
+	}
+	for i, buildType := range []string{BuildTypeDebug, BuildTypeRelease} {
+		config := configs[i]
+		if config.Package.IsDebug != (buildType == BuildTypeDebug) {
+			t.Errorf("wrong IsDebug of %s config", buildType)
+		}
+		if config.Build.CMake.Defines["CMAKE_BUILD_TYPE"] != buildType {
+			t.Errorf("wrong CMAKE_BUILD_TYPE of %s config - %v", buildType, config.Build.CMake.Defines)
+		}
+		if config.Build.CMake.Defines["BRINGAUTO_SAMPLES"] != "OFF" {
+			t.Errorf("Defines of %s config not inherited - %v", buildType, config.Build.CMake.Defines)
+		}
+		if len(config.BuildTypes) != 0 {
+			t.Errorf("BuildTypes not removed from %s config", buildType)
+		}
+	}
+}
+
+func TestLoadJSONConfigsInvalidBuildType(t *testing.T) {
+	_, err := LoadJSONConfigs(InvalidBuildTypesConfigPath)
+	if err == nil {
+		t.Fatal("LoadJSONConfigs didn't returned error")
+	}
+	if !strings.Contains(err.Error(), "RelWithDebInfo") {
+		t.Errorf("wrong error - %s", err)
+	}
+}
+
+func TestExpandBuildTypesDefaultBuildSystem(t *testing.T) {
+	config := Config{BuildTypes: []string{BuildTypeRelease}}
+	configs, err := config.ExpandBuildTypes()
+	if err != nil {
+		t.Fatalf("ExpandBuildTypes failed - %s", err)
+	}
+	if len(configs) != 1 || configs[0].Build.CMake == nil {
+		t.Fatalf("default CMake not created - %v", configs)
+	}
+	if configs[0].Build.CMake.Defines["CMAKE_BUILD_TYPE"] != BuildTypeRelease {
+		t.Errorf("wrong CMAKE_BUILD_TYPE - %v", configs[0].Build.CMake.Defines)
+	}
+	if config.Build.CMake != nil {
+		t.Error("original config modified")
+	}
+}
+
+func TestGetDependencies(t *testing.T) {
+	config := Config{
+		DependsOn: []string{"zlib", "  openssl  >= 3.0  <4 "},
+	}
+	err := config.checkDependencies()
+	if err != nil {
+		t.Fatalf("checkDependencies failed - %s", err)
+	}
+	dependencies := config.GetDependencies()
+	if len(dependencies) != 2 || dependencies[0].Constraint != nil || dependencies[1].Name != "openssl" {
+		t.Fatalf("wrong dependencies - %v", dependencies)
+	}
+	if dependencies[1].String() != "openssl >=3.0 <4" {
+		t.Errorf("wrong dependency string - %s", dependencies[1])
+	}
+	if !dependencies[1].IsSatisfiedBy("v3.2.1") || dependencies[1].IsSatisfiedBy("v4.0.0") {
+		t.Error("wrong version constraint check")
+	}
+	if !dependencies[0].IsSatisfiedBy("v0.0.1") {
+		t.Error("dependency without constraint not satisfied")
+	}
+	if names := config.GetDependsOnNames(); len(names) != 2 || names[0] != "zlib" || names[1] != "openssl" {
+		t.Errorf("wrong dependency names - %s", names)
+	}
+
+	for _, dependsOn := range [][]string{{"zlib >=1.2 <"}, {"zlib 1.x"}, {""}, {"zlib", "zlib >=1.0.0"}} {
+		config.DependsOn = dependsOn
+		err = config.checkDependencies()
+		if err == nil {
+			t.Errorf("invalid DependsOn %s not detected", dependsOn)
+		}
+	}
+}
+
+func TestGetBuildAndRuntimeDependencies(t *testing.T) {
+	config := Config{
+		DependsOn:        []string{"zlib"},
+		BuildDependsOn:   []string{"protoc >=3"},
+		RuntimeDependsOn: []string{"tzdata"},
+	}
+	err := config.checkDependencies()
+	if err != nil {
+		t.Fatalf("checkDependencies failed - %s", err)
+	}
+	names := func(dependencies []Dependency) []string {
+		var dependencyNames []string
+		for _, dependency := range dependencies {
+			dependencyNames = append(dependencyNames, dependency.Name)
+		}
+		return dependencyNames
+	}
+	if dependencies := names(config.GetDependencies()); !slices.Equal(dependencies, []string{"zlib", "protoc", "tzdata"}) {
+		t.Errorf("wrong dependencies - %s", dependencies)
+	}
+	if dependencies := names(config.GetBuildDependencies()); !slices.Equal(dependencies, []string{"zlib", "protoc"}) {
+		t.Errorf("wrong build dependencies - %s", dependencies)
+	}
+	if dependencies := names(config.GetRuntimeDependencies()); !slices.Equal(dependencies, []string{"zlib", "tzdata"}) {
+		t.Errorf("wrong runtime dependencies - %s", dependencies)
+	}
+
+	config.RuntimeDependsOn = []string{"protoc"}
+	err = config.checkDependencies()
+	if err == nil {
+		t.Error("dependency in both BuildDependsOn and RuntimeDependsOn not detected")
+	}
+}
+
+func TestGetCacheKeyRuntimeDependencies(t *testing.T) {
+	config := Config{
+		DependsOn:      []string{"zlib"},
+		BuildDependsOn: []string{"protoc"},
+	}
+	cacheKey, err := config.GetCacheKey("image", nil, []string{"zlib-key", "protoc-key"}, BuildOptions{})
+	if err != nil {
+		t.Fatalf("GetCacheKey failed - %s", err)
+	}
+	config.DependsOn = []string{}
+	config.BuildDependsOn = []string{"protoc"}
+	config.RuntimeDependsOn = []string{"zlib"}
+	sameKey, err := config.GetCacheKey("image", nil, []string{"zlib-key", "protoc-key"}, BuildOptions{})
+	if err != nil {
+		t.Fatalf("GetCacheKey failed - %s", err)
+	}
+	if sameKey != cacheKey {
+		t.Error("cache key changed by moving runtime dependency")
+	}
+	config.RuntimeDependsOn = []string{}
+	config.BuildDependsOn = []string{"protoc", "zlib"}
+	changedKey, err := config.GetCacheKey("image", nil, []string{"zlib-key", "protoc-key"}, BuildOptions{})
+	if err != nil {
+		t.Fatalf("GetCacheKey failed - %s", err)
+	}
+	if changedKey == cacheKey {
+		t.Error("cache key not changed by removing runtime dependency")
+	}
+}
+
+func TestGetCacheKeyLocalSource(t *testing.T) {
+	sourceDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(sourceDir, "main.c"), []byte("int main() { return 0; }\n"), 0644)
+	if err != nil {
+		t.Fatalf("can't write source file - %s", err)
+	}
+	var config Config
+	config.Source.LocalDirectory = &bringauto_source.LocalDirectory{Path: sourceDir}
+	key1, err := config.GetCacheKey("image", nil, nil, BuildOptions{})
+	if err != nil {
+		t.Fatalf("GetCacheKey failed - %s", err)
+	}
+	err = os.WriteFile(filepath.Join(sourceDir, "main.c"), []byte("int main() { return 1; }\n"), 0644)
+	if err != nil {
+		t.Fatalf("can't write source file - %s", err)
+	}
+	key2, err := config.GetCacheKey("image", nil, nil, BuildOptions{})
+	if err != nil {
+		t.Fatalf("GetCacheKey failed - %s", err)
+	}
+	if key1 == key2 {
+		t.Error("cache key not changed after change of the local source")
+	}
+}
+
+func TestGetCacheKeyContextLocation(t *testing.T) {
+	for _, configPath := range []string{PatchesConfigPath, LocalSourceConfigPath} {
+		var keys []string
+		for range 2 {
+			contextDir := t.TempDir()
+			copyTestFile(t, configPath, contextDir)
+			copyTestFile(t, PatchPath, contextDir)
+			err := os.Mkdir(filepath.Join(contextDir, "pack1_src"), 0755)
+			if err != nil {
+				t.Fatalf("can't create source directory - %s", err)
+			}
+			copyTestFile(t, LocalSourceFilePath, filepath.Join(contextDir, "pack1_src"))
+			var config Config
+			err = config.LoadJSONConfig(filepath.Join(contextDir, filepath.Base(configPath)))
+			if err != nil {
+				t.Fatalf("LoadJSONConfig failed - %s", err)
+			}
+			key, err := config.GetCacheKey("image", nil, nil, BuildOptions{})
+			if err != nil {
+				t.Fatalf("GetCacheKey failed - %s", err)
+			}
+			keys = append(keys, key)
+		}
+		if keys[0] != keys[1] {
+			t.Errorf("cache key of %s changed after change of the context location", configPath)
+		}
+	}
+}
+
+func TestGetCacheKeyBuildOptions(t *testing.T) {
+	var config Config
+	var keys []string
+	for _, options := range []BuildOptions{
+		{},
+		{Reproducible: true},
+		{Reproducible: true, SourceDateEpoch: 1700000000},
+		{SplitDebugSymbols: true},
+	} {
+		key, err := config.GetCacheKey("image", nil, nil, options)
+		if err != nil {
+			t.Fatalf("GetCacheKey failed - %s", err)
+		}
+		if slices.Contains(keys, key) {
+			t.Errorf("cache key not changed by build options %v", options)
+		}
+		keys = append(keys, key)
+	}
+}
+
+func TestJSONSchemaUpToDate(t *testing.T) {
+	schema, err := GenerateJSONSchema()
+	if err != nil {
+		t.Fatalf("GenerateJSONSchema failed - %s", err)
+	}
+	schema = append(schema, '\n')
+	if *update {
+		err = os.WriteFile(JSONSchemaPath, schema, 0644)
+		if err != nil {
+			t.Fatalf("can't write JSON Schema - %s", err)
+		}
+	}
+	published, err := os.ReadFile(JSONSchemaPath)
+	if err != nil {
+		t.Fatalf("can't read JSON Schema - %s", err)
+	}
+	if !bytes.Equal(published, schema) {
+		t.Error("published JSON Schema is outdated, run 'go test ./modules/bringauto_config -update'")
+	}
+}
+
+// copyTestFile
+// Copies the file at path to the directory dir.
+func copyTestFile(t *testing.T, path string, dir string) {
+	mbytes, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("can't read test file - %s", err)
+	}
+	err = os.WriteFile(filepath.Join(dir, filepath.Base(path)), mbytes, 0644)
+	if err != nil {
+		t.Fatalf("can't write test file - %s", err)
+	}
+}
@@ -8,10 +8,12 @@ import (
 const (
 	// Where to install files on the remote machine
 	DockerInstallDirConst = string(filepath.Separator) + "INSTALL"
-	// Default SSH port of docker container
+	// First host port tried when allocating SSH port of docker container
 	DefaultSSHPort = 1122
 	// Name of the docker directory
 	DockerDirName  = "docker"
 	// Name of the package directory
 	PackageDirName = "package"
+	// Name of the optional file with settings of the whole context
+	ContextSettingsFileName = "context.json"
 )
@@ -11,6 +11,8 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 )
 
 // ContextManager
@@ -43,24 +45,13 @@ func (context *ContextManager) GetAllPackagesJsonDefPaths() (map[string][]string
 // Returns Config structs of all Package Configs. If platformString is not nil, it is added to all
 // Packages.
 func (context *ContextManager) GetAllPackagesConfigs(platformString *bringauto_package.PlatformString) ([]*bringauto_config.Config, error) {
-	var packConfigs []*bringauto_config.Config
-	packageJsonPathMap, err := context.GetAllPackagesJsonDefPaths()
+	packConfigs, _, err := context.getAllPackagesConfigsWithPaths()
 	if err != nil {
 		return nil, err
 	}
-	logger := bringauto_log.GetLogger()
-	for _, packageJsonPaths := range packageJsonPathMap {
-		for _, packageJsonPath := range packageJsonPaths {
-			var config bringauto_config.Config
-			err = config.LoadJSONConfig(packageJsonPath)
-			if err != nil {
-				logger.Warn("Couldn't load JSON config from %s path - %s", packageJsonPath, err)
-				continue
-			}
-			if platformString != nil {
-				config.Package.PlatformString = *platformString
-			}
-			packConfigs = append(packConfigs, &config)
+	if platformString != nil {
+		for _, config := range packConfigs {
+			config.Package.PlatformString = *platformString
 		}
 	}
 	return packConfigs, nil
@@ -137,75 +128,103 @@ func (context *ContextManager) GetPackageJsonDefPaths(packageName string) ([]str
 
 // getAllDepsJsonPaths
 // Returns all Config paths for given Package (specified with packageJsonPath) and all Configs for
-// its dependencies recursively. For tracking of circular dependencies, the visited map must be
+// its dependencies recursively. Only dependency Configs of the version selected by SelectDependency
+// are returned. If runtimeOnly is true, only runtime dependencies (DependsOn and RuntimeDependsOn)
+// are followed. For tracking of circular dependencies, the visited map must be
 // initialized before function call.
-func (context *ContextManager) getAllDepsJsonPaths(packageJsonPath string, visited map[string]struct{}) ([]string, error) {
-	var config bringauto_config.Config
-	err := config.LoadJSONConfig(packageJsonPath)
+func (context *ContextManager) getAllDepsJsonPaths(packageJsonPath string, visited map[string]struct{}, runtimeOnly bool) ([]string, error) {
+	configs, err := bringauto_config.LoadJSONConfigs(packageJsonPath)
 	if err != nil {
 		return []string{}, fmt.Errorf("couldn't load JSON config from %s path - %s", packageJsonPath, err)
 	}
 	visited[packageJsonPath] = struct{}{}
-	addedPackages := 0
 	var jsonPathListWithDeps []string
-	for _, packageDep := range config.DependsOn {
-		packageDepsJsonPaths, err := context.GetPackageJsonDefPaths(packageDep)
-		if err != nil {
-			return []string{}, fmt.Errorf("couldn't get Json Path of %s package", packageDep)
+	for _, config := range configs {
+		dependencies := config.GetDependencies()
+		if runtimeOnly {
+			dependencies = config.GetRuntimeDependencies()
 		}
-		var depConfig bringauto_config.Config
-		for _, packageDepJsonPath := range packageDepsJsonPaths {
-			err := depConfig.LoadJSONConfig(packageDepJsonPath)
+		for _, dependency := range dependencies {
+			packageDepsJsonPaths, err := context.GetPackageJsonDefPaths(dependency.Name)
 			if err != nil {
-				return []string{}, fmt.Errorf("couldn't load JSON config from %s path - %s", packageDepJsonPath, err)
-			}
-			if depConfig.Package.IsDebug != config.Package.IsDebug {
-				continue
+				return []string{}, fmt.Errorf("couldn't get Json Path of %s package", dependency.Name)
 			}
-			addedPackages++
-			_, packageVisited := visited[packageDepJsonPath]
-			if packageVisited {
-				continue
+			depConfigs, depConfigPaths, err := loadConfigs(packageDepsJsonPaths)
+			if err != nil {
+				return []string{}, err
 			}
-			jsonPathListWithDeps = append(jsonPathListWithDeps, packageDepJsonPath)
-			jsonPathListWithDepsTmp, err := context.getAllDepsJsonPaths(packageDepJsonPath, visited)
+			resolvedConfigs, err := SelectDependency(config, dependency, depConfigs)
 			if err != nil {
 				return []string{}, err
 			}
-			jsonPathListWithDeps = append(jsonPathListWithDeps, jsonPathListWithDepsTmp...)
+			if len(resolvedConfigs) == 0 {
+				return []string{}, fmt.Errorf("package %s dependencies do not have package with same build type", config.Package.Name)
+			}
+			for i, packageDepJsonPath := range depConfigPaths {
+				if !slices.Contains(resolvedConfigs, depConfigs[i]) {
+					continue
+				}
+				_, packageVisited := visited[packageDepJsonPath]
+				if packageVisited {
+					continue
+				}
+				jsonPathListWithDeps = append(jsonPathListWithDeps, packageDepJsonPath)
+				jsonPathListWithDepsTmp, err := context.getAllDepsJsonPaths(packageDepJsonPath, visited, runtimeOnly)
+				if err != nil {
+					return []string{}, err
+				}
+				jsonPathListWithDeps = append(jsonPathListWithDeps, jsonPathListWithDepsTmp...)
+			}
 		}
 	}
 
-	if addedPackages < len(config.DependsOn) {
-		return []string{}, fmt.Errorf("package %s dependencies do not have package with same build type", config.Package.Name)
-	}
-
 	return jsonPathListWithDeps, nil
 }
 
+// loadConfigs
+// Loads Configs of all given Package JSON definitions. Returns the Configs together with paths of
+// their definitions (one definition expands to more Configs if it has more BuildTypes).
+func loadConfigs(packageJsonPaths []string) ([]*bringauto_config.Config, []string, error) {
+	var configs []*bringauto_config.Config
+	var configPaths []string
+	for _, packageJsonPath := range packageJsonPaths {
+		loadedConfigs, err := bringauto_config.LoadJSONConfigs(packageJsonPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't load JSON config from %s path - %s", packageJsonPath, err)
+		}
+		for _, config := range loadedConfigs {
+			configs = append(configs, config)
+			configPaths = append(configPaths, packageJsonPath)
+		}
+	}
+	return configs, configPaths, nil
+}
+
 // getAllDepsOnJsonPaths
-// Returns all Config paths of Packages which depends on Package specified with config. If
+// Returns all Config paths of Packages which depends on Package specified with config at build time
+// (DependsOn or BuildDependsOn, its version is selected for them by SelectDependency). Only
+// versions of the dependent Packages which selected the version of config are returned. If
 // recursively is set to true, it is done recursively. For tracking of circular dependencies,
 // the visited map must be initialized before function call.
 func (context *ContextManager) getAllDepsOnJsonPaths(config bringauto_config.Config, visited map[string]struct{}, recursively bool) ([]string, error) {
-	packConfigs, err := context.GetAllPackagesConfigs(nil)
+	packConfigs, packConfigPaths, err := context.getAllPackagesConfigsWithPaths()
 	if err != nil {
 		return []string{}, err
 	}
-	visited[config.Package.Name] = struct{}{}
+	visited[getConfigKey(&config)] = struct{}{}
 	var packsToBuild []string
-	for _, packConfig := range packConfigs {
+	for i, packConfig := range packConfigs {
 		if (packConfig.Package.Name == config.Package.Name ||
 	 	  	packConfig.Package.IsDebug != config.Package.IsDebug){
 			continue
 		}
-		for _, dep := range packConfig.DependsOn {
-			if dep == config.Package.Name {
-				_, packageVisited := visited[packConfig.Package.Name]
+		for _, dependency := range packConfig.GetBuildDependencies() {
+			if dependency.Name == config.Package.Name && isSelectedDependency(packConfig, dependency, &config, packConfigs) {
+				_, packageVisited := visited[getConfigKey(packConfig)]
 				if packageVisited {
 					break
 				}
-				err = context.addDependsOnPackagesToBuild(&packsToBuild, packConfig, visited, recursively)
+				err = context.addDependsOnPackagesToBuild(&packsToBuild, packConfig, packConfigPaths[i], visited, recursively)
 				if err != nil {
 					return []string{}, err
 				}
@@ -217,12 +236,68 @@ func (context *ContextManager) getAllDepsOnJsonPaths(config bringauto_config.Con
 	return packsToBuild, nil
 }
 
-func (context *ContextManager) addDependsOnPackagesToBuild(packsToBuild *[]string, packConfig *bringauto_config.Config, visited map[string]struct{}, recursively bool) error {
-	packWithDeps, err := context.GetPackageWithDepsJsonDefPaths(packConfig.Package.Name)
+// getAllPackagesConfigsWithPaths
+// Returns Config structs of all Package Configs together with paths of their definitions.
+func (context *ContextManager) getAllPackagesConfigsWithPaths() ([]*bringauto_config.Config, []string, error) {
+	packageJsonPathMap, err := context.GetAllPackagesJsonDefPaths()
+	if err != nil {
+		return nil, nil, err
+	}
+	var packConfigs []*bringauto_config.Config
+	var packConfigPaths []string
+	logger := bringauto_log.GetLogger()
+	for _, packageJsonPaths := range packageJsonPathMap {
+		for _, packageJsonPath := range packageJsonPaths {
+			configs, err := bringauto_config.LoadJSONConfigs(packageJsonPath)
+			if err != nil {
+				logger.Warn("Couldn't load JSON config from %s path - %s", packageJsonPath, err)
+				continue
+			}
+			for _, config := range configs {
+				packConfigs = append(packConfigs, config)
+				packConfigPaths = append(packConfigPaths, packageJsonPath)
+			}
+		}
+	}
+	return packConfigs, packConfigPaths, nil
+}
+
+// getConfigKey
+// Returns key which identifies the Package of config - the Package name, version and build type.
+func getConfigKey(config *bringauto_config.Config) string {
+	return config.Package.Name + ":" + config.Package.VersionTag + ":" + strconv.FormatBool(config.Package.IsDebug)
+}
+
+// isSelectedDependency
+// Returns true if the version of the config is selected for the dependent Config from candidates,
+// else returns false.
+func isSelectedDependency(
+	dependent  *bringauto_config.Config,
+	dependency bringauto_config.Dependency,
+	config     *bringauto_config.Config,
+	candidates []*bringauto_config.Config,
+) bool {
+	selectedConfigs, err := SelectDependency(dependent, dependency, candidates)
+	return err == nil && len(selectedConfigs) > 0 &&
+		selectedConfigs[0].Package.VersionTag == config.Package.VersionTag
+}
+
+// addDependsOnPackagesToBuild
+// Adds the Config path of packConfig and Config paths of dependency versions selected for it to
+// packsToBuild. If recursively is set to true, Packages which depends on packConfig are added too.
+func (context *ContextManager) addDependsOnPackagesToBuild(
+	packsToBuild   *[]string,
+	packConfig     *bringauto_config.Config,
+	packConfigPath string,
+	visited        map[string]struct{},
+	recursively    bool,
+) error {
+	packDeps, err := context.getAllDepsJsonPaths(packConfigPath, make(map[string]struct{}), false)
 	if err != nil {
 		return err
 	}
-	*packsToBuild = append(*packsToBuild, packWithDeps...)
+	*packsToBuild = append(*packsToBuild, packConfigPath)
+	*packsToBuild = append(*packsToBuild, packDeps...)
 	if recursively {
 		packsDepsOnRecursive, err := context.getAllDepsOnJsonPaths(*packConfig, visited, true)
 		if err != nil {
@@ -236,6 +311,21 @@ func (context *ContextManager) addDependsOnPackagesToBuild(packsToBuild *[]strin
 // GetPackageWithDepsJsonDefPaths
 // Returns all Config paths for given Package and all its dependencies Config paths recursively.
 func (context *ContextManager) GetPackageWithDepsJsonDefPaths(packageName string) ([]string, error) {
+	return context.getPackageWithDepsJsonDefPaths(packageName, false)
+}
+
+// GetPackageWithRuntimeDepsJsonDefPaths
+// Returns all Config paths for given Package and Config paths of its runtime dependencies
+// (DependsOn and RuntimeDependsOn) recursively. Packages needed only for the build are not
+// returned.
+func (context *ContextManager) GetPackageWithRuntimeDepsJsonDefPaths(packageName string) ([]string, error) {
+	return context.getPackageWithDepsJsonDefPaths(packageName, true)
+}
+
+// getPackageWithDepsJsonDefPaths
+// Returns all Config paths for given Package and its dependencies Config paths recursively. If
+// runtimeOnly is true, only runtime dependencies are followed.
+func (context *ContextManager) getPackageWithDepsJsonDefPaths(packageName string, runtimeOnly bool) ([]string, error) {
 	packageDefs, err := context.GetPackageJsonDefPaths(packageName)
 	if err != nil {
 		return []string{}, fmt.Errorf("cannot get config paths for package '%s' - %s", packageName, err)
@@ -243,7 +333,7 @@ func (context *ContextManager) GetPackageWithDepsJsonDefPaths(packageName string
 	var packageDeps []string
 	visitedPackages := make(map[string]struct{})
 	for _, packageDef := range packageDefs {
-		packageDepsTmp, err := context.getAllDepsJsonPaths(packageDef, visitedPackages)
+		packageDepsTmp, err := context.getAllDepsJsonPaths(packageDef, visitedPackages, runtimeOnly)
 		if err != nil {
 			return []string{}, err
 		}
@@ -266,13 +356,12 @@ func (context *ContextManager) GetDepsOnJsonDefPaths(packageName string, recursi
 	}
 	var packsToBuild []string
 	visitedPackages := make(map[string]struct{})
-	for _, packageDef := range packageDefs {
-		var config bringauto_config.Config
-		err := config.LoadJSONConfig(packageDef)
-		if err != nil {
-			return []string{}, fmt.Errorf("couldn't load JSON config from %s path - %s", packageDef, err)
-		}
-		packageDepsTmp, err := context.getAllDepsOnJsonPaths(config, visitedPackages, recursively)
+	configs, _, err := loadConfigs(packageDefs)
+	if err != nil {
+		return []string{}, err
+	}
+	for _, config := range configs {
+		packageDepsTmp, err := context.getAllDepsOnJsonPaths(*config, visitedPackages, recursively)
 		if err != nil {
 			return []string{}, err
 		}
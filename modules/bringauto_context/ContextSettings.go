@@ -0,0 +1,46 @@
+package bringauto_context
+
+import (
+	"bringauto/modules/bringauto_const"
+	"bringauto/modules/bringauto_package"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+)
+
+// ContextSettings
+// Settings of the whole Context stored in the context.json file in the Context root directory.
+// The file is optional, default settings are used if it does not exist.
+type ContextSettings struct {
+	// PackageFormat format of the Package archives (zip, tar.gz or tar.zst), zip if empty
+	PackageFormat bringauto_package.ArchiveFormat
+}
+
+// GetSettings
+// Returns settings of the Context. Returns default settings if the Context has no settings file.
+func (context *ContextManager) GetSettings() (*ContextSettings, error) {
+	settings := ContextSettings{
+		PackageFormat: bringauto_package.FormatZip,
+	}
+	settingsPath := path.Join(context.ContextPath, bringauto_const.ContextSettingsFileName)
+	mbytes, err := os.ReadFile(settingsPath)
+	if os.IsNotExist(err) {
+		return &settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(mbytes))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&settings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid context settings %s - %s", settingsPath, err)
+	}
+	settings.PackageFormat, err = bringauto_package.ParseArchiveFormat(string(settings.PackageFormat))
+	if err != nil {
+		return nil, fmt.Errorf("invalid context settings %s - %s", settingsPath, err)
+	}
+	return &settings, nil
+}
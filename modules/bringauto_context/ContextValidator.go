@@ -0,0 +1,232 @@
+package bringauto_context
+
+import (
+	"bringauto/modules/bringauto_config"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ValidationProblem
+// One problem found by ValidateContext.
+type ValidationProblem struct {
+	// Path of the file which contains the problem
+	Path string
+	// Message description of the problem
+	Message string
+}
+
+func (problem ValidationProblem) String() string {
+	return problem.Path + ": " + problem.Message
+}
+
+// loadedConfig
+// Config loaded from the Package JSON definition together with its path.
+type loadedConfig struct {
+	path   string
+	config *bringauto_config.Config
+}
+
+// ValidateContext
+// Loads all Package Configs in the Context and checks them. Following problems are reported:
+//   - Config cannot be loaded or Package is not valid (e.g. invalid VersionTag)
+//   - Package name differs from the name of its directory
+//   - Dockerfile of an image from DockerMatrix does not exist
+//   - patch file from Patches does not exist
+//   - Package from DependsOn does not exist or it has no Config with the same build type
+//   - no Config of the Package from DependsOn satisfies the version constraint
+//   - Package from DependsOn is not built for all images of the Config
+//   - Debug and Release Configs of the same Package version have different Git Revision
+//   - two Configs of the same Package version and build type are built for the same image
+//
+// All problems found are returned. Error is returned only if the Context cannot be read.
+func (context *ContextManager) ValidateContext() ([]ValidationProblem, error) {
+	packageJsonPathMap, err := context.GetAllPackagesJsonDefPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []ValidationProblem
+	configsByName := map[string][]loadedConfig{}
+	for _, packageJsonPaths := range packageJsonPathMap {
+		for _, packageJsonPath := range packageJsonPaths {
+			configs, err := bringauto_config.LoadJSONConfigs(packageJsonPath)
+			if err != nil {
+				problems = append(problems, ValidationProblem{packageJsonPath, fmt.Sprintf("cannot load config - %s", err)})
+				continue
+			}
+			for _, config := range configs {
+				err = config.Package.CheckPrerequisites(nil)
+				if err != nil {
+					problems = append(problems, ValidationProblem{packageJsonPath, fmt.Sprintf("invalid package - %s", err)})
+				}
+				dirName := filepath.Base(filepath.Dir(packageJsonPath))
+				if config.Package.Name != dirName {
+					problems = append(problems, ValidationProblem{packageJsonPath,
+						fmt.Sprintf("directory name (%s) is different from package name (%s)", dirName, config.Package.Name)})
+				}
+				configsByName[config.Package.Name] = append(configsByName[config.Package.Name], loadedConfig{packageJsonPath, config})
+			}
+		}
+	}
+
+	for _, configs := range configsByName {
+		for _, loaded := range configs {
+			problems = append(problems, context.checkDockerfiles(loaded)...)
+			problems = append(problems, checkPatches(loaded)...)
+			problems = append(problems, checkDependencies(loaded, configsByName)...)
+		}
+		problems = append(problems, checkDebugReleaseRevision(configs)...)
+		problems = append(problems, checkDuplicateVersions(configs)...)
+	}
+
+	problems = removeDuplicateProblems(problems)
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Path < problems[j].Path
+	})
+	return problems, nil
+}
+
+// removeDuplicateProblems
+// Removes problems which are reported more than once, e.g. for each build type of the same
+// definition. The order of the problems is preserved.
+func removeDuplicateProblems(problems []ValidationProblem) []ValidationProblem {
+	seen := map[ValidationProblem]struct{}{}
+	var uniqueProblems []ValidationProblem
+	for _, problem := range problems {
+		_, found := seen[problem]
+		if found {
+			continue
+		}
+		seen[problem] = struct{}{}
+		uniqueProblems = append(uniqueProblems, problem)
+	}
+	return uniqueProblems
+}
+
+// checkDockerfiles
+// Checks that Dockerfiles of all images from DockerMatrix of the loaded Config exist.
+func (context *ContextManager) checkDockerfiles(loaded loadedConfig) []ValidationProblem {
+	var problems []ValidationProblem
+	for _, imageName := range loaded.config.DockerMatrix.ImageNames {
+		_, err := context.GetImageDockerfilePath(imageName)
+		if err != nil {
+			problems = append(problems, ValidationProblem{loaded.path,
+				fmt.Sprintf("image '%s' from DockerMatrix is not valid - %s", imageName, err)})
+		}
+	}
+	return problems
+}
+
+// checkPatches
+// Checks that all patch files of the loaded Config exist.
+func checkPatches(loaded loadedConfig) []ValidationProblem {
+	var problems []ValidationProblem
+	for _, patchPath := range loaded.config.Patches {
+		info, err := os.Stat(patchPath)
+		if err != nil {
+			problems = append(problems, ValidationProblem{loaded.path, fmt.Sprintf("patch is not valid - %s", err)})
+		} else if !info.Mode().IsRegular() {
+			problems = append(problems, ValidationProblem{loaded.path, fmt.Sprintf("patch %s is not a file", patchPath)})
+		}
+	}
+	return problems
+}
+
+// checkDependencies
+// Checks that all Packages from DependsOn of the loaded Config exist, have a Config with the same
+// build type which satisfies the version constraint and that the selected version is built for
+// all images the loaded Config is built for.
+func checkDependencies(loaded loadedConfig, configsByName map[string][]loadedConfig) []ValidationProblem {
+	var problems []ValidationProblem
+	for _, dependency := range loaded.config.GetDependencies() {
+		dep := dependency.Name
+		depConfigs, found := configsByName[dep]
+		if !found {
+			problems = append(problems, ValidationProblem{loaded.path, fmt.Sprintf("unknown dependency '%s'", dep)})
+			continue
+		}
+		var candidates []*bringauto_config.Config
+		for _, depConfig := range depConfigs {
+			candidates = append(candidates, depConfig.config)
+		}
+		resolvedConfigs, err := SelectDependency(loaded.config, dependency, candidates)
+		if err != nil {
+			problems = append(problems, ValidationProblem{loaded.path, err.Error()})
+			continue
+		}
+		if len(resolvedConfigs) == 0 {
+			problems = append(problems, ValidationProblem{loaded.path,
+				fmt.Sprintf("dependency '%s' does not have config with the same build type", dep)})
+			continue
+		}
+		var depImages []string
+		for _, depConfig := range resolvedConfigs {
+			depImages = append(depImages, depConfig.DockerMatrix.ImageNames...)
+		}
+		var missingImages []string
+		for _, imageName := range loaded.config.DockerMatrix.ImageNames {
+			if !slices.Contains(depImages, imageName) {
+				missingImages = append(missingImages, imageName)
+			}
+		}
+		if len(missingImages) > 0 {
+			problems = append(problems, ValidationProblem{loaded.path,
+				fmt.Sprintf("dependency '%s' is not built for images: %s", dep, strings.Join(missingImages, ", "))})
+		}
+	}
+	return problems
+}
+
+// checkDebugReleaseRevision
+// Checks that Debug and Release Configs of one Package with the same VersionTag are built from
+// the same source revision (Git Revision, tarball checksum or local directory).
+func checkDebugReleaseRevision(configs []loadedConfig) []ValidationProblem {
+	var problems []ValidationProblem
+	for _, debugConfig := range configs {
+		if !debugConfig.config.Package.IsDebug {
+			continue
+		}
+		for _, releaseConfig := range configs {
+			if releaseConfig.config.Package.IsDebug ||
+				releaseConfig.config.Package.VersionTag != debugConfig.config.Package.VersionTag {
+				continue
+			}
+			debugRevision := debugConfig.config.GetSourceRevision()
+			releaseRevision := releaseConfig.config.GetSourceRevision()
+			if debugRevision != releaseRevision {
+				problems = append(problems, ValidationProblem{debugConfig.path,
+					fmt.Sprintf("source Revision '%s' differs from Revision '%s' of release config %s",
+						debugRevision, releaseRevision, releaseConfig.path)})
+			}
+		}
+	}
+	return problems
+}
+
+// checkDuplicateVersions
+// Checks that there are no two Configs of one Package with the same VersionTag and build type
+// built for the same image. Such Configs would produce the same Package.
+func checkDuplicateVersions(configs []loadedConfig) []ValidationProblem {
+	var problems []ValidationProblem
+	for i, loaded := range configs {
+		for _, previous := range configs[:i] {
+			if previous.config.Package.VersionTag != loaded.config.Package.VersionTag ||
+				previous.config.Package.IsDebug != loaded.config.Package.IsDebug {
+				continue
+			}
+			for _, imageName := range loaded.config.DockerMatrix.ImageNames {
+				if slices.Contains(previous.config.DockerMatrix.ImageNames, imageName) {
+					problems = append(problems, ValidationProblem{loaded.path,
+						fmt.Sprintf("package version %s for image '%s' is already defined by %s",
+							loaded.config.Package.VersionTag, imageName, previous.path)})
+					break
+				}
+			}
+		}
+	}
+	return problems
+}
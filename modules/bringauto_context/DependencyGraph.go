@@ -0,0 +1,128 @@
+package bringauto_context
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// DependencyGraph
+// Graph of dependencies between Packages in the Context. Debug and Release Configs of the same
+// Package are represented by one node.
+type DependencyGraph struct {
+	// DependsOn maps Package name to sorted names of Packages it depends on
+	DependsOn map[string][]string
+}
+
+// GetDependencyGraph
+// Returns dependency graph of all Packages in the Context. If imageName is not empty, only
+// Packages built for imageName (and Packages they depend on) are in the graph.
+func (context *ContextManager) GetDependencyGraph(imageName string) (*DependencyGraph, error) {
+	packConfigs, err := context.GetAllPackagesConfigs(nil)
+	if err != nil {
+		return nil, err
+	}
+	graph := DependencyGraph{
+		DependsOn: map[string][]string{},
+	}
+	for _, config := range packConfigs {
+		if imageName != "" && !slices.Contains(config.DockerMatrix.ImageNames, imageName) {
+			continue
+		}
+		graph.addNode(config.Package.Name)
+		for _, dep := range config.GetDependsOnNames() {
+			graph.addNode(dep)
+			if !slices.Contains(graph.DependsOn[config.Package.Name], dep) {
+				graph.DependsOn[config.Package.Name] = append(graph.DependsOn[config.Package.Name], dep)
+			}
+		}
+	}
+	for _, deps := range graph.DependsOn {
+		sort.Strings(deps)
+	}
+	return &graph, nil
+}
+
+// GetNodes
+// Returns sorted names of all Packages in the graph.
+func (graph *DependencyGraph) GetNodes() []string {
+	var nodes []string
+	for node := range graph.DependsOn {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// GetDepsSubgraph
+// Returns subgraph with packageName and all Packages it depends on recursively.
+func (graph *DependencyGraph) GetDepsSubgraph(packageName string) (*DependencyGraph, error) {
+	_, found := graph.DependsOn[packageName]
+	if !found {
+		return nil, fmt.Errorf("package '%s' is not in the dependency graph", packageName)
+	}
+	return graph.subgraph(graph.collectReachable(packageName, graph.DependsOn)), nil
+}
+
+// GetDepsOnSubgraph
+// Returns subgraph with packageName and all Packages which depends on it recursively.
+func (graph *DependencyGraph) GetDepsOnSubgraph(packageName string) (*DependencyGraph, error) {
+	_, found := graph.DependsOn[packageName]
+	if !found {
+		return nil, fmt.Errorf("package '%s' is not in the dependency graph", packageName)
+	}
+	reversed := map[string][]string{}
+	for node, deps := range graph.DependsOn {
+		for _, dep := range deps {
+			reversed[dep] = append(reversed[dep], node)
+		}
+	}
+	return graph.subgraph(graph.collectReachable(packageName, reversed)), nil
+}
+
+// addNode
+// Adds Package to the graph if it is not in the graph yet.
+func (graph *DependencyGraph) addNode(packageName string) {
+	_, found := graph.DependsOn[packageName]
+	if !found {
+		graph.DependsOn[packageName] = []string{}
+	}
+}
+
+// collectReachable
+// Returns set of nodes reachable from root (including root) through edges.
+func (graph *DependencyGraph) collectReachable(root string, edges map[string][]string) map[string]struct{} {
+	reachable := map[string]struct{}{root: {}}
+	stack := []string{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, next := range edges[node] {
+			_, visited := reachable[next]
+			if visited {
+				continue
+			}
+			reachable[next] = struct{}{}
+			stack = append(stack, next)
+		}
+	}
+	return reachable
+}
+
+// subgraph
+// Returns subgraph which contains only given nodes and edges between them.
+func (graph *DependencyGraph) subgraph(nodes map[string]struct{}) *DependencyGraph {
+	subgraph := DependencyGraph{
+		DependsOn: map[string][]string{},
+	}
+	for node := range nodes {
+		subgraph.addNode(node)
+		for _, dep := range graph.DependsOn[node] {
+			_, found := nodes[dep]
+			if found {
+				subgraph.DependsOn[node] = append(subgraph.DependsOn[node], dep)
+			}
+		}
+	}
+	return &subgraph
+}
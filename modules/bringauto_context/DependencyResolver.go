@@ -0,0 +1,167 @@
+package bringauto_context
+
+import (
+	"bringauto/modules/bringauto_config"
+	"bringauto/modules/bringauto_package"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// versionRequirement
+// Version constraint of one dependent Package on the dependency.
+type versionRequirement struct {
+	dependent  *bringauto_config.Config
+	dependency bringauto_config.Dependency
+}
+
+// ResolveDependency
+// Returns Configs from candidates which have the same build type as the dependent Config and
+// whose VersionTag satisfies the version constraint of the dependency. Returns error explaining
+// the conflict if there are Configs with the same build type, but none of them satisfies the
+// constraint. Returns empty list if there is no Config with the same build type.
+func ResolveDependency(
+	dependent  *bringauto_config.Config,
+	dependency bringauto_config.Dependency,
+	candidates []*bringauto_config.Config,
+) ([]*bringauto_config.Config, error) {
+	var resolved []*bringauto_config.Config
+	var versions []string
+	for _, candidate := range candidates {
+		if candidate.Package.Name != dependency.Name || candidate.Package.IsDebug != dependent.Package.IsDebug {
+			continue
+		}
+		if !slices.Contains(versions, candidate.Package.VersionTag) {
+			versions = append(versions, candidate.Package.VersionTag)
+		}
+		if dependency.IsSatisfiedBy(candidate.Package.VersionTag) {
+			resolved = append(resolved, candidate)
+		}
+	}
+	if len(resolved) == 0 && len(versions) > 0 {
+		return nil, fmt.Errorf("package %s requires %s, but the context provides only %s %s (%s)",
+			dependent.Package.Name, dependency, dependency.Name, strings.Join(versions, ", "),
+			buildTypeName(dependent.Package.IsDebug))
+	}
+	return resolved, nil
+}
+
+// SelectDependency
+// Returns Configs of the dependency version which the dependent Config gets - the highest version
+// from candidates with the same build type which satisfies the version constraint of the
+// dependency. Returns error if there are Configs with the same build type, but none of them
+// satisfies the constraint. Returns empty list if there is no Config with the same build type.
+func SelectDependency(
+	dependent  *bringauto_config.Config,
+	dependency bringauto_config.Dependency,
+	candidates []*bringauto_config.Config,
+) ([]*bringauto_config.Config, error) {
+	resolved, err := ResolveDependency(dependent, dependency, candidates)
+	if err != nil || len(resolved) == 0 {
+		return resolved, err
+	}
+	selectedVersion := resolved[0].Package.VersionTag
+	for _, config := range resolved {
+		if bringauto_package.CompareVersionTags(config.Package.VersionTag, selectedVersion) > 0 {
+			selectedVersion = config.Package.VersionTag
+		}
+	}
+	var selected []*bringauto_config.Config
+	for _, config := range resolved {
+		if config.Package.VersionTag == selectedVersion {
+			selected = append(selected, config)
+		}
+	}
+	return selected, nil
+}
+
+// CheckVersionConflicts
+// Checks that version constraints of all configs can be satisfied - for each dependency with
+// the version constraint there is a version of the dependency in configs which satisfies it.
+// Different dependents may get different versions of the same Package. Dependencies which are not
+// in configs are not checked. Returned error lists all requirements on each conflicting Package.
+func CheckVersionConflicts(configs []*bringauto_config.Config) error {
+	requirements := map[string][]versionRequirement{}
+	for _, config := range configs {
+		for _, dependency := range config.GetDependencies() {
+			if dependency.Constraint == nil {
+				continue
+			}
+			key := dependencyKey(dependency.Name, config.Package.IsDebug)
+			requirements[key] = append(requirements[key], versionRequirement{config, dependency})
+		}
+	}
+
+	var conflicts []string
+	for key, keyRequirements := range requirements {
+		var versions []string
+		for _, config := range configs {
+			if dependencyKey(config.Package.Name, config.Package.IsDebug) == key &&
+				!slices.Contains(versions, config.Package.VersionTag) {
+				versions = append(versions, config.Package.VersionTag)
+			}
+		}
+		if len(versions) == 0 || !slices.ContainsFunc(keyRequirements, func(requirement versionRequirement) bool {
+			return !satisfiesAny(requirement, versions)
+		}) {
+			continue
+		}
+		slices.SortFunc(versions, bringauto_package.CompareVersionTags)
+		conflicts = append(conflicts, describeConflict(keyRequirements, versions))
+	}
+	if len(conflicts) > 0 {
+		slices.Sort(conflicts)
+		return fmt.Errorf("version conflict of dependencies:\n%s", strings.Join(conflicts, "\n"))
+	}
+	return nil
+}
+
+// satisfiesAny
+// Returns true if any of versions satisfies the requirement, else returns false.
+func satisfiesAny(requirement versionRequirement, versions []string) bool {
+	return slices.ContainsFunc(versions, requirement.dependency.IsSatisfiedBy)
+}
+
+// describeConflict
+// Returns description of the conflict - available versions of the Package and requirements of
+// all dependent Packages together with versions which satisfy them.
+func describeConflict(requirements []versionRequirement, versions []string) string {
+	dependency := requirements[0].dependency
+	lines := []string{
+		fmt.Sprintf("  requirements on %s (%s) cannot be satisfied, available versions: %s",
+			dependency.Name, buildTypeName(requirements[0].dependent.Package.IsDebug), strings.Join(versions, ", ")),
+	}
+	var requirementLines []string
+	for _, requirement := range requirements {
+		var satisfiedBy []string
+		for _, version := range versions {
+			if requirement.dependency.IsSatisfiedBy(version) {
+				satisfiedBy = append(satisfiedBy, version)
+			}
+		}
+		satisfiedString := "not satisfied by any version"
+		if len(satisfiedBy) > 0 {
+			satisfiedString = "satisfied by " + strings.Join(satisfiedBy, ", ")
+		}
+		requirementLines = append(requirementLines, fmt.Sprintf("    %s %s requires %s - %s",
+			requirement.dependent.Package.Name, requirement.dependent.Package.VersionTag,
+			requirement.dependency, satisfiedString))
+	}
+	slices.Sort(requirementLines)
+	return strings.Join(append(lines, requirementLines...), "\n")
+}
+
+// dependencyKey
+// Returns key which identifies the Package with the build type.
+func dependencyKey(packageName string, isDebug bool) string {
+	return packageName + ":" + buildTypeName(isDebug)
+}
+
+// buildTypeName
+// Returns name of the build type.
+func buildTypeName(isDebug bool) string {
+	if isDebug {
+		return "debug"
+	}
+	return "release"
+}
@@ -1,10 +1,12 @@
 package bringauto_context
 
 import (
+	"bringauto/modules/bringauto_config"
 	"bringauto/modules/bringauto_const"
 	"bringauto/modules/bringauto_package"
 	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 	"os"
 )
@@ -15,10 +17,22 @@ const (
 	Set2DirName = "set2"
 	Set3DirName = "set3"
 	Set4DirName = "set4"
+	Set5DirName = "set5"
+	Set6DirName = "set6"
+	Set7DirName = "set7"
+	Set8DirName = "set8"
+	Set9DirName = "set9"
+	Set10DirName = "set10"
 	Set1DirPath = TestDataDirName + "/" + Set1DirName
 	Set2DirPath = TestDataDirName + "/" + Set2DirName
 	Set3DirPath = TestDataDirName + "/" + Set3DirName
 	Set4DirPath = TestDataDirName + "/" + Set4DirName
+	Set5DirPath = TestDataDirName + "/" + Set5DirName
+	Set6DirPath = TestDataDirName + "/" + Set6DirName
+	Set7DirPath = TestDataDirName + "/" + Set7DirName
+	Set8DirPath = TestDataDirName + "/" + Set8DirName
+	Set9DirPath = TestDataDirName + "/" + Set9DirName
+	Set10DirPath = TestDataDirName + "/" + Set10DirName
 
 	Pack1Name = "pack1"
 	Pack2Name = "pack2"
@@ -290,3 +304,494 @@ func TestGetDepsOnJsonDefPathsRecursivelyCircularDependency(t *testing.T) {
 		t.Fatalf("wrong returned paths - %s", paths)
 	}
 }
+
+func TestGetDependencyGraph(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set2DirPath,
+	}
+
+	graph, err := context.GetDependencyGraph("")
+	if err != nil {
+		t.Fatalf("GetDependencyGraph failed - %s", err)
+	}
+
+	nodes := graph.GetNodes()
+	if !slices.Equal(nodes, []string{Pack1Name, Pack2Name, Pack3Name, Pack4Name, Pack5Name, Pack6Name}) {
+		t.Fatalf("wrong graph nodes - %s", nodes)
+	}
+	if !slices.Equal(graph.DependsOn[Pack3Name], []string{Pack2Name, Pack4Name}) {
+		t.Fatalf("wrong dependencies of %s - %s", Pack3Name, graph.DependsOn[Pack3Name])
+	}
+	if len(graph.DependsOn[Pack2Name]) != 0 {
+		t.Fatalf("wrong dependencies of %s - %s", Pack2Name, graph.DependsOn[Pack2Name])
+	}
+}
+
+func TestGetDependencyGraphSubgraphs(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set2DirPath,
+	}
+
+	graph, err := context.GetDependencyGraph("")
+	if err != nil {
+		t.Fatalf("GetDependencyGraph failed - %s", err)
+	}
+
+	depsGraph, err := graph.GetDepsSubgraph(Pack3Name)
+	if err != nil {
+		t.Fatalf("GetDepsSubgraph failed - %s", err)
+	}
+	nodes := depsGraph.GetNodes()
+	if !slices.Equal(nodes, []string{Pack1Name, Pack2Name, Pack3Name, Pack4Name}) {
+		t.Fatalf("wrong deps subgraph nodes - %s", nodes)
+	}
+
+	depsOnGraph, err := graph.GetDepsOnSubgraph(Pack1Name)
+	if err != nil {
+		t.Fatalf("GetDepsOnSubgraph failed - %s", err)
+	}
+	nodes = depsOnGraph.GetNodes()
+	if !slices.Equal(nodes, []string{Pack1Name, Pack3Name, Pack4Name, Pack5Name, Pack6Name}) {
+		t.Fatalf("wrong deps on subgraph nodes - %s", nodes)
+	}
+	if !slices.Equal(depsOnGraph.DependsOn[Pack3Name], []string{Pack4Name}) {
+		t.Fatalf("wrong dependencies of %s - %s", Pack3Name, depsOnGraph.DependsOn[Pack3Name])
+	}
+
+	_, err = graph.GetDepsSubgraph("unknown")
+	if err == nil {
+		t.Error("GetDepsSubgraph didn't returned error for unknown package")
+	}
+}
+
+func TestGetDependencyGraphImage(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set2DirPath,
+	}
+
+	graph, err := context.GetDependencyGraph(Image2Name)
+	if err != nil {
+		t.Fatalf("GetDependencyGraph failed - %s", err)
+	}
+	if len(graph.DependsOn[Pack1Name]) != 0 {
+		t.Fatalf("package not built for image has dependencies - %s", graph.DependsOn[Pack1Name])
+	}
+	if !slices.Equal(graph.DependsOn[Pack6Name], []string{Pack1Name, Pack5Name}) {
+		t.Fatalf("wrong dependencies of %s - %s", Pack6Name, graph.DependsOn[Pack6Name])
+	}
+}
+
+func TestValidateContext(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set5DirPath,
+	}
+
+	problems, err := context.ValidateContext()
+	if err != nil {
+		t.Fatalf("ValidateContext failed - %s", err)
+	}
+
+	commonPath := filepath.Join(Set5DirPath, bringauto_const.PackageDirName)
+	pack1DebugPath := filepath.Join(commonPath, Pack1Name, Pack1Name + "_debug.json")
+	pack2Path := filepath.Join(commonPath, Pack2Name, Pack2Name + ".json")
+	pack3Path := filepath.Join(commonPath, Pack3Name, Pack3Name + ".json")
+
+	problemCounts := map[string]int{}
+	for _, problem := range problems {
+		problemCounts[problem.Path]++
+	}
+	if (len(problems) != 6 ||
+		problemCounts[pack1DebugPath] != 1 ||
+		problemCounts[pack2Path] != 3 ||
+		problemCounts[pack3Path] != 2) {
+		t.Fatalf("wrong returned problems - %s", problems)
+	}
+}
+
+func TestValidateContextNoDepWithBuildType(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set3DirPath,
+	}
+
+	problems, err := context.ValidateContext()
+	if err != nil {
+		t.Fatalf("ValidateContext failed - %s", err)
+	}
+
+	pack2Path := filepath.Join(Set3DirPath, bringauto_const.PackageDirName, Pack2Name, Pack2Name + ".json")
+	if len(problems) != 1 || problems[0].Path != pack2Path {
+		t.Fatalf("wrong returned problems - %s", problems)
+	}
+}
+
+func TestGetPackageWithDepsJsonDefPathsVersionConstraint(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set6DirPath,
+	}
+
+	_, err := context.GetPackageWithDepsJsonDefPaths(Pack1Name)
+	if err == nil || !strings.Contains(err.Error(), "pack1 requires pack2 >=1.2 <2") {
+		t.Errorf("unsatisfied version constraint not detected - %v", err)
+	}
+
+	paths, err := context.GetPackageWithDepsJsonDefPaths(Pack3Name)
+	if err != nil {
+		t.Fatalf("GetPackageWithDepsJsonDefPaths failed - %s", err)
+	}
+	if len(paths) != 2 {
+		t.Errorf("wrong returned paths - %s", paths)
+	}
+}
+
+func TestValidateContextVersionConstraint(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set6DirPath,
+	}
+
+	problems, err := context.ValidateContext()
+	if err != nil {
+		t.Fatalf("ValidateContext failed - %s", err)
+	}
+
+	pack1Path := filepath.Join(Set6DirPath, bringauto_const.PackageDirName, Pack1Name, Pack1Name + ".json")
+	if len(problems) != 1 || problems[0].Path != pack1Path {
+		t.Fatalf("wrong returned problems - %s", problems)
+	}
+}
+
+func TestCheckVersionConflicts(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set6DirPath,
+	}
+	configs, err := context.GetAllPackagesConfigs(&defaultPlatformString)
+	if err != nil {
+		t.Fatalf("GetAllPackagesConfigs failed - %s", err)
+	}
+
+	err = CheckVersionConflicts(configs)
+	if err == nil {
+		t.Fatal("version conflict not detected")
+	}
+	for _, line := range []string{
+		"requirements on pack2 (release) cannot be satisfied, available versions: v2.1.0",
+		"pack1 v1.0.0 requires pack2 >=1.2 <2 - not satisfied by any version",
+		"pack3 v1.0.0 requires pack2 ^2.0.0 - satisfied by v2.1.0",
+	} {
+		if !strings.Contains(err.Error(), line) {
+			t.Errorf("conflict explanation does not contain '%s':\n%s", line, err)
+		}
+	}
+
+	var satisfiedConfigs []*bringauto_config.Config
+	for _, config := range configs {
+		if config.Package.Name != Pack1Name {
+			satisfiedConfigs = append(satisfiedConfigs, config)
+		}
+	}
+	err = CheckVersionConflicts(satisfiedConfigs)
+	if err != nil {
+		t.Errorf("CheckVersionConflicts failed - %s", err)
+	}
+}
+
+func TestGetPackageWithDepsJsonDefPathsMultipleVersions(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set7DirPath,
+	}
+
+	commonPath := filepath.Join(Set7DirPath, bringauto_const.PackageDirName)
+	pack1Path := filepath.Join(commonPath, Pack1Name, Pack1Name + ".json")
+	pack2V1Path := filepath.Join(commonPath, Pack2Name, Pack2Name + "_v1.5.0.json")
+	pack2V2Path := filepath.Join(commonPath, Pack2Name, Pack2Name + ".json")
+	pack3Path := filepath.Join(commonPath, Pack3Name, Pack3Name + ".json")
+
+	paths, err := context.GetPackageWithDepsJsonDefPaths(Pack1Name)
+	if err != nil {
+		t.Fatalf("GetPackageWithDepsJsonDefPaths failed - %s", err)
+	}
+	if len(paths) != 2 || !slices.Contains(paths, pack1Path) || !slices.Contains(paths, pack2V1Path) {
+		t.Errorf("wrong returned paths - %s", paths)
+	}
+
+	paths, err = context.GetPackageWithDepsJsonDefPaths(Pack3Name)
+	if err != nil {
+		t.Fatalf("GetPackageWithDepsJsonDefPaths failed - %s", err)
+	}
+	if len(paths) != 2 || !slices.Contains(paths, pack3Path) || !slices.Contains(paths, pack2V2Path) {
+		t.Errorf("wrong returned paths - %s", paths)
+	}
+}
+
+func TestGetDepsOnJsonDefPathsMultipleVersions(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set7DirPath,
+	}
+
+	paths, err := context.GetDepsOnJsonDefPaths(Pack2Name, false)
+	if err != nil {
+		t.Fatalf("GetDepsOnJsonDefPaths failed - %s", err)
+	}
+
+	commonPath := filepath.Join(Set7DirPath, bringauto_const.PackageDirName)
+	pack1Path := filepath.Join(commonPath, Pack1Name, Pack1Name + ".json")
+	pack3Path := filepath.Join(commonPath, Pack3Name, Pack3Name + ".json")
+	if len(paths) != 2 || !slices.Contains(paths, pack1Path) || !slices.Contains(paths, pack3Path) {
+		t.Fatalf("wrong returned paths - %s", paths)
+	}
+}
+
+func TestGetDepsOnJsonDefPathsSelectedDependentVersions(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set10DirPath,
+	}
+
+	commonPath := filepath.Join(Set10DirPath, bringauto_const.PackageDirName)
+	pack2V1Path := filepath.Join(commonPath, Pack2Name, Pack2Name + "_v1.0.0.json")
+	pack2V15Path := filepath.Join(commonPath, Pack2Name, Pack2Name + "_v1.5.0.json")
+	pack2V2Path := filepath.Join(commonPath, Pack2Name, Pack2Name + ".json")
+	pack3Path := filepath.Join(commonPath, Pack3Name, Pack3Name + ".json")
+
+	// pack2 v2.0.0 does not depend on pack1
+	paths, err := context.GetDepsOnJsonDefPaths(Pack1Name, false)
+	if err != nil {
+		t.Fatalf("GetDepsOnJsonDefPaths failed - %s", err)
+	}
+	if len(paths) != 2 || !slices.Contains(paths, pack2V1Path) || !slices.Contains(paths, pack2V15Path) {
+		t.Errorf("wrong returned paths - %s", paths)
+	}
+
+	paths, err = context.GetDepsOnJsonDefPaths(Pack1Name, true)
+	if err != nil {
+		t.Fatalf("GetDepsOnJsonDefPaths failed - %s", err)
+	}
+	if len(paths) != 3 || !slices.Contains(paths, pack2V1Path) || !slices.Contains(paths, pack2V15Path) ||
+		!slices.Contains(paths, pack3Path) {
+		t.Errorf("wrong returned paths - %s", paths)
+	}
+
+	// pack3 selected pack2 v1.5.0, which does not depend on pack4
+	paths, err = context.GetDepsOnJsonDefPaths(Pack4Name, true)
+	if err != nil {
+		t.Fatalf("GetDepsOnJsonDefPaths failed - %s", err)
+	}
+	if len(paths) != 1 || paths[0] != pack2V2Path {
+		t.Errorf("wrong returned paths - %s", paths)
+	}
+}
+
+func TestSelectDependency(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set7DirPath,
+	}
+	configs, err := context.GetAllPackagesConfigs(&defaultPlatformString)
+	if err != nil {
+		t.Fatalf("GetAllPackagesConfigs failed - %s", err)
+	}
+
+	expectedVersions := map[string]string{
+		Pack1Name: "v1.5.0",
+		Pack3Name: "v2.1.0",
+	}
+	for _, config := range configs {
+		expectedVersion, found := expectedVersions[config.Package.Name]
+		if !found {
+			continue
+		}
+		selected, err := SelectDependency(config, config.GetDependencies()[0], configs)
+		if err != nil {
+			t.Fatalf("SelectDependency failed - %s", err)
+		}
+		if len(selected) != 1 || selected[0].Package.VersionTag != expectedVersion {
+			t.Errorf("wrong version of %s selected for %s", Pack2Name, config.Package.Name)
+		}
+	}
+
+	err = CheckVersionConflicts(configs)
+	if err != nil {
+		t.Errorf("CheckVersionConflicts failed - %s", err)
+	}
+}
+
+func TestValidateContextMultipleVersions(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set7DirPath,
+	}
+
+	problems, err := context.ValidateContext()
+	if err != nil {
+		t.Fatalf("ValidateContext failed - %s", err)
+	}
+	if len(problems) != 0 {
+		t.Fatalf("wrong returned problems - %s", problems)
+	}
+}
+
+func TestCheckDuplicateVersions(t *testing.T) {
+	newConfig := func(versionTag string, imageName string) *bringauto_config.Config {
+		config := bringauto_config.Config{}
+		config.Package.Name = Pack1Name
+		config.Package.VersionTag = versionTag
+		config.DockerMatrix.ImageNames = []string{imageName}
+		return &config
+	}
+	configs := []loadedConfig{
+		{"pack1.json", newConfig("v1.0.0", Image1Name)},
+		{"pack1_v2.json", newConfig("v2.0.0", Image1Name)},
+		{"pack1_image2.json", newConfig("v1.0.0", Image2Name)},
+		{"pack1_copy.json", newConfig("v1.0.0", Image1Name)},
+	}
+
+	problems := checkDuplicateVersions(configs)
+	if len(problems) != 1 || problems[0].Path != "pack1_copy.json" {
+		t.Fatalf("wrong returned problems - %s", problems)
+	}
+}
+
+func TestGetPackageWithRuntimeDepsJsonDefPaths(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set8DirPath,
+	}
+
+	commonPath := filepath.Join(Set8DirPath, bringauto_const.PackageDirName)
+	pack1Path := filepath.Join(commonPath, Pack1Name, Pack1Name + ".json")
+	pack2Path := filepath.Join(commonPath, Pack2Name, Pack2Name + ".json")
+	pack3Path := filepath.Join(commonPath, Pack3Name, Pack3Name + ".json")
+	pack4Path := filepath.Join(commonPath, Pack4Name, Pack4Name + ".json")
+
+	paths, err := context.GetPackageWithDepsJsonDefPaths(Pack1Name)
+	if err != nil {
+		t.Fatalf("GetPackageWithDepsJsonDefPaths failed - %s", err)
+	}
+	if (len(paths) != 4 ||
+		!slices.Contains(paths, pack1Path) ||
+		!slices.Contains(paths, pack2Path) ||
+		!slices.Contains(paths, pack3Path) ||
+		!slices.Contains(paths, pack4Path)) {
+		t.Errorf("wrong returned paths - %s", paths)
+	}
+
+	paths, err = context.GetPackageWithRuntimeDepsJsonDefPaths(Pack1Name)
+	if err != nil {
+		t.Fatalf("GetPackageWithRuntimeDepsJsonDefPaths failed - %s", err)
+	}
+	if (len(paths) != 3 ||
+		!slices.Contains(paths, pack1Path) ||
+		!slices.Contains(paths, pack2Path) ||
+		!slices.Contains(paths, pack4Path)) {
+		t.Errorf("wrong returned paths - %s", paths)
+	}
+}
+
+func TestGetDepsOnJsonDefPathsBuildDependencies(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set8DirPath,
+	}
+
+	pack1Path := filepath.Join(Set8DirPath, bringauto_const.PackageDirName, Pack1Name, Pack1Name + ".json")
+	for _, packName := range []string{Pack2Name, Pack3Name} {
+		paths, err := context.GetDepsOnJsonDefPaths(packName, false)
+		if err != nil {
+			t.Fatalf("GetDepsOnJsonDefPaths failed - %s", err)
+		}
+		if !slices.Contains(paths, pack1Path) {
+			t.Errorf("wrong returned paths for %s - %s", packName, paths)
+		}
+	}
+
+	paths, err := context.GetDepsOnJsonDefPaths(Pack4Name, false)
+	if err != nil {
+		t.Fatalf("GetDepsOnJsonDefPaths failed - %s", err)
+	}
+	if len(paths) != 0 {
+		t.Errorf("runtime dependency followed - %s", paths)
+	}
+}
+
+func TestGetSettings(t *testing.T) {
+	context := ContextManager{
+		ContextPath: Set1DirPath,
+	}
+	settings, err := context.GetSettings()
+	if err != nil {
+		t.Fatalf("GetSettings failed - %s", err)
+	}
+	if settings.PackageFormat != bringauto_package.FormatZip {
+		t.Errorf("wrong default package format - %s", settings.PackageFormat)
+	}
+
+	context.ContextPath = Set2DirPath
+	settings, err = context.GetSettings()
+	if err != nil {
+		t.Fatalf("GetSettings failed - %s", err)
+	}
+	if settings.PackageFormat != bringauto_package.FormatTarZst {
+		t.Errorf("wrong package format - %s", settings.PackageFormat)
+	}
+}
+
+func TestGetAllPackagesConfigsBuildTypes(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set9DirPath,
+	}
+
+	configs, err := context.GetAllPackagesConfigs(&defaultPlatformString)
+	if err != nil {
+		t.Fatalf("GetAllPackagesConfigs failed - %s", err)
+	}
+	var packageNames []string
+	for _, config := range configs {
+		packageNames = append(packageNames, config.Package.GetFullPackageName())
+	}
+	if len(packageNames) != 4 {
+		t.Fatalf("wrong number of configs - %s", packageNames)
+	}
+	for _, packageName := range []string{Pack1Name, Pack2Name} {
+		for _, isDebug := range []bool{false, true} {
+			pack := bringauto_package.Package{
+				Name:           packageName,
+				VersionTag:     "v1.0.0",
+				PlatformString: defaultPlatformString,
+				IsLibrary:      true,
+				IsDevLib:       true,
+				IsDebug:        isDebug,
+			}
+			if !slices.Contains(packageNames, pack.GetFullPackageName()) {
+				t.Errorf("config %s not expanded - %s", pack.GetFullPackageName(), packageNames)
+			}
+		}
+	}
+}
+
+func TestBuildTypesDepsJsonDefPaths(t *testing.T) {
+	context := ContextManager {
+		ContextPath: Set9DirPath,
+	}
+
+	commonPath := filepath.Join(Set9DirPath, bringauto_const.PackageDirName)
+	pack1Path := filepath.Join(commonPath, Pack1Name, Pack1Name + ".json")
+	pack2Path := filepath.Join(commonPath, Pack2Name, Pack2Name + ".json")
+
+	paths, err := context.GetPackageWithDepsJsonDefPaths(Pack1Name)
+	if err != nil {
+		t.Fatalf("GetPackageWithDepsJsonDefPaths failed - %s", err)
+	}
+	if len(paths) != 2 || !slices.Contains(paths, pack1Path) || !slices.Contains(paths, pack2Path) {
+		t.Errorf("wrong returned paths - %s", paths)
+	}
+
+	paths, err = context.GetDepsOnJsonDefPaths(Pack2Name, false)
+	if err != nil {
+		t.Fatalf("GetDepsOnJsonDefPaths failed - %s", err)
+	}
+	if !slices.Equal(paths, []string{pack1Path}) {
+		t.Errorf("wrong returned paths - %s", paths)
+	}
+
+	problems, err := context.ValidateContext()
+	if err != nil {
+		t.Fatalf("ValidateContext failed - %s", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("unexpected problems - %v", problems)
+	}
+}
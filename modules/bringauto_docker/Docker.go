@@ -3,14 +3,16 @@ package bringauto_docker
 import (
 	"bringauto/modules/bringauto_prerequisites"
 	"bringauto/modules/bringauto_process"
-	"bringauto/modules/bringauto_const"
 	"fmt"
 	"os"
 	"bytes"
+	"slices"
 )
 
 const (
 	defaultImageNameConst = "unknown"
+	// Port on which the sshd listens inside the container
+	containerSSHPortConst = 22
 )
 
 // Docker
@@ -20,6 +22,10 @@ type Docker struct {
 	// Ports mapping between host and container
 	// in manner map[int]int { <host>:<container> }
 	Ports map[int]int `json:"-"`
+	// PublishedPorts container ports published on an ephemeral port of the host loopback
+	// interface. The host port is chosen by docker when the container is started, use GetHostPort
+	// to get it
+	PublishedPorts []int `json:"-"`
 	// Volumes map a host directory (represented by absolute path)
 	// to the directory inside the docker container
 	// in manner map[string]string { <host_volume_abs_path>:<> }
@@ -38,9 +44,7 @@ func (docker *Docker) FillDefault(*bringauto_prerequisites.Args) error {
 		Volumes:     map[string]string{},
 		RunAsDaemon: true,
 		ImageName:   defaultImageNameConst,
-		Ports: map[int]int{
-			bringauto_const.DefaultSSHPort: 22,
-		},
+		Ports:       map[int]int{},
 	}
 	return nil
 }
@@ -58,11 +62,13 @@ func (docker *Docker) FillDynamic(args *bringauto_prerequisites.Args) error {
 // It checks if the docker is installed and can be run by given user.
 // Function returns nil if Docker installation is ok, not nil of the problem is recognized
 func (docker *Docker) CheckPrerequisites(*bringauto_prerequisites.Args) error {
-	portAvailable, err := IsDefaultPortAvailable()
-	if err != nil {
-		return err
-	} else if !portAvailable {
-		return fmt.Errorf("default port %d not available", bringauto_const.DefaultSSHPort)
+	for hostPort := range docker.Ports {
+		portAvailable, err := IsPortAvailable(hostPort)
+		if err != nil {
+			return err
+		} else if !portAvailable {
+			return fmt.Errorf("port %d not available", hostPort)
+		}
 	}
 	var outBuff bytes.Buffer
 	process := bringauto_process.Process{
@@ -76,7 +82,7 @@ func (docker *Docker) CheckPrerequisites(*bringauto_prerequisites.Args) error {
 		},
 		StdOut: &outBuff,
 	}
-	err = process.Run()
+	err := process.Run()
 	if err != nil {
 		return err
 	}
@@ -103,3 +109,25 @@ func (docker *Docker) SetVolume(hostDirectory string, containerDirectory string)
 	}
 	docker.Volumes[hostDirectory] = containerDirectory
 }
+
+// PublishSSHPort
+// Publishes the sshd port of the container on an ephemeral host port, so containers started at
+// the same time (also by other processes) never collide. Fixed mapping of the sshd port is removed.
+// The host port is returned by GetSSHPort after the container is started.
+func (docker *Docker) PublishSSHPort() {
+	for host, container := range docker.Ports {
+		if container == containerSSHPortConst {
+			delete(docker.Ports, host)
+		}
+	}
+	if !slices.Contains(docker.PublishedPorts, containerSSHPortConst) {
+		docker.PublishedPorts = append(docker.PublishedPorts, containerSSHPortConst)
+	}
+}
+
+// GetSSHPort
+// Returns the host port the sshd port of the running container is published on.
+func (docker *Docker) GetSSHPort() (uint16, error) {
+	port, err := docker.GetHostPort(containerSSHPortConst)
+	return uint16(port), err
+}
@@ -43,6 +43,20 @@ func (dockerImage *DockerImage) ImageExists() bool {
 	return false
 }
 
+// GetImageId
+// Returns ID of the docker image. The ID changes every time the image is rebuilt.
+func (dockerImage *DockerImage) GetImageId() (string, error) {
+	output, err := dockerImage.runDockerImageCommand([]string{"image", "inspect", "--format", "{{.Id}}", dockerImage.ImageName})
+	if err != nil {
+		return "", fmt.Errorf("cannot get ID of image '%s' - %s", dockerImage.ImageName, err)
+	}
+	imageId := strings.TrimSpace(output)
+	if imageId == "" {
+		return "", fmt.Errorf("image '%s' has empty ID", dockerImage.ImageName)
+	}
+	return imageId, nil
+}
+
 func (dockerImage *DockerImage) runDockerImageCommand(extraArgs []string) (string, error) {
 	var stdOut bytes.Buffer
 	process := bringauto_process.Process{
@@ -48,6 +48,9 @@ func (runArgs *DockerRun) GenerateCmdLine() ([]string, error) {
 		cmdArgs = append(cmdArgs, "-p")
 		cmdArgs = append(cmdArgs, portPair)
 	}
+	for _, port := range runArgs.PublishedPorts {
+		cmdArgs = append(cmdArgs, "-p", "127.0.0.1::"+strconv.Itoa(port))
+	}
 	for key, value := range runArgs.Volumes {
 		volumePair := key + ":" + value
 		cmdArgs = append(cmdArgs, "-v", volumePair)
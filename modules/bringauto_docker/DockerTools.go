@@ -1,17 +1,18 @@
 package bringauto_docker
 
 import (
-	"bringauto/modules/bringauto_const"
 	"bringauto/modules/bringauto_process"
 	"bytes"
 	"fmt"
+	"net"
 	"strconv"
+	"strings"
 )
 
-// IsDefaultPortAvailable
-// Returns true if default port for docker is available, else returns false.
-// When false is returned, the error contains message from the docker command.
-func IsDefaultPortAvailable() (bool, error) {
+// IsPortAvailable
+// Returns true if given host port is not published by any running docker container, else returns
+// false. When false is returned, the error contains message from the docker command.
+func IsPortAvailable(port int) (bool, error) {
 	var outBuff, errBuff bytes.Buffer
 
 	process := bringauto_process.Process{
@@ -21,7 +22,7 @@ func IsDefaultPortAvailable() (bool, error) {
 				"container",
 				"ls",
 				"--filter",
-				"publish=" + strconv.Itoa(bringauto_const.DefaultSSHPort),
+				"publish=" + strconv.Itoa(port),
 				"--format",
 				"{{.ID}}{{.Ports}}",
 			},
@@ -37,3 +38,44 @@ func IsDefaultPortAvailable() (bool, error) {
 
 	return outBuff.Len() == 0, nil
 }
+
+// GetHostPort
+// Returns the host port the containerPort (TCP) of the running container is published on. The
+// container must be started by DockerRun.
+func (docker *Docker) GetHostPort(containerPort int) (int, error) {
+	var outBuff, errBuff bytes.Buffer
+
+	process := bringauto_process.Process{
+		CommandAbsolutePath: DockerExecutablePathConst,
+		Args: bringauto_process.ProcessArgs{
+			ExtraArgs: &[]string{
+				"port",
+				docker.containerId,
+				strconv.Itoa(containerPort) + "/tcp",
+			},
+		},
+		StdOut: &outBuff,
+		StdErr: &errBuff,
+	}
+
+	err := process.Run()
+	if err != nil {
+		return 0, fmt.Errorf("cannot get host port of container port %d - %s", containerPort, errBuff.String())
+	}
+	return parseHostPort(outBuff.String())
+}
+
+// parseHostPort
+// Returns the port from the first line of the 'docker port' output (e.g. 127.0.0.1:49153).
+func parseHostPort(output string) (int, error) {
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	_, portString, err := net.SplitHostPort(strings.TrimSpace(firstLine))
+	if err != nil {
+		return 0, fmt.Errorf("invalid docker port output '%s' - %s", output, err)
+	}
+	port, err := strconv.Atoi(portString)
+	if err != nil || port <= 0 {
+		return 0, fmt.Errorf("invalid docker port output '%s'", output)
+	}
+	return port, nil
+}
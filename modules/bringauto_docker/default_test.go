@@ -88,6 +88,20 @@ func TestDockerRun_GenerateCmdLine(t *testing.T) {
 		return
 	}
 
+	dockerRun.PublishedPorts = []int{22}
+	validCmdLine = validCmdLine[:len(validCmdLine)-1]
+	validCmdLine = append(validCmdLine, "-p", "127.0.0.1::22", dockerRun.ImageName)
+	cmdLine, err = dockerRun.GenerateCmdLine()
+	if err != nil {
+		t.Errorf("cannot generate reference cmd line")
+		return
+	}
+	cmdLineValid = reflect.DeepEqual(cmdLine, validCmdLine)
+	if !cmdLineValid {
+		t.Errorf("invalid Docker Run cmd line with published ports!")
+		return
+	}
+
 	dockerRun.Volumes = map[string]string{
 		"A": "A",
 		"B": "BVol",
@@ -105,3 +119,21 @@ func TestDockerRun_GenerateCmdLine(t *testing.T) {
 		return
 	}
 }
+
+func TestDocker_PublishSSHPort(t *testing.T) {
+	docker := bringauto_prerequisites.CreateAndInitialize[bringauto_docker.Docker]()
+	docker.Ports = map[int]int{
+		1122: 22,
+		8080: 80,
+	}
+
+	docker.PublishSSHPort()
+	docker.PublishSSHPort()
+
+	if !reflect.DeepEqual(docker.Ports, map[int]int{8080: 80}) {
+		t.Errorf("fixed mapping of the SSH port is not removed: %v", docker.Ports)
+	}
+	if !reflect.DeepEqual(docker.PublishedPorts, []int{22}) {
+		t.Errorf("SSH port is not published exactly once: %v", docker.PublishedPorts)
+	}
+}
@@ -2,6 +2,7 @@ package bringauto_git
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -23,10 +24,25 @@ type GitSubmoduleUpdate struct {
 	Git
 }
 
+// GitCheckRevision stops the build if the Revision is a branch. Branches can move, so the
+// build of the same Revision could produce different results.
+type GitCheckRevision struct {
+	Git
+}
+
+// GitRevParse prints hash of the checked out commit prefixed by Prefix. If the Revision is a full
+// commit hash, the build is stopped if the checked out commit is different.
+type GitRevParse struct {
+	Git
+	Prefix string
+}
+
 const (
 	GitExecutablePath = "git"
 )
 
+var fullHashRegexp = regexp.MustCompile("^([0-9a-fA-F]{40}|[0-9a-fA-F]{64})$")
+
 func (args *GitClone) ConstructCMDLine() []string {
 	validateGITPath(args.ClonePath)
 	cmd := []string{
@@ -48,7 +64,7 @@ func (args *GitCheckout) ConstructCMDLine() []string {
 	}
 	return []string{
 		"pushd " + args.ClonePath,
-		strings.Join(cmd, " "),
+		strings.Join(cmd, " ") + " || exit 1",
 		"popd",
 	}
 }
@@ -74,3 +90,42 @@ func validateGITPath(path string) {
 		panic(fmt.Errorf("git path is empty"))
 	}
 }
+
+func (args *GitCheckRevision) ConstructCMDLine() []string {
+	validateGITPath(args.ClonePath)
+	cmd := []string{
+		GitExecutablePath,
+		"show-ref",
+		"--verify",
+		"--quiet",
+		"\"refs/remotes/origin/" + args.Revision + "\"",
+	}
+	return []string{
+		"pushd " + args.ClonePath,
+		"if " + strings.Join(cmd, " ") + "; then echo \"Revision '" + args.Revision +
+			"' is a branch, use a tag or a commit hash\"; exit 1; fi",
+		"popd",
+	}
+}
+
+func (args *GitRevParse) ConstructCMDLine() []string {
+	validateGITPath(args.ClonePath)
+	cmd := []string{
+		GitExecutablePath,
+		"rev-parse",
+		"HEAD",
+	}
+	cmdLine := []string{
+		"pushd " + args.ClonePath,
+		"commit=$(" + strings.Join(cmd, " ") + ") || exit 1",
+	}
+	if fullHashRegexp.MatchString(args.Revision) {
+		revision := strings.ToLower(args.Revision)
+		cmdLine = append(cmdLine, "if [ \"$commit\" != \""+revision+"\" ]; then echo \"Checked out commit "+
+			"$commit is not Revision "+revision+"\"; exit 1; fi")
+	}
+	return append(cmdLine,
+		"echo \"" + args.Prefix + "$commit\"",
+		"popd",
+	)
+}
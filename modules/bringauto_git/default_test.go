@@ -39,7 +39,7 @@ func TestGitCheckout_ConstructCMDLine(t *testing.T) {
 	}
 	validCmdLine := []string{
 		"pushd " + git.ClonePath,
-		strings.Join(gitCmdLine, " "),
+		strings.Join(gitCmdLine, " ") + " || exit 1",
 		"popd",
 	}
 	cmdLineValid := reflect.DeepEqual(cmdLine, validCmdLine)
@@ -71,3 +71,49 @@ func TestGitSubmoduleUpdate_ConstructCMDLine(t *testing.T) {
 		t.Errorf("git update CMD line is not valid!")
 	}
 }
+
+func TestGitCheckRevision_ConstructCMDLine(t *testing.T) {
+	git := bringauto_git.GitCheckRevision{}
+	git.Revision = "master"
+	git.ClonePath = "local"
+	cmdLine := git.ConstructCMDLine()
+	validCmdLine := []string{
+		"pushd " + git.ClonePath,
+		"if git show-ref --verify --quiet \"refs/remotes/origin/master\"; " +
+			"then echo \"Revision 'master' is a branch, use a tag or a commit hash\"; exit 1; fi",
+		"popd",
+	}
+	if !reflect.DeepEqual(cmdLine, validCmdLine) {
+		t.Errorf("git check revision CMD line is not valid! %s", cmdLine)
+	}
+}
+
+func TestGitRevParse_ConstructCMDLine(t *testing.T) {
+	git := bringauto_git.GitRevParse{Prefix: "commit "}
+	git.Revision = "v1.0.0"
+	git.ClonePath = "local"
+	validCmdLine := []string{
+		"pushd " + git.ClonePath,
+		"commit=$(git rev-parse HEAD) || exit 1",
+		"echo \"commit $commit\"",
+		"popd",
+	}
+	cmdLine := git.ConstructCMDLine()
+	if !reflect.DeepEqual(cmdLine, validCmdLine) {
+		t.Errorf("git rev-parse CMD line is not valid! %s", cmdLine)
+	}
+
+	git.Revision = "0123456789ABCDEF0123456789abcdef01234567"
+	validCmdLine = []string{
+		"pushd " + git.ClonePath,
+		"commit=$(git rev-parse HEAD) || exit 1",
+		"if [ \"$commit\" != \"0123456789abcdef0123456789abcdef01234567\" ]; then echo \"Checked out commit " +
+			"$commit is not Revision 0123456789abcdef0123456789abcdef01234567\"; exit 1; fi",
+		"echo \"commit $commit\"",
+		"popd",
+	}
+	cmdLine = git.ConstructCMDLine()
+	if !reflect.DeepEqual(cmdLine, validCmdLine) {
+		t.Errorf("git rev-parse CMD line with full hash is not valid! %s", cmdLine)
+	}
+}
@@ -73,6 +73,11 @@ func (logger *ContextLogger) CheckPrerequisites(*bringauto_prerequisites.Args) e
 // Returns writable file for writing logs for specified context of a package. The file must be
 // closed by the caller.
 func (logger *ContextLogger) GetFile() (*os.File, error) {
-	filepath := filepath.Join(logger.logDirPath, logger.logFileName)
-	return os.OpenFile(filepath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	return os.OpenFile(logger.GetFilePath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+}
+
+// GetFilePath
+// Returns path of the log file for specified context of a package.
+func (logger *ContextLogger) GetFilePath() string {
+	return filepath.Join(logger.logDirPath, logger.logFileName)
 }
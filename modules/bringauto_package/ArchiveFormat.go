@@ -0,0 +1,104 @@
+package bringauto_package
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mholt/archiver/v3"
+)
+
+// ArchiveFormat is a format of the Package archive.
+type ArchiveFormat string
+
+const (
+	// FormatZip zip archive, the default format
+	FormatZip ArchiveFormat = "zip"
+	// FormatTarGz tar archive compressed by gzip
+	FormatTarGz ArchiveFormat = "tar.gz"
+	// FormatTarZst tar archive compressed by Zstandard
+	FormatTarZst ArchiveFormat = "tar.zst"
+)
+
+const (
+	TarGzExt  = ".tar.gz"
+	TarZstExt = ".tar.zst"
+)
+
+// ArchiveFormats
+// Returns all supported archive formats.
+func ArchiveFormats() []ArchiveFormat {
+	return []ArchiveFormat{FormatZip, FormatTarGz, FormatTarZst}
+}
+
+// ParseArchiveFormat
+// Returns ArchiveFormat represented by the format string. Empty string represents FormatZip.
+func ParseArchiveFormat(format string) (ArchiveFormat, error) {
+	if format == "" {
+		return FormatZip, nil
+	}
+	for _, archiveFormat := range ArchiveFormats() {
+		if string(archiveFormat) == format {
+			return archiveFormat, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported package format '%s' (supported: zip, tar.gz, tar.zst)", format)
+}
+
+// GetArchiveFormat
+// Returns ArchiveFormat of the archive based on the extension of archivePath.
+func GetArchiveFormat(archivePath string) (ArchiveFormat, error) {
+	for _, archiveFormat := range ArchiveFormats() {
+		if strings.HasSuffix(archivePath, archiveFormat.Ext()) {
+			return archiveFormat, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported package archive %s", archivePath)
+}
+
+// Ext
+// Returns file extension of the archive format (including the leading dot). Empty format is
+// FormatZip.
+func (format ArchiveFormat) Ext() string {
+	switch format {
+	case FormatTarGz:
+		return TarGzExt
+	case FormatTarZst:
+		return TarZstExt
+	default:
+		return ZipExt
+	}
+}
+
+// ExtractArchive
+// Extracts the Package archive to the outputDir. The format is determined by the extension of
+// archivePath. Existing files are not overwritten.
+func ExtractArchive(archivePath string, outputDir string) error {
+	archiveFormat, err := GetArchiveFormat(archivePath)
+	if err != nil {
+		return err
+	}
+	var unarchiver archiver.Unarchiver
+	switch archiveFormat {
+	case FormatTarGz:
+		unarchiver = &archiver.TarGz{
+			Tar: &archiver.Tar{
+				MkdirAll:          true,
+				OverwriteExisting: false,
+			},
+		}
+	case FormatTarZst:
+		unarchiver = &archiver.TarZstd{
+			Tar: &archiver.Tar{
+				MkdirAll:          true,
+				OverwriteExisting: false,
+			},
+		}
+	default:
+		unarchiver = &archiver.Zip{
+			MkdirAll:             true,
+			OverwriteExisting:    false,
+			SelectiveCompression: true,
+		}
+	}
+	return unarchiver.Unarchive(archivePath, outputDir)
+}
@@ -0,0 +1,221 @@
+package bringauto_package
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// Content of the debian-binary member of the Debian package
+	debBinaryVersionConst = "2.0\n"
+	// Magic string at the start of the ar archive
+	arMagicConst = "!<arch>\n"
+)
+
+// createDebPackage
+// Creates Debian package at archivePath. The package is an ar archive with the debian-binary,
+// control.tar.gz and data.tar.gz members.
+func (packg *Package) createDebPackage(entries []nativeEntry, archivePath string) error {
+	buildTime := packg.getNativeBuildTime()
+	data, md5sums, installedSize, err := packg.createDebData(entries)
+	if err != nil {
+		return err
+	}
+	control, err := packg.createDebControl(md5sums, installedSize, buildTime)
+	if err != nil {
+		return err
+	}
+
+	var archive bytes.Buffer
+	archive.WriteString(arMagicConst)
+	members := []struct {
+		name    string
+		content []byte
+	}{
+		{"debian-binary", []byte(debBinaryVersionConst)},
+		{"control.tar.gz", control},
+		{"data.tar.gz", data},
+	}
+	for _, member := range members {
+		fmt.Fprintf(&archive, "%-16s%-12d%-6d%-6d%-8s%-10d`\n",
+			member.name, buildTime.Unix(), 0, 0, "100644", len(member.content))
+		archive.Write(member.content)
+		if len(member.content)%2 != 0 {
+			archive.WriteByte('\n')
+		}
+	}
+	return os.WriteFile(archivePath, archive.Bytes(), 0644)
+}
+
+// createDebData
+// Returns data.tar.gz of the Debian package with all entries and their parent directories,
+// content of the md5sums control file and the installed size in KiB.
+func (packg *Package) createDebData(entries []nativeEntry) ([]byte, string, int64, error) {
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	var md5sums strings.Builder
+	var installedSize int64
+
+	writtenDirs := map[string]struct{}{}
+	var writeDir func(dirPath string, modTime time.Time) error
+	writeDir = func(dirPath string, modTime time.Time) error {
+		if _, written := writtenDirs[dirPath]; written {
+			return nil
+		}
+		if dirPath != "/" {
+			err := writeDir(path.Dir(dirPath), modTime)
+			if err != nil {
+				return err
+			}
+		}
+		writtenDirs[dirPath] = struct{}{}
+		return tarWriter.WriteHeader(newDebTarHeader(tar.TypeDir, debTarName(dirPath)+"/", 0755, modTime))
+	}
+
+	for _, entry := range entries {
+		mode := entry.info.Mode()
+		modTime := packg.getNativeModTime(entry.info)
+		err := writeDir(path.Dir(entry.targetPath), modTime)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		switch {
+		case mode.IsDir():
+			err = writeDir(entry.targetPath, modTime)
+		case entry.linkTarget != "":
+			header := newDebTarHeader(tar.TypeSymlink, debTarName(entry.targetPath), 0777, modTime)
+			header.Linkname = entry.linkTarget
+			err = tarWriter.WriteHeader(header)
+		default:
+			var checksum string
+			checksum, err = writeDebFile(tarWriter, entry, packg.getNativeMode(mode), modTime)
+			md5sums.WriteString(checksum + "  " + strings.TrimPrefix(entry.targetPath, "/") + "\n")
+			installedSize += entry.info.Size()
+		}
+		if err != nil {
+			return nil, "", 0, fmt.Errorf("cannot add %s to Debian package: %s", entry.name, err)
+		}
+	}
+	err := tarWriter.Close()
+	if err != nil {
+		return nil, "", 0, err
+	}
+	err = gzipWriter.Close()
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return buffer.Bytes(), md5sums.String(), (installedSize + 1023) / 1024, nil
+}
+
+// writeDebFile
+// Writes regular file of the entry to the data tar archive. Returns MD5 checksum of the file.
+func writeDebFile(tarWriter *tar.Writer, entry nativeEntry, mode os.FileMode, modTime time.Time) (string, error) {
+	header := newDebTarHeader(tar.TypeReg, debTarName(entry.targetPath), int64(mode), modTime)
+	header.Size = entry.info.Size()
+	err := tarWriter.WriteHeader(header)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(entry.path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := md5.New()
+	_, err = io.Copy(io.MultiWriter(tarWriter, hash), file)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// createDebControl
+// Returns control.tar.gz of the Debian package with the control and md5sums files.
+func (packg *Package) createDebControl(md5sums string, installedSize int64, buildTime time.Time) ([]byte, error) {
+	var control strings.Builder
+	fmt.Fprintf(&control, "Package: %s\n", packg.GetNativeName())
+	fmt.Fprintf(&control, "Version: %s-%s\n", packg.GetNativeVersion(NativeFormatDeb), nativeReleaseConst)
+	fmt.Fprintf(&control, "Architecture: %s\n", getNativeArch(NativeFormatDeb, packg.PlatformString.String.Machine))
+	fmt.Fprintf(&control, "Maintainer: %s\n", nativeMaintainerConst)
+	fmt.Fprintf(&control, "Installed-Size: %d\n", installedSize)
+	var depends []string
+	for _, dep := range packg.Native.Dependencies {
+		depends = append(depends, fmt.Sprintf("%s (= %s-%s)", dep.GetNativeName(), dep.GetNativeVersion(NativeFormatDeb), nativeReleaseConst))
+	}
+	if len(depends) > 0 {
+		fmt.Fprintf(&control, "Depends: %s\n", strings.Join(depends, ", "))
+	}
+	fmt.Fprintf(&control, "Section: misc\n")
+	fmt.Fprintf(&control, "Priority: optional\n")
+	fmt.Fprintf(&control, "Description: %s\n", packg.Name)
+	fmt.Fprintf(&control, " Package %s built by bap-builder.\n", packg.GetFullPackageName())
+
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"./control", control.String()},
+		{"./md5sums", md5sums},
+	}
+	err := tarWriter.WriteHeader(newDebTarHeader(tar.TypeDir, "./", 0755, buildTime))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		header := newDebTarHeader(tar.TypeReg, file.name, 0644, buildTime)
+		header.Size = int64(len(file.content))
+		err = tarWriter.WriteHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tarWriter.Write([]byte(file.content))
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = tarWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	err = gzipWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// newDebTarHeader
+// Returns header of the tar archive entry owned by root.
+func newDebTarHeader(typeflag byte, name string, mode int64, modTime time.Time) *tar.Header {
+	return &tar.Header{
+		Typeflag: typeflag,
+		Name:     name,
+		Mode:     mode,
+		ModTime:  modTime,
+		Uname:    "root",
+		Gname:    "root",
+		Format:   tar.FormatGNU,
+	}
+}
+
+// debTarName
+// Returns name of the entry with the absolute targetPath in the Debian package tar archive.
+func debTarName(targetPath string) string {
+	if targetPath == "/" {
+		return "."
+	}
+	return "." + targetPath
+}
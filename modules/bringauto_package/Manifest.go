@@ -0,0 +1,141 @@
+package bringauto_package
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Directory inside the Package archive where the manifest is stored
+	ManifestDir = "share/bap"
+	// Extension of the manifest file, the file name is the short Package name
+	ManifestExt = ".manifest.json"
+)
+
+// ManifestDependency
+// Package which the Package was built with.
+type ManifestDependency struct {
+	Name       string
+	VersionTag string
+}
+
+// Manifest
+// Describes how the Package was built. The manifest is stored inside the Package archive, so it
+// is present also in the sysroot where the archive is unpacked.
+type Manifest struct {
+	Name           string
+	VersionTag     string
+	PlatformString string
+	IsLibrary      bool
+	IsDevLib       bool
+	IsDebug        bool
+	// GitURI URI of the Git repository, empty for other sources
+	GitURI string `json:",omitempty"`
+	// GitCommit hash of the commit the Package was built from, empty for other sources
+	GitCommit string `json:",omitempty"`
+	// TarballURL URL of the source tarball, empty for other sources
+	TarballURL string `json:",omitempty"`
+	// TarballSHA256 checksum of the source tarball, empty for other sources
+	TarballSHA256 string `json:",omitempty"`
+	// LocalDirectory path of the local source directory, empty for other sources
+	LocalDirectory string `json:",omitempty"`
+	// CMakeDefines CMake variables the Package was configured with
+	CMakeDefines map[string]string `json:",omitempty"`
+	// DockerImage name of the docker image the Package was built in
+	DockerImage string
+	// DockerImageId ID of the docker image the Package was built in
+	DockerImageId string
+	// Dependencies Packages from DependsOn with their versions
+	Dependencies []ManifestDependency
+	// BuildTimestamp time of the build in RFC 3339 format
+	BuildTimestamp string
+}
+
+// GetManifestPath
+// Returns path of the manifest of the Package relative to the root of the Package archive. The file
+// name contains the version, so manifests of different versions of the Package do not collide.
+func (packg *Package) GetManifestPath() string {
+	return path.Join(ManifestDir, packg.GetShortPackageName()+stringSeparator+packg.VersionTag+ManifestExt)
+}
+
+// writeManifest
+// Stores manifest of the Package to the sourceDir which is going to be archived.
+func (packg *Package) writeManifest(sourceDir string) error {
+	mbytes, err := json.MarshalIndent(packg.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	manifestPath := filepath.Join(sourceDir, filepath.FromSlash(packg.GetManifestPath()))
+	err = os.MkdirAll(filepath.Dir(manifestPath), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath, append(mbytes, '\n'), 0644)
+}
+
+// ReadManifest
+// Reads manifest from the Package archive of any supported format. Returns error if the archive
+// has no manifest.
+func ReadManifest(archivePath string) (*Manifest, error) {
+	name, mbytes, err := readArchiveFile(archivePath, isManifestPath)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("package %s has no manifest", archivePath)
+	}
+	var manifest Manifest
+	err = json.Unmarshal(mbytes, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest %s in %s - %s", name, archivePath, err)
+	}
+	return &manifest, nil
+}
+
+// isManifestPath
+// Returns true if the name of the file inside the Package archive is a path of a manifest.
+func isManifestPath(name string) bool {
+	return path.Dir(name) == ManifestDir && strings.HasSuffix(name, ManifestExt)
+}
+
+// readArchiveFile
+// Returns name and content of the first regular file in the Package archive whose name matches.
+// Names are relative to the archive root without the leading "./". Returns empty name if no
+// file matches.
+func readArchiveFile(archivePath string, match func(name string) bool) (string, []byte, error) {
+	archiveFormat, err := GetArchiveFormat(archivePath)
+	if err != nil {
+		return "", nil, err
+	}
+	if archiveFormat != FormatZip {
+		return readTarArchiveFile(archivePath, archiveFormat, match)
+	}
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return "", nil, err
+	}
+	defer reader.Close()
+	for _, file := range reader.File {
+		name := strings.TrimPrefix(file.Name, "./")
+		if file.FileInfo().IsDir() || !match(name) {
+			continue
+		}
+		fileReader, err := file.Open()
+		if err != nil {
+			return "", nil, err
+		}
+		mbytes, err := io.ReadAll(fileReader)
+		fileReader.Close()
+		if err != nil {
+			return "", nil, err
+		}
+		return name, mbytes, nil
+	}
+	return "", nil, nil
+}
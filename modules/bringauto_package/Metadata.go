@@ -0,0 +1,88 @@
+package bringauto_package
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// Signature of the zip end of central directory record
+	zipEndOfCentralDirSignature = 0x06054b50
+	// Size of the zip end of central directory record without the comment
+	zipEndOfCentralDirSize = 22
+)
+
+// Metadata
+// Information about the Package build stored as JSON in the comment of the Package zip archive
+// or in the PAX global header of the Package tar archive.
+type Metadata struct {
+	// GitURI URI of the Git repository the Package was built from, empty for other sources
+	GitURI string `json:",omitempty"`
+	// GitCommit hash of the commit the Package was built from, empty for other sources
+	GitCommit string `json:",omitempty"`
+}
+
+// ReadMetadata
+// Reads Metadata from the Package archive of any supported format. Returns nil Metadata if the
+// archive has no Metadata.
+func ReadMetadata(archivePath string) (*Metadata, error) {
+	archiveFormat, err := GetArchiveFormat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	if archiveFormat != FormatZip {
+		return readTarMetadata(archivePath, archiveFormat)
+	}
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	if reader.Comment == "" {
+		return nil, nil
+	}
+	var metadata Metadata
+	err = json.Unmarshal([]byte(reader.Comment), &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata of %s - %s", archivePath, err)
+	}
+	return &metadata, nil
+}
+
+// writeMetadata
+// Stores Metadata as the comment of the zip archive. The archive must have no comment.
+func writeMetadata(archivePath string, metadata *Metadata) error {
+	comment, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	if len(comment) > 0xffff {
+		return fmt.Errorf("metadata too long")
+	}
+	file, err := os.OpenFile(archivePath, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	recordOffset, err := file.Seek(-zipEndOfCentralDirSize, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	record := make([]byte, zipEndOfCentralDirSize)
+	_, err = io.ReadFull(file, record)
+	if err != nil {
+		return err
+	}
+	if binary.LittleEndian.Uint32(record[0:4]) != zipEndOfCentralDirSignature ||
+		binary.LittleEndian.Uint16(record[20:22]) != 0 {
+		return fmt.Errorf("cannot find end of central directory of %s", archivePath)
+	}
+	binary.LittleEndian.PutUint16(record[20:22], uint16(len(comment)))
+	_, err = file.WriteAt(append(record, comment...), recordOffset)
+	return err
+}
@@ -0,0 +1,225 @@
+package bringauto_package
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NativeFormat is a format of the native package of the target distribution.
+type NativeFormat string
+
+const (
+	// NativeFormatDeb Debian package (Debian, Ubuntu)
+	NativeFormatDeb NativeFormat = "deb"
+	// NativeFormatRpm RPM package (Fedora, RHEL)
+	NativeFormatRpm NativeFormat = "rpm"
+)
+
+const (
+	DebExt = ".deb"
+	RpmExt = ".rpm"
+	// Installation prefix of the native package files used if no prefix is given
+	DefaultNativePrefix = "/usr"
+	// Release of the native package, VersionTag is the upstream version
+	nativeReleaseConst = "1"
+	// Maintainer (Debian) and packager (RPM) of the native package
+	nativeMaintainerConst = "bap-builder"
+)
+
+var (
+	// Distributions whose native package format is deb
+	debDistributions = []string{"debian", "ubuntu"}
+	// Distributions whose native package format is rpm
+	rpmDistributions = []string{"fedora", "centos", "rhel", "rocky", "almalinux", "opensuse", "sles"}
+)
+
+// NativePackage
+// Native package (deb or rpm) created from the Package files next to the Package archive.
+type NativePackage struct {
+	Format NativeFormat
+	// Prefix installation prefix of the Package files on the target system
+	Prefix string
+	// Dependencies Packages the Package depends on, the native package requires exactly their
+	// versions
+	Dependencies []Package
+}
+
+// NativeFormats
+// Returns all supported native package formats.
+func NativeFormats() []NativeFormat {
+	return []NativeFormat{NativeFormatDeb, NativeFormatRpm}
+}
+
+// ParseNativeFormat
+// Returns NativeFormat represented by the format string. The "auto" format is resolved by the
+// distribution of the platformString.
+func ParseNativeFormat(format string, platformString *PlatformString) (NativeFormat, error) {
+	switch format {
+	case string(NativeFormatDeb):
+		return NativeFormatDeb, nil
+	case string(NativeFormatRpm):
+		return NativeFormatRpm, nil
+	case "auto":
+		distroName := strings.ToLower(platformString.String.DistroName)
+		if slices.Contains(debDistributions, distroName) {
+			return NativeFormatDeb, nil
+		}
+		if slices.Contains(rpmDistributions, distroName) {
+			return NativeFormatRpm, nil
+		}
+		return "", fmt.Errorf("cannot determine native package format of distribution '%s'", distroName)
+	}
+	return "", fmt.Errorf("unsupported native package format '%s' (supported: deb, rpm, auto)", format)
+}
+
+// Ext
+// Returns file extension of the native package format (including the leading dot).
+func (format NativeFormat) Ext() string {
+	if format == NativeFormatRpm {
+		return RpmExt
+	}
+	return DebExt
+}
+
+// GetNativeName
+// Returns name of the native package - the short package name in lowercase with '_'
+// replaced by '-'.
+func (packg *Package) GetNativeName() string {
+	return strings.ReplaceAll(strings.ToLower(packg.GetShortPackageName()), "_", "-")
+}
+
+// GetNativeVersion
+// Returns version of the native package of the given format - VersionTag without the leading 'v'.
+// The pre-release is separated by '~' instead of '-', so it is ordered before the release version
+// by dpkg and rpm. '-' is not allowed in the RPM version, so the other hyphens (in the pre-release
+// and build metadata) are replaced by '_' for rpm.
+func (packg *Package) GetNativeVersion(format NativeFormat) string {
+	nativeVersion := strings.TrimPrefix(packg.VersionTag, "v")
+	version, err := ParseVersionTag(packg.VersionTag)
+	if err == nil && version.PreRelease != "" {
+		nativeVersion = strings.Replace(nativeVersion, "-", "~", 1)
+	}
+	if format == NativeFormatRpm {
+		nativeVersion = strings.ReplaceAll(nativeVersion, "-", "_")
+	}
+	return nativeVersion
+}
+
+// GetNativeArchiveName
+// Returns file name of the native package - full package name with extension of the format.
+func (packg *Package) GetNativeArchiveName(format NativeFormat) string {
+	return packg.GetFullPackageName() + format.Ext()
+}
+
+// getNativeArch
+// Returns architecture of the native package derived from the Machine of the platform string.
+func getNativeArch(format NativeFormat, machine string) string {
+	machine = strings.ReplaceAll(strings.ToLower(machine), "-", "_")
+	debArch, rpmArch := strings.ReplaceAll(machine, "_", "-"), machine
+	switch machine {
+	case "x86_64", "amd64":
+		debArch, rpmArch = "amd64", "x86_64"
+	case "aarch64", "arm64":
+		debArch, rpmArch = "arm64", "aarch64"
+	case "armv7l", "armv7hl", "armhf":
+		debArch, rpmArch = "armhf", "armv7hl"
+	case "i386", "i686":
+		debArch, rpmArch = "i386", "i686"
+	}
+	if format == NativeFormatRpm {
+		return rpmArch
+	}
+	return debArch
+}
+
+// nativeEntry
+// File, directory or symlink of the native package with its absolute path on the target system.
+type nativeEntry struct {
+	archiveEntry
+	// targetPath absolute path on the target system
+	targetPath string
+	// linkTarget target of the symlink, empty for other entries
+	linkTarget string
+}
+
+// collectNativeEntries
+// Returns all entries of the sourceDir placed under the prefix, sorted by their target paths.
+func collectNativeEntries(sourceDir string, prefix string) ([]nativeEntry, error) {
+	entries, err := collectArchiveEntries(sourceDir)
+	if err != nil {
+		return nil, err
+	}
+	var nativeEntries []nativeEntry
+	for _, entry := range entries {
+		nativeEntry := nativeEntry{
+			archiveEntry: entry,
+			targetPath:   path.Join("/", prefix, entry.name),
+		}
+		mode := entry.info.Mode()
+		switch {
+		case mode&fs.ModeSymlink != 0:
+			nativeEntry.linkTarget, err = os.Readlink(entry.path)
+			if err != nil {
+				return nil, err
+			}
+		case !mode.IsDir() && !mode.IsRegular():
+			return nil, fmt.Errorf("unsupported file type %s of %s", mode.Type(), entry.name)
+		}
+		nativeEntries = append(nativeEntries, nativeEntry)
+	}
+	return nativeEntries, nil
+}
+
+// getNativeModTime
+// Returns modification time of the entry stored in the native package.
+func (packg *Package) getNativeModTime(info fs.FileInfo) time.Time {
+	if packg.Reproducible {
+		return packg.SourceDateEpoch.UTC()
+	}
+	return info.ModTime().UTC()
+}
+
+// getNativeBuildTime
+// Returns build time stored in the native package.
+func (packg *Package) getNativeBuildTime() time.Time {
+	if packg.Reproducible {
+		return packg.SourceDateEpoch.UTC()
+	}
+	return time.Now().UTC()
+}
+
+// getNativeMode
+// Returns permissions of the entry stored in the native package.
+func (packg *Package) getNativeMode(mode fs.FileMode) fs.FileMode {
+	if packg.Reproducible {
+		return normalizedMode(mode).Perm()
+	}
+	return mode.Perm()
+}
+
+// createNativePackage
+// Creates native package of the Native format from all files in the sourceDir in the outputDir.
+func (packg *Package) createNativePackage(sourceDir string, outputDir string) error {
+	prefix := packg.Native.Prefix
+	if prefix == "" {
+		prefix = DefaultNativePrefix
+	}
+	entries, err := collectNativeEntries(sourceDir, prefix)
+	if err != nil {
+		return err
+	}
+	archivePath := path.Join(outputDir, packg.GetNativeArchiveName(packg.Native.Format))
+	switch packg.Native.Format {
+	case NativeFormatDeb:
+		return packg.createDebPackage(entries, archivePath)
+	case NativeFormatRpm:
+		return packg.createRpmPackage(entries, archivePath)
+	default:
+		return fmt.Errorf("unsupported native package format '%s'", packg.Native.Format)
+	}
+}
@@ -7,8 +7,8 @@ import (
 	"github.com/mholt/archiver/v3"
 	"os"
 	"path"
-	"regexp"
 	"strings"
+	"time"
 )
 
 const (
@@ -16,6 +16,8 @@ const (
 	defaultPackageNameConst = "generic-package"
 	defaultVersionTagConst  = "v0.0.0"
 	stringSeparator = "_"
+	// DbgsymSuffix suffix of the full package name of the debug symbols archive
+	DbgsymSuffix = "-dbgsym"
 )
 
 // Package enables us to easily create a package
@@ -32,6 +34,22 @@ type Package struct {
 	IsDevLib bool
 	// Mark package as debug build if true
 	IsDebug bool
+	// Metadata stored in the Package archive, nothing is stored if nil
+	Metadata *Metadata `json:"-"`
+	// Manifest stored inside the Package archive, nothing is stored if nil
+	Manifest *Manifest `json:"-"`
+	// Format of the Package archive, FormatZip if empty
+	Format ArchiveFormat `json:"-"`
+	// Native package (deb or rpm) created next to the Package archive, nothing is created if nil
+	Native *NativePackage `json:"-"`
+	// DebugSymbolsDir directory with split debug symbols archived to the debug symbols archive
+	// next to the Package archive, nothing is created if empty
+	DebugSymbolsDir string `json:"-"`
+	// Reproducible if true, the archive depends only on the content of the files, all files
+	// have SourceDateEpoch as the modification time
+	Reproducible bool `json:"-"`
+	// SourceDateEpoch modification time of files in the reproducible archive
+	SourceDateEpoch time.Time `json:"-"`
 }
 
 func (packg *Package) FillDefault(*bringauto_prerequisites.Args) error {
@@ -58,9 +76,9 @@ func (packg *Package) CheckPrerequisites(*bringauto_prerequisites.Args) error {
 		return fmt.Errorf("IsDevLib is true but IsLibrary is false")
 	}
 
-	versionTagRegex, _ := regexp.CompilePOSIX("^v[0-9]+\\.[0-9]+\\.[0-9]+$")
-	if !versionTagRegex.MatchString(packg.VersionTag) {
-		return fmt.Errorf("VersionTag %s is not valid version tag", packg.VersionTag)
+	_, err := ParseVersionTag(packg.VersionTag)
+	if err != nil {
+		return fmt.Errorf("invalid VersionTag - %s", err)
 	}
 	if packg.Name == "" {
 		return fmt.Errorf("package name cannot be empty")
@@ -70,8 +88,14 @@ func (packg *Package) CheckPrerequisites(*bringauto_prerequisites.Args) error {
 
 // CreatePackage creates a package from sourceDir directory
 //   - construct package name
-//   - zip all files into archive with name <package_name>.zip
-//   - copy the zip archive to the outputDir
+//   - store Manifest (if any) to the sourceDir, so it is part of the archive
+//   - archive all files into archive with name <package_name><format_ext> in the outputDir
+//     (reproducibly if Reproducible is set)
+//   - store Metadata (if any) as the comment of the zip archive or as the PAX global header of
+//     the tar archive
+//   - create the Native package (if any) in the outputDir
+//   - archive all files from the DebugSymbolsDir (if any) into archive with name
+//     <package_name>-dbgsym<format_ext> in the outputDir
 func (packg *Package) CreatePackage(sourceDir string, outputDir string) error {
 	var err error
 	if _, err = os.Stat(sourceDir); os.IsNotExist(err) {
@@ -82,16 +106,79 @@ func (packg *Package) CreatePackage(sourceDir string, outputDir string) error {
 		return err
 	}
 
-	packageName := packg.GetFullPackageName() + ZipExt
+	if packg.Manifest != nil {
+		err = packg.writeManifest(sourceDir)
+		if err != nil {
+			return fmt.Errorf("cannot write package manifest - %s", err)
+		}
+	}
+
+	packageName := packg.GetArchiveName()
 
-	err = createZIPArchive(sourceDir, outputDir+"/"+packageName)
+	if packg.Native != nil {
+		err = packg.createNativePackage(sourceDir, outputDir)
+		if err != nil {
+			return fmt.Errorf("cannot create %s package - %s", packg.Native.Format, err)
+		}
+	}
+
+	if packg.DebugSymbolsDir != "" {
+		err = packg.createArchive(packg.DebugSymbolsDir, outputDir+"/"+packg.GetDbgsymArchiveName())
+		if err != nil {
+			return fmt.Errorf("cannot create debug symbols archive - %s", err)
+		}
+	}
+
+	return packg.createArchive(sourceDir, outputDir+"/"+packageName)
+}
+
+// createArchive
+// Archives all files from the sourceDir into the archivePath in the Format of the Package.
+func (packg *Package) createArchive(sourceDir string, archivePath string) error {
+	var err error
+	if packg.Format == FormatTarGz || packg.Format == FormatTarZst {
+		return createTarArchive(sourceDir, archivePath, tarArchiveOptions{
+			format:       packg.Format,
+			reproducible: packg.Reproducible,
+			modTime:      packg.SourceDateEpoch.UTC(),
+			metadata:     packg.Metadata,
+		})
+	}
+	if packg.Reproducible {
+		err = createReproducibleZIPArchive(sourceDir, archivePath, packg.SourceDateEpoch)
+	} else {
+		err = createZIPArchive(sourceDir, archivePath)
+	}
 	if err != nil {
 		return fmt.Errorf("cannot create zip archive")
 	}
+	if packg.Metadata != nil {
+		err = writeMetadata(archivePath, packg.Metadata)
+		if err != nil {
+			return fmt.Errorf("cannot write package metadata - %s", err)
+		}
+	}
 
 	return nil
 }
 
+// ComparePackages
+// Compares Packages by name, build type (release first) and VersionTag (by CompareVersionTags).
+// Used for sorting of Packages, so versions of one Package are ordered from the lowest.
+func ComparePackages(packg Package, other Package) int {
+	result := strings.Compare(packg.Name, other.Name)
+	if result == 0 && packg.IsDebug != other.IsDebug {
+		result = -1
+		if packg.IsDebug {
+			result = 1
+		}
+	}
+	if result == 0 {
+		result = CompareVersionTags(packg.VersionTag, other.VersionTag)
+	}
+	return result
+}
+
 // GetShortPackageName
 // Returns short package name without version and platform string.
 func (packg *Package) GetShortPackageName() string {
@@ -119,6 +206,19 @@ func (packg *Package) GetFullPackageName() string {
 	return strings.Join(packageName, stringSeparator)
 }
 
+// GetArchiveName
+// Returns file name of the Package archive - full package name with extension of the Format.
+func (packg *Package) GetArchiveName() string {
+	return packg.GetFullPackageName() + packg.Format.Ext()
+}
+
+// GetDbgsymArchiveName
+// Returns file name of the debug symbols archive - full package name with DbgsymSuffix and
+// extension of the Format.
+func (packg *Package) GetDbgsymArchiveName() string {
+	return packg.GetFullPackageName() + DbgsymSuffix + packg.Format.Ext()
+}
+
 func createZIPArchive(sourceDir string, archivePath string) error {
 	var files []string
 	var err error
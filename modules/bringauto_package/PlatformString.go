@@ -113,6 +113,8 @@ func (pstr *PlatformString) determinePlatformString(credentials bringauto_ssh.SS
 		panic(fmt.Errorf("cannot determine PlatformString for explicit mode"))
 	}
 
+	docker.PublishSSHPort()
+
 	dockerRun := (*bringauto_docker.DockerRun)(docker)
 	removeHandler := bringauto_process.SignalHandlerAddHandler(func() error {
 		dockerStop := (*bringauto_docker.DockerStop)(docker)
@@ -128,6 +130,10 @@ func (pstr *PlatformString) determinePlatformString(credentials bringauto_ssh.SS
 	if err != nil {
 		return err
 	}
+	credentials.Port, err = docker.GetSSHPort()
+	if err != nil {
+		return err
+	}
 
 	pstr.String.DistroName = getDistributionName(credentials)
 	pstr.String.DistroRelease = getReleaseVersion(credentials)
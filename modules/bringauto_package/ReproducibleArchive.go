@@ -0,0 +1,158 @@
+package bringauto_package
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+var (
+	// Minimal modification time which can be stored in the zip archive (MS-DOS time)
+	minZipModTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// archiveEntry
+// File, directory or symlink stored in the Package archive.
+type archiveEntry struct {
+	// name path relative to the archive root, separated by '/'
+	name string
+	// path path of the file on the filesystem
+	path string
+	info fs.FileInfo
+}
+
+// collectArchiveEntries
+// Returns all files and directories in the sourceDir sorted by their names.
+func collectArchiveEntries(sourceDir string) ([]archiveEntry, error) {
+	var entries []archiveEntry
+	err := filepath.WalkDir(sourceDir, func(filePath string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath == sourceDir {
+			return nil
+		}
+		relPath, err := filepath.Rel(sourceDir, filePath)
+		if err != nil {
+			return err
+		}
+		info, err := os.Lstat(filePath)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, archiveEntry{name: filepath.ToSlash(relPath), path: filePath, info: info})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s directory: %s", sourceDir, err)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+	return entries, nil
+}
+
+// normalizedMode
+// Returns permissions of the file stored in the reproducible archive. Directories and executable
+// files have 0755, other files 0644 and symlinks 0777.
+func normalizedMode(mode fs.FileMode) fs.FileMode {
+	switch {
+	case mode.IsDir():
+		return fs.ModeDir | 0755
+	case mode&fs.ModeSymlink != 0:
+		return fs.ModeSymlink | 0777
+	case mode.Perm()&0111 != 0:
+		return 0755
+	default:
+		return 0644
+	}
+}
+
+// createReproducibleZIPArchive
+// Creates zip archive of all files in the sourceDir which depends only on the names, types,
+// content and executable permission of the files. Entries are sorted by name, all entries have
+// modTime as the modification time, permissions are normalized to 0755 for directories and
+// executable files and to 0644 for other files. Owners of the files are not stored.
+func createReproducibleZIPArchive(sourceDir string, archivePath string, modTime time.Time) error {
+	entries, err := collectArchiveEntries(sourceDir)
+	if err != nil {
+		return err
+	}
+
+	if modTime.Before(minZipModTime) {
+		modTime = minZipModTime
+	}
+	modTime = modTime.UTC()
+
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer archiveFile.Close()
+	zipWriter := zip.NewWriter(archiveFile)
+	zipWriter.RegisterCompressor(zip.Deflate, func(writer io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(writer, flate.BestSpeed)
+	})
+	for _, entry := range entries {
+		err = addReproducibleEntry(zipWriter, entry, modTime)
+		if err != nil {
+			return fmt.Errorf("cannot add %s to archive: %s", entry.name, err)
+		}
+	}
+	err = zipWriter.Close()
+	if err != nil {
+		return err
+	}
+	return archiveFile.Close()
+}
+
+// addReproducibleEntry
+// Adds the entry to the zip archive with normalized metadata.
+func addReproducibleEntry(zipWriter *zip.Writer, entry archiveEntry, modTime time.Time) error {
+	header := zip.FileHeader{
+		Name:     entry.name,
+		Method:   zip.Deflate,
+		Modified: modTime,
+	}
+	mode := entry.info.Mode()
+	switch {
+	case mode.IsDir():
+		header.Name += "/"
+		header.Method = zip.Store
+	case mode&fs.ModeSymlink != 0:
+		header.Method = zip.Store
+	case !mode.IsRegular():
+		return fmt.Errorf("unsupported file type %s", mode.Type())
+	}
+	header.SetMode(normalizedMode(mode))
+
+	writer, err := zipWriter.CreateHeader(&header)
+	if err != nil {
+		return err
+	}
+	switch {
+	case mode.IsDir():
+		return nil
+	case mode&fs.ModeSymlink != 0:
+		target, err := os.Readlink(entry.path)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write([]byte(target))
+		return err
+	default:
+		file, err := os.Open(entry.path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(writer, file)
+		return err
+	}
+}
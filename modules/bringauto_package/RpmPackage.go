@@ -0,0 +1,435 @@
+package bringauto_package
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"sort"
+)
+
+// Types of the RPM header entries
+const (
+	rpmTypeInt16       = 3
+	rpmTypeInt32       = 4
+	rpmTypeString      = 6
+	rpmTypeBin         = 7
+	rpmTypeStringArray = 8
+	rpmTypeI18NString  = 9
+)
+
+// Tags of the RPM signature header
+const (
+	rpmSigTagHeaderSignatures = 62
+	rpmSigTagSHA1             = 269
+	rpmSigTagSHA256           = 273
+	rpmSigTagSize             = 1000
+	rpmSigTagMD5              = 1004
+	rpmSigTagPayloadSize      = 1007
+)
+
+// Tags of the RPM header
+const (
+	rpmTagHeaderImmutable   = 63
+	rpmTagHeaderI18NTable   = 100
+	rpmTagName              = 1000
+	rpmTagVersion           = 1001
+	rpmTagRelease           = 1002
+	rpmTagSummary           = 1004
+	rpmTagDescription       = 1005
+	rpmTagBuildTime         = 1006
+	rpmTagSize              = 1009
+	rpmTagLicense           = 1014
+	rpmTagPackager          = 1015
+	rpmTagGroup             = 1016
+	rpmTagOS                = 1021
+	rpmTagArch              = 1022
+	rpmTagFileSizes         = 1028
+	rpmTagFileModes         = 1030
+	rpmTagFileRDevs         = 1033
+	rpmTagFileMTimes        = 1034
+	rpmTagFileDigests       = 1035
+	rpmTagFileLinkTos       = 1036
+	rpmTagFileFlags         = 1037
+	rpmTagFileUserName      = 1039
+	rpmTagFileGroupName     = 1040
+	rpmTagSourceRpm         = 1044
+	rpmTagProvideName       = 1047
+	rpmTagRequireFlags      = 1048
+	rpmTagRequireName       = 1049
+	rpmTagRequireVersion    = 1050
+	rpmTagFileDevices       = 1095
+	rpmTagFileINodes        = 1096
+	rpmTagFileLangs         = 1097
+	rpmTagProvideFlags      = 1112
+	rpmTagProvideVersion    = 1113
+	rpmTagDirIndexes        = 1116
+	rpmTagBaseNames         = 1117
+	rpmTagDirNames          = 1118
+	rpmTagPayloadFormat     = 1124
+	rpmTagPayloadCompressor = 1125
+	rpmTagPayloadFlags      = 1126
+	rpmTagFileDigestAlgo    = 5011
+)
+
+const (
+	// Magic number at the start of the RPM lead
+	rpmLeadMagicConst = 0xedabeedb
+	// Magic bytes at the start of the RPM header (including the header version)
+	rpmHeaderMagicConst = "\x8e\xad\xe8\x01"
+	// Size of the RPM lead
+	rpmLeadSizeConst = 96
+	// Signature type of the RPM lead - the signature is in the header format
+	rpmLeadSignatureTypeConst = 5
+	// Dependency flags
+	rpmSenseLess   = 0x02
+	rpmSenseEqual  = 0x08
+	rpmSenseRPMLib = 0x01000000
+	// SHA256 algorithm of the file digests
+	rpmDigestAlgoSHA256 = 8
+	// File type bits of the file mode
+	rpmModeDir     = 0040000
+	rpmModeRegular = 0100000
+	rpmModeSymlink = 0120000
+)
+
+// rpmLibRequires
+// Features of rpm required by packages created by bap-builder.
+var rpmLibRequires = [][2]string{
+	{"rpmlib(CompressedFileNames)", "3.0.4-1"},
+	{"rpmlib(FileDigests)", "4.6.0-1"},
+	{"rpmlib(PayloadFilesHavePrefix)", "4.0-1"},
+}
+
+// rpmHeaderEntry
+// Entry of the RPM header. Value is []byte for binary data, string, []string, []uint16 or []uint32.
+type rpmHeaderEntry struct {
+	tag       uint32
+	valueType uint32
+	value     any
+}
+
+// rpmHeader
+// Header structure used by the RPM package for the signature and the package header.
+type rpmHeader struct {
+	entries []rpmHeaderEntry
+}
+
+func (header *rpmHeader) add(tag uint32, valueType uint32, value any) {
+	header.entries = append(header.entries, rpmHeaderEntry{tag: tag, valueType: valueType, value: value})
+}
+
+// marshal
+// Returns binary representation of the header. The header is an immutable region identified by
+// the regionTag.
+func (header *rpmHeader) marshal(regionTag uint32) []byte {
+	entries := slices.Clone(header.entries)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].tag < entries[j].tag
+	})
+
+	var store bytes.Buffer
+	var index bytes.Buffer
+	indexCount := len(entries) + 1
+	writeIndex := func(tag uint32, valueType uint32, offset int32, count uint32) {
+		binary.Write(&index, binary.BigEndian, []uint32{tag, valueType, uint32(offset), count})
+	}
+	for _, entry := range entries {
+		count, alignment := uint32(1), 1
+		switch value := entry.value.(type) {
+		case []byte:
+			count = uint32(len(value))
+		case []string:
+			count = uint32(len(value))
+		case []uint16:
+			count, alignment = uint32(len(value)), 2
+		case []uint32:
+			count, alignment = uint32(len(value)), 4
+		}
+		for store.Len()%alignment != 0 {
+			store.WriteByte(0)
+		}
+		writeIndex(entry.tag, entry.valueType, int32(store.Len()), count)
+		switch value := entry.value.(type) {
+		case []byte:
+			store.Write(value)
+		case string:
+			store.WriteString(value)
+			store.WriteByte(0)
+		case []string:
+			for _, item := range value {
+				store.WriteString(item)
+				store.WriteByte(0)
+			}
+		default:
+			binary.Write(&store, binary.BigEndian, value)
+		}
+	}
+
+	// Trailer of the immutable region points back to the start of the index
+	for store.Len()%4 != 0 {
+		store.WriteByte(0)
+	}
+	regionOffset := store.Len()
+	binary.Write(&store, binary.BigEndian, []uint32{regionTag, rpmTypeBin, uint32(int32(-indexCount * 16)), 16})
+
+	var result bytes.Buffer
+	result.WriteString(rpmHeaderMagicConst)
+	binary.Write(&result, binary.BigEndian, []uint32{0, uint32(indexCount), uint32(store.Len())})
+	binary.Write(&result, binary.BigEndian, []uint32{regionTag, rpmTypeBin, uint32(regionOffset), 16})
+	result.Write(index.Bytes())
+	result.Write(store.Bytes())
+	return result.Bytes()
+}
+
+// createRpmPackage
+// Creates RPM package at archivePath. The package consists of the lead, the signature header,
+// the package header and the gzip compressed cpio payload.
+func (packg *Package) createRpmPackage(entries []nativeEntry, archivePath string) error {
+	payload, payloadSize, err := packg.createRpmPayload(entries)
+	if err != nil {
+		return err
+	}
+	header, err := packg.createRpmHeader(entries)
+	if err != nil {
+		return err
+	}
+	headerBytes := header.marshal(rpmTagHeaderImmutable)
+
+	headerSHA1 := sha1.Sum(headerBytes)
+	headerSHA256 := sha256.Sum256(headerBytes)
+	md5Hash := md5.New()
+	md5Hash.Write(headerBytes)
+	md5Hash.Write(payload)
+	signature := rpmHeader{}
+	signature.add(rpmSigTagSHA1, rpmTypeString, hex.EncodeToString(headerSHA1[:]))
+	signature.add(rpmSigTagSHA256, rpmTypeString, hex.EncodeToString(headerSHA256[:]))
+	signature.add(rpmSigTagSize, rpmTypeInt32, []uint32{uint32(len(headerBytes) + len(payload))})
+	signature.add(rpmSigTagMD5, rpmTypeBin, md5Hash.Sum(nil))
+	signature.add(rpmSigTagPayloadSize, rpmTypeInt32, []uint32{uint32(payloadSize)})
+	signatureBytes := signature.marshal(rpmSigTagHeaderSignatures)
+
+	var archive bytes.Buffer
+	archive.Write(packg.createRpmLead())
+	archive.Write(signatureBytes)
+	// The signature header is padded to 8 bytes
+	for archive.Len()%8 != 0 {
+		archive.WriteByte(0)
+	}
+	archive.Write(headerBytes)
+	archive.Write(payload)
+	return os.WriteFile(archivePath, archive.Bytes(), 0644)
+}
+
+// createRpmLead
+// Returns the lead of the RPM package. The lead is obsolete, only its size and magic matter.
+func (packg *Package) createRpmLead() []byte {
+	lead := make([]byte, rpmLeadSizeConst)
+	binary.BigEndian.PutUint32(lead[0:4], rpmLeadMagicConst)
+	lead[4] = 3 // major version
+	lead[5] = 0 // minor version
+	// type (binary) 0 on lead[6:8], architecture number 0 on lead[8:10]
+	copy(lead[10:75], packg.getRpmNEVR())
+	binary.BigEndian.PutUint16(lead[76:78], 1) // operating system Linux
+	binary.BigEndian.PutUint16(lead[78:80], rpmLeadSignatureTypeConst)
+	return lead
+}
+
+// getRpmNEVR
+// Returns name-version-release of the RPM package.
+func (packg *Package) getRpmNEVR() string {
+	return packg.GetNativeName() + "-" + packg.GetNativeVersion(NativeFormatRpm) + "-" + nativeReleaseConst
+}
+
+// createRpmHeader
+// Returns the package header of the RPM package with the package information, dependencies and
+// the list of files.
+func (packg *Package) createRpmHeader(entries []nativeEntry) (*rpmHeader, error) {
+	version := packg.GetNativeVersion(NativeFormatRpm)
+	fullVersion := version + "-" + nativeReleaseConst
+	header := rpmHeader{}
+	header.add(rpmTagHeaderI18NTable, rpmTypeStringArray, []string{"C"})
+	header.add(rpmTagName, rpmTypeString, packg.GetNativeName())
+	header.add(rpmTagVersion, rpmTypeString, version)
+	header.add(rpmTagRelease, rpmTypeString, nativeReleaseConst)
+	header.add(rpmTagSummary, rpmTypeI18NString, []string{packg.Name})
+	header.add(rpmTagDescription, rpmTypeI18NString, []string{"Package " + packg.GetFullPackageName() + " built by bap-builder."})
+	header.add(rpmTagBuildTime, rpmTypeInt32, []uint32{uint32(packg.getNativeBuildTime().Unix())})
+	header.add(rpmTagLicense, rpmTypeString, "Unspecified")
+	header.add(rpmTagPackager, rpmTypeString, nativeMaintainerConst)
+	header.add(rpmTagGroup, rpmTypeI18NString, []string{"Unspecified"})
+	header.add(rpmTagOS, rpmTypeString, "linux")
+	header.add(rpmTagArch, rpmTypeString, getNativeArch(NativeFormatRpm, packg.PlatformString.String.Machine))
+	header.add(rpmTagSourceRpm, rpmTypeString, packg.getRpmNEVR()+".src.rpm")
+	header.add(rpmTagProvideName, rpmTypeStringArray, []string{packg.GetNativeName()})
+	header.add(rpmTagProvideFlags, rpmTypeInt32, []uint32{rpmSenseEqual})
+	header.add(rpmTagProvideVersion, rpmTypeStringArray, []string{fullVersion})
+
+	var requireNames, requireVersions []string
+	var requireFlags []uint32
+	for _, dep := range packg.Native.Dependencies {
+		requireNames = append(requireNames, dep.GetNativeName())
+		requireVersions = append(requireVersions, dep.GetNativeVersion(NativeFormatRpm)+"-"+nativeReleaseConst)
+		requireFlags = append(requireFlags, rpmSenseEqual)
+	}
+	for _, require := range rpmLibRequires {
+		requireNames = append(requireNames, require[0])
+		requireVersions = append(requireVersions, require[1])
+		requireFlags = append(requireFlags, rpmSenseRPMLib|rpmSenseLess|rpmSenseEqual)
+	}
+	header.add(rpmTagRequireName, rpmTypeStringArray, requireNames)
+	header.add(rpmTagRequireVersion, rpmTypeStringArray, requireVersions)
+	header.add(rpmTagRequireFlags, rpmTypeInt32, requireFlags)
+
+	var totalSize uint32
+	var fileSizes, fileMTimes, fileFlags, fileDevices, fileINodes, dirIndexes []uint32
+	var fileModes, fileRDevs []uint16
+	var fileDigests, fileLinkTos, fileUserNames, fileGroupNames, fileLangs, baseNames, dirNames []string
+	dirIndexMap := map[string]uint32{}
+	for i, entry := range entries {
+		mode := entry.info.Mode()
+		fileMode := uint16(packg.getNativeMode(mode))
+		size, digest := uint32(0), ""
+		switch {
+		case mode.IsDir():
+			fileMode |= rpmModeDir
+			size = 4096
+		case entry.linkTarget != "":
+			fileMode = rpmModeSymlink | 0777
+			size = uint32(len(entry.linkTarget))
+		default:
+			fileMode |= rpmModeRegular
+			size = uint32(entry.info.Size())
+			var err error
+			digest, err = fileSHA256(entry.path)
+			if err != nil {
+				return nil, err
+			}
+		}
+		totalSize += size
+		dirName := path.Dir(entry.targetPath) + "/"
+		dirIndex, found := dirIndexMap[dirName]
+		if !found {
+			dirIndex = uint32(len(dirNames))
+			dirIndexMap[dirName] = dirIndex
+			dirNames = append(dirNames, dirName)
+		}
+		fileSizes = append(fileSizes, size)
+		fileModes = append(fileModes, fileMode)
+		fileRDevs = append(fileRDevs, 0)
+		fileMTimes = append(fileMTimes, uint32(packg.getNativeModTime(entry.info).Unix()))
+		fileDigests = append(fileDigests, digest)
+		fileLinkTos = append(fileLinkTos, entry.linkTarget)
+		fileFlags = append(fileFlags, 0)
+		fileUserNames = append(fileUserNames, "root")
+		fileGroupNames = append(fileGroupNames, "root")
+		fileDevices = append(fileDevices, 1)
+		fileINodes = append(fileINodes, uint32(i+1))
+		fileLangs = append(fileLangs, "")
+		dirIndexes = append(dirIndexes, dirIndex)
+		baseNames = append(baseNames, path.Base(entry.targetPath))
+	}
+	header.add(rpmTagSize, rpmTypeInt32, []uint32{totalSize})
+	if len(entries) > 0 {
+		header.add(rpmTagFileSizes, rpmTypeInt32, fileSizes)
+		header.add(rpmTagFileModes, rpmTypeInt16, fileModes)
+		header.add(rpmTagFileRDevs, rpmTypeInt16, fileRDevs)
+		header.add(rpmTagFileMTimes, rpmTypeInt32, fileMTimes)
+		header.add(rpmTagFileDigests, rpmTypeStringArray, fileDigests)
+		header.add(rpmTagFileLinkTos, rpmTypeStringArray, fileLinkTos)
+		header.add(rpmTagFileFlags, rpmTypeInt32, fileFlags)
+		header.add(rpmTagFileUserName, rpmTypeStringArray, fileUserNames)
+		header.add(rpmTagFileGroupName, rpmTypeStringArray, fileGroupNames)
+		header.add(rpmTagFileDevices, rpmTypeInt32, fileDevices)
+		header.add(rpmTagFileINodes, rpmTypeInt32, fileINodes)
+		header.add(rpmTagFileLangs, rpmTypeStringArray, fileLangs)
+		header.add(rpmTagDirIndexes, rpmTypeInt32, dirIndexes)
+		header.add(rpmTagBaseNames, rpmTypeStringArray, baseNames)
+		header.add(rpmTagDirNames, rpmTypeStringArray, dirNames)
+		header.add(rpmTagFileDigestAlgo, rpmTypeInt32, []uint32{rpmDigestAlgoSHA256})
+	}
+	header.add(rpmTagPayloadFormat, rpmTypeString, "cpio")
+	header.add(rpmTagPayloadCompressor, rpmTypeString, "gzip")
+	header.add(rpmTagPayloadFlags, rpmTypeString, "9")
+	return &header, nil
+}
+
+// createRpmPayload
+// Returns gzip compressed cpio (newc) archive with all entries and the size of the uncompressed
+// archive.
+func (packg *Package) createRpmPayload(entries []nativeEntry) ([]byte, int, error) {
+	var archive bytes.Buffer
+	writeEntry := func(name string, mode uint32, mtime int64, ino int, content []byte) {
+		fmt.Fprintf(&archive, "070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x",
+			ino, mode, 0, 0, 1, mtime, len(content), 0, 0, 0, 0, len(name)+1, 0)
+		archive.WriteString(name)
+		archive.WriteByte(0)
+		for archive.Len()%4 != 0 {
+			archive.WriteByte(0)
+		}
+		archive.Write(content)
+		for archive.Len()%4 != 0 {
+			archive.WriteByte(0)
+		}
+	}
+	for i, entry := range entries {
+		mode := entry.info.Mode()
+		fileMode := uint32(packg.getNativeMode(mode))
+		var content []byte
+		switch {
+		case mode.IsDir():
+			fileMode |= rpmModeDir
+		case entry.linkTarget != "":
+			fileMode = rpmModeSymlink | 0777
+			content = []byte(entry.linkTarget)
+		default:
+			fileMode |= rpmModeRegular
+			var err error
+			content, err = os.ReadFile(entry.path)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		writeEntry("."+entry.targetPath, fileMode, packg.getNativeModTime(entry.info).Unix(), i+1, content)
+	}
+	writeEntry("TRAILER!!!", 0, 0, 0, nil)
+
+	var payload bytes.Buffer
+	gzipWriter, err := gzip.NewWriterLevel(&payload, gzip.BestCompression)
+	if err != nil {
+		return nil, 0, err
+	}
+	_, err = gzipWriter.Write(archive.Bytes())
+	if err != nil {
+		return nil, 0, err
+	}
+	err = gzipWriter.Close()
+	if err != nil {
+		return nil, 0, err
+	}
+	return payload.Bytes(), archive.Len(), nil
+}
+
+// fileSHA256
+// Returns SHA256 checksum of the file as a hex string.
+func fileSHA256(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
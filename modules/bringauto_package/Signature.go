@@ -0,0 +1,103 @@
+package bringauto_package
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+const (
+	// Extension of the detached signature file stored next to the signed archive
+	SignatureExt = ".sig"
+)
+
+// ParsePrivateKey
+// Parses ed25519 private key in the PEM encoded PKCS #8 form (as created by
+// 'openssl genpkey -algorithm ed25519').
+func ParsePrivateKey(pemData []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("no PEM encoded private key found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an ed25519 key")
+	}
+	return privateKey, nil
+}
+
+// ParsePublicKeys
+// Parses all ed25519 public keys in the PEM encoded PKIX form (as created by
+// 'openssl pkey -pubout'). Returns error if there is no public key or any of the keys is invalid.
+func ParsePublicKeys(pemData []byte) ([]ed25519.PublicKey, error) {
+	var publicKeys []ed25519.PublicKey
+	for {
+		var block *pem.Block
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("unexpected PEM block '%s'", block.Type)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		publicKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key is not an ed25519 key")
+		}
+		publicKeys = append(publicKeys, publicKey)
+	}
+	if len(publicKeys) == 0 {
+		return nil, fmt.Errorf("no PEM encoded public key found")
+	}
+	return publicKeys, nil
+}
+
+// SignArchive
+// Signs the archive by the privateKey and stores the base64 encoded detached signature to the
+// file <archivePath>.sig.
+func SignArchive(archivePath string, privateKey ed25519.PrivateKey) error {
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		return err
+	}
+	signature := ed25519.Sign(privateKey, data)
+	return os.WriteFile(archivePath+SignatureExt, []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), 0644)
+}
+
+// VerifyArchive
+// Verifies the detached signature of the archive (<archivePath>.sig). Returns error if the
+// signature is missing or it is not valid for any of the trustedKeys.
+func VerifyArchive(archivePath string, trustedKeys []ed25519.PublicKey) error {
+	encodedSignature, err := os.ReadFile(archivePath + SignatureExt)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("archive %s is not signed", archivePath)
+	} else if err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(encodedSignature)))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature of archive %s", archivePath)
+	}
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		return err
+	}
+	for _, trustedKey := range trustedKeys {
+		if ed25519.Verify(trustedKey, data, signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature of archive %s is not valid for any trusted key", archivePath)
+}
@@ -0,0 +1,269 @@
+package bringauto_package
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// Name of the PAX global header which holds Metadata of the Package tar archive
+	tarGlobalHeaderNameConst = "pax_global_header"
+	// PAX record of the global header with Metadata JSON
+	tarMetadataRecordConst = "BAP.metadata"
+)
+
+// tarArchiveOptions
+// Options of the Package tar archive.
+type tarArchiveOptions struct {
+	// format FormatTarGz or FormatTarZst
+	format ArchiveFormat
+	// reproducible if true, entries have modTime, normalized permissions and no owners
+	reproducible bool
+	modTime      time.Time
+	// metadata stored in the PAX global header, nothing is stored if nil
+	metadata *Metadata
+}
+
+// createTarArchive
+// Creates compressed tar archive of all files in the sourceDir. Entries are sorted by name,
+// symlinks are stored as symlinks. If the archive is reproducible, all entries have the same
+// modification time, normalized permissions and no owners.
+func createTarArchive(sourceDir string, archivePath string, options tarArchiveOptions) error {
+	entries, err := collectArchiveEntries(sourceDir)
+	if err != nil {
+		return err
+	}
+
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer archiveFile.Close()
+	compressWriter, err := newCompressWriter(archiveFile, options.format)
+	if err != nil {
+		return err
+	}
+	tarWriter := tar.NewWriter(compressWriter)
+
+	if options.metadata != nil {
+		err = writeTarMetadata(tarWriter, options)
+		if err != nil {
+			return fmt.Errorf("cannot write package metadata - %s", err)
+		}
+	}
+	for _, entry := range entries {
+		err = addTarEntry(tarWriter, entry, options)
+		if err != nil {
+			return fmt.Errorf("cannot add %s to archive: %s", entry.name, err)
+		}
+	}
+	err = tarWriter.Close()
+	if err != nil {
+		return err
+	}
+	err = compressWriter.Close()
+	if err != nil {
+		return err
+	}
+	return archiveFile.Close()
+}
+
+// newCompressWriter
+// Returns writer which compresses data written to writer by the compression of the format.
+func newCompressWriter(writer io.Writer, format ArchiveFormat) (io.WriteCloser, error) {
+	switch format {
+	case FormatTarGz:
+		return gzip.NewWriter(writer), nil
+	case FormatTarZst:
+		return zstd.NewWriter(writer, zstd.WithEncoderConcurrency(1))
+	default:
+		return nil, fmt.Errorf("format %s is not a tar format", format)
+	}
+}
+
+// writeTarMetadata
+// Writes Metadata as a record of the PAX global header, which is not extracted as a file.
+func writeTarMetadata(tarWriter *tar.Writer, options tarArchiveOptions) error {
+	mbytes, err := json.Marshal(options.metadata)
+	if err != nil {
+		return err
+	}
+	header := tar.Header{
+		Typeflag:   tar.TypeXGlobalHeader,
+		Name:       tarGlobalHeaderNameConst,
+		PAXRecords: map[string]string{tarMetadataRecordConst: string(mbytes)},
+	}
+	if options.reproducible {
+		header.ModTime = options.modTime
+	}
+	return tarWriter.WriteHeader(&header)
+}
+
+// addTarEntry
+// Adds the entry to the tar archive.
+func addTarEntry(tarWriter *tar.Writer, entry archiveEntry, options tarArchiveOptions) error {
+	mode := entry.info.Mode()
+	linkTarget := ""
+	if mode&fs.ModeSymlink != 0 {
+		var err error
+		linkTarget, err = os.Readlink(entry.path)
+		if err != nil {
+			return err
+		}
+	} else if !mode.IsDir() && !mode.IsRegular() {
+		return fmt.Errorf("unsupported file type %s", mode.Type())
+	}
+	header, err := tar.FileInfoHeader(entry.info, linkTarget)
+	if err != nil {
+		return err
+	}
+	header.Name = entry.name
+	if mode.IsDir() {
+		header.Name += "/"
+	}
+	header.Format = tar.FormatPAX
+	if options.reproducible {
+		header.Mode = int64(normalizedMode(mode).Perm())
+		header.ModTime = options.modTime
+		header.AccessTime = time.Time{}
+		header.ChangeTime = time.Time{}
+		header.Uid = 0
+		header.Gid = 0
+		header.Uname = ""
+		header.Gname = ""
+	}
+	err = tarWriter.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+	if !mode.IsRegular() {
+		return nil
+	}
+	file, err := os.Open(entry.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(tarWriter, file)
+	return err
+}
+
+// tarArchiveReader
+// Reader of the Package tar archive which closes the archive file and the decompressor.
+type tarArchiveReader struct {
+	*tar.Reader
+	closers []func() error
+}
+
+func (reader *tarArchiveReader) Close() error {
+	var err error
+	for i := len(reader.closers) - 1; i >= 0; i-- {
+		closeErr := reader.closers[i]()
+		if err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// openTarArchive
+// Opens the Package tar archive of the given format for reading.
+func openTarArchive(archivePath string, format ArchiveFormat) (*tarArchiveReader, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	reader := tarArchiveReader{closers: []func() error{file.Close}}
+	var decompressReader io.Reader
+	switch format {
+	case FormatTarGz:
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			reader.Close()
+			return nil, err
+		}
+		reader.closers = append(reader.closers, gzipReader.Close)
+		decompressReader = gzipReader
+	case FormatTarZst:
+		zstdReader, err := zstd.NewReader(file)
+		if err != nil {
+			reader.Close()
+			return nil, err
+		}
+		reader.closers = append(reader.closers, func() error {
+			zstdReader.Close()
+			return nil
+		})
+		decompressReader = zstdReader
+	default:
+		reader.Close()
+		return nil, fmt.Errorf("format %s is not a tar format", format)
+	}
+	reader.Reader = tar.NewReader(decompressReader)
+	return &reader, nil
+}
+
+// readTarMetadata
+// Reads Metadata from the PAX global header of the Package tar archive. Returns nil Metadata if
+// the archive has no Metadata.
+func readTarMetadata(archivePath string, format ArchiveFormat) (*Metadata, error) {
+	reader, err := openTarArchive(archivePath, format)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	header, err := reader.Next()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	record, found := header.PAXRecords[tarMetadataRecordConst]
+	if header.Typeflag != tar.TypeXGlobalHeader || !found {
+		return nil, nil
+	}
+	var metadata Metadata
+	err = json.Unmarshal([]byte(record), &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata of %s - %s", archivePath, err)
+	}
+	return &metadata, nil
+}
+
+// readTarArchiveFile
+// Tar variant of readArchiveFile.
+func readTarArchiveFile(archivePath string, format ArchiveFormat, match func(name string) bool) (string, []byte, error) {
+	reader, err := openTarArchive(archivePath, format)
+	if err != nil {
+		return "", nil, err
+	}
+	defer reader.Close()
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return "", nil, nil
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		name := strings.TrimPrefix(header.Name, "./")
+		if header.Typeflag != tar.TypeReg || !match(name) {
+			continue
+		}
+		mbytes, err := io.ReadAll(reader)
+		if err != nil {
+			return "", nil, err
+		}
+		return name, mbytes, nil
+	}
+}
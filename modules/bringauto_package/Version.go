@@ -0,0 +1,186 @@
+package bringauto_package
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Version
+// Version parsed from the VersionTag - semantic version with optional fourth part
+// (v<major>.<minor>.<patch>[.<revision>][-<pre-release>][+<build>]), e.g. v1.2.3-rc1,
+// v2024.10.1.2 or v1.2.3+ba1.
+type Version struct {
+	Major       uint64
+	Minor       uint64
+	Patch       uint64
+	// Revision optional fourth part of the version (package revision or the fourth part of the
+	// upstream version), 0 if not given
+	Revision    uint64
+	// HasRevision true if the fourth part of the version is given
+	HasRevision bool
+	// PreRelease dot separated pre-release identifiers, empty for release versions
+	PreRelease  string
+	// Build dot separated build metadata identifiers (e.g. rebuild suffix ba1), empty if not given
+	Build       string
+}
+
+var versionRegexp = regexp.MustCompile(`^v?([0-9]+)\.([0-9]+)\.([0-9]+)(?:\.([0-9]+))?(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+// ParseVersion
+// Parses version string with optional leading 'v'.
+func ParseVersion(versionString string) (Version, error) {
+	match := versionRegexp.FindStringSubmatch(versionString)
+	if match == nil {
+		return Version{}, fmt.Errorf("'%s' is not a valid version", versionString)
+	}
+	var version Version
+	var err error
+	for i, number := range []*uint64{&version.Major, &version.Minor, &version.Patch, &version.Revision} {
+		if match[i+1] == "" {
+			continue
+		}
+		*number, err = strconv.ParseUint(match[i+1], 10, 64)
+		if err != nil {
+			return Version{}, fmt.Errorf("'%s' is not a valid version - %s", versionString, err)
+		}
+	}
+	version.HasRevision = match[4] != ""
+	version.PreRelease = match[5]
+	version.Build = match[6]
+	for _, identifiers := range []string{version.PreRelease, version.Build} {
+		if identifiers != "" && slices.Contains(strings.Split(identifiers, "."), "") {
+			return Version{}, fmt.Errorf("'%s' is not a valid version - empty identifier", versionString)
+		}
+	}
+	return version, nil
+}
+
+// ParseVersionTag
+// Parses the VersionTag of the Package, the leading 'v' is required.
+func ParseVersionTag(versionTag string) (Version, error) {
+	if !strings.HasPrefix(versionTag, "v") {
+		return Version{}, fmt.Errorf("'%s' is not a valid version tag - it must start with 'v'", versionTag)
+	}
+	return ParseVersion(versionTag)
+}
+
+// CompareVersionTags
+// Compares two VersionTags by Version.Compare. VersionTags which are not valid versions are
+// compared as strings.
+func CompareVersionTags(versionTag string, other string) int {
+	version, err := ParseVersion(versionTag)
+	if err != nil {
+		return strings.Compare(versionTag, other)
+	}
+	otherVersion, err := ParseVersion(other)
+	if err != nil {
+		return strings.Compare(versionTag, other)
+	}
+	return version.Compare(otherVersion)
+}
+
+// Compare
+// Returns -1 if version is lower than other, 1 if it is greater and 0 if both are equal.
+// Versions are ordered by ComparePrecedence, versions with the same precedence are ordered by
+// the build metadata (version without build metadata is the lowest), so rebuilds of the same
+// version are ordered too.
+func (version Version) Compare(other Version) int {
+	result := version.ComparePrecedence(other)
+	if result != 0 {
+		return result
+	}
+	if version.Build == other.Build {
+		return 0
+	}
+	if version.Build == "" {
+		return -1
+	}
+	if other.Build == "" {
+		return 1
+	}
+	return compareIdentifiers(version.Build, other.Build)
+}
+
+// ComparePrecedence
+// Returns -1 if version is lower than other, 1 if it is greater and 0 if both are equal.
+// Versions are ordered by the semantic versioning precedence, the fourth part is compared after
+// the patch version (missing fourth part is 0) and a pre-release version is lower than the release
+// version. Build metadata are ignored.
+func (version Version) ComparePrecedence(other Version) int {
+	result := cmp.Compare(version.Major, other.Major)
+	if result == 0 {
+		result = cmp.Compare(version.Minor, other.Minor)
+	}
+	if result == 0 {
+		result = cmp.Compare(version.Patch, other.Patch)
+	}
+	if result == 0 {
+		result = cmp.Compare(version.Revision, other.Revision)
+	}
+	if result != 0 {
+		return result
+	}
+	return comparePreRelease(version.PreRelease, other.PreRelease)
+}
+
+func (version Version) String() string {
+	versionString := fmt.Sprintf("%d.%d.%d", version.Major, version.Minor, version.Patch)
+	if version.HasRevision {
+		versionString += fmt.Sprintf(".%d", version.Revision)
+	}
+	if version.PreRelease != "" {
+		versionString += "-" + version.PreRelease
+	}
+	if version.Build != "" {
+		versionString += "+" + version.Build
+	}
+	return versionString
+}
+
+// comparePreRelease
+// Compares pre-release parts of two versions. Empty pre-release is greater than any other.
+func comparePreRelease(preRelease string, other string) int {
+	if preRelease == other {
+		return 0
+	}
+	if preRelease == "" {
+		return 1
+	}
+	if other == "" {
+		return -1
+	}
+	return compareIdentifiers(preRelease, other)
+}
+
+// compareIdentifiers
+// Compares dot separated identifiers one by one, numeric identifiers are compared numerically and
+// are lower than alphanumeric. If all common identifiers are equal, the longer list is greater.
+func compareIdentifiers(identifiersString string, otherString string) int {
+	identifiers := strings.Split(identifiersString, ".")
+	otherIdentifiers := strings.Split(otherString, ".")
+	for i := 0; i < len(identifiers) && i < len(otherIdentifiers); i++ {
+		number, err := strconv.ParseUint(identifiers[i], 10, 64)
+		isNumber := err == nil
+		otherNumber, err := strconv.ParseUint(otherIdentifiers[i], 10, 64)
+		isOtherNumber := err == nil
+		var result int
+		switch {
+		case isNumber && isOtherNumber:
+			result = cmp.Compare(number, otherNumber)
+		case isNumber:
+			result = -1
+		case isOtherNumber:
+			result = 1
+		default:
+			result = strings.Compare(identifiers[i], otherIdentifiers[i])
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return cmp.Compare(len(identifiers), len(otherIdentifiers))
+}
@@ -0,0 +1,206 @@
+package bringauto_package
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Separator of alternatives in the version constraint
+	constraintOrConst = "||"
+)
+
+// VersionConstraint
+// Range of allowed versions, e.g. ">=1.2.11 <1.3". Comparators separated by whitespace must be
+// satisfied all, alternatives are separated by '||'. Supported comparators are
+// =, !=, >, >=, <, <=, ~ (same minor version) and ^ (same major version, the first non-zero part
+// for 0.x versions). Version without operator means exact version. Versions can be partial
+// (1, 1.2), missing parts match any value (=1.2 is >=1.2.0 <1.3.0, =1.2.3 matches also
+// 1.2.3.1). Build metadata of the checked version are ignored.
+type VersionConstraint struct {
+	constraint   string
+	alternatives [][]versionComparator
+}
+
+// versionComparator
+// Single comparison of the version with the given version.
+type versionComparator struct {
+	operator string
+	version  Version
+}
+
+var (
+	constraintOperatorRegexp = regexp.MustCompile(`^(==|=|!=|>=|>|<=|<|~|\^)?(.*)$`)
+	partialVersionRegexp     = regexp.MustCompile(`^v?([0-9]+)(?:\.([0-9]+)(?:\.([0-9]+)(?:\.([0-9]+))?(?:-([0-9A-Za-z.-]+))?)?)?$`)
+)
+
+// ParseVersionConstraint
+// Parses the version constraint. Whitespace between the operator and the version is allowed.
+func ParseVersionConstraint(constraint string) (*VersionConstraint, error) {
+	versionConstraint := VersionConstraint{}
+	var normalized []string
+	for _, alternative := range strings.Split(constraint, constraintOrConst) {
+		var comparators []versionComparator
+		var tokens []string
+		for _, field := range strings.Fields(alternative) {
+			if len(tokens) > 0 && constraintOperatorRegexp.FindStringSubmatch(tokens[len(tokens)-1])[2] == "" {
+				// Operator separated from the version by whitespace
+				tokens[len(tokens)-1] += field
+				continue
+			}
+			tokens = append(tokens, field)
+		}
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("empty version constraint in '%s'", constraint)
+		}
+		for _, token := range tokens {
+			tokenComparators, err := parseComparator(token)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version constraint '%s' - %s", constraint, err)
+			}
+			comparators = append(comparators, tokenComparators...)
+		}
+		versionConstraint.alternatives = append(versionConstraint.alternatives, comparators)
+		normalized = append(normalized, strings.Join(tokens, " "))
+	}
+	versionConstraint.constraint = strings.Join(normalized, " "+constraintOrConst+" ")
+	return &versionConstraint, nil
+}
+
+// Check
+// Returns true if the version satisfies the constraint, else returns false.
+func (constraint *VersionConstraint) Check(version Version) bool {
+	for _, comparators := range constraint.alternatives {
+		satisfied := true
+		for _, comparator := range comparators {
+			if !comparator.check(version) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckVersionTag
+// Returns true if the versionTag satisfies the constraint, else returns false. Returns error if
+// the versionTag is not a valid version.
+func (constraint *VersionConstraint) CheckVersionTag(versionTag string) (bool, error) {
+	version, err := ParseVersion(versionTag)
+	if err != nil {
+		return false, err
+	}
+	return constraint.Check(version), nil
+}
+
+func (constraint *VersionConstraint) String() string {
+	return constraint.constraint
+}
+
+func (comparator versionComparator) check(version Version) bool {
+	result := version.ComparePrecedence(comparator.version)
+	switch comparator.operator {
+	case "=":
+		return result == 0
+	case "!=":
+		return result != 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	default:
+		return result <= 0
+	}
+}
+
+// parseComparator
+// Parses one comparator of the version constraint and returns equivalent comparators with
+// full versions.
+func parseComparator(token string) ([]versionComparator, error) {
+	operatorMatch := constraintOperatorRegexp.FindStringSubmatch(token)
+	operator := operatorMatch[1]
+	versionMatch := partialVersionRegexp.FindStringSubmatch(operatorMatch[2])
+	if versionMatch == nil {
+		return nil, fmt.Errorf("'%s' is not a valid version", operatorMatch[2])
+	}
+	var version Version
+	parts := 0
+	for i, number := range []*uint64{&version.Major, &version.Minor, &version.Patch, &version.Revision} {
+		if versionMatch[i+1] == "" {
+			break
+		}
+		var err error
+		*number, err = strconv.ParseUint(versionMatch[i+1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid version - %s", operatorMatch[2], err)
+		}
+		parts++
+	}
+	version.HasRevision = parts == 4
+	version.PreRelease = versionMatch[5]
+	// Version with the fourth part or the pre-release is not extended by the missing parts
+	isFull := parts == 4 || version.PreRelease != ""
+
+	lowest := version
+	lowest.PreRelease = "0"
+	switch operator {
+	case "", "=", "==":
+		if isFull {
+			return []versionComparator{{"=", version}}, nil
+		}
+		return []versionComparator{{">=", version}, {"<", nextVersion(version, parts)}}, nil
+	case "!=":
+		if parts < 3 {
+			return nil, fmt.Errorf("'%s' - full version is required for !=", token)
+		}
+		return []versionComparator{{"!=", version}}, nil
+	case ">":
+		if isFull {
+			return []versionComparator{{">", version}}, nil
+		}
+		return []versionComparator{{">=", nextVersion(version, parts)}}, nil
+	case ">=":
+		return []versionComparator{{">=", version}}, nil
+	case "<":
+		if parts >= 3 {
+			return []versionComparator{{"<", version}}, nil
+		}
+		return []versionComparator{{"<", lowest}}, nil
+	case "<=":
+		if isFull {
+			return []versionComparator{{"<=", version}}, nil
+		}
+		return []versionComparator{{"<", nextVersion(version, parts)}}, nil
+	case "~":
+		return []versionComparator{{">=", version}, {"<", nextVersion(version, min(parts, 2))}}, nil
+	default:
+		caretParts := 3
+		if version.Major > 0 || parts == 1 {
+			caretParts = 1
+		} else if version.Minor > 0 || parts == 2 {
+			caretParts = 2
+		}
+		return []versionComparator{{">=", version}, {"<", nextVersion(version, caretParts)}}, nil
+	}
+}
+
+// nextVersion
+// Returns the lowest version (including pre-releases) which is greater than all versions with the
+// same first parts of the version.
+func nextVersion(version Version, parts int) Version {
+	switch parts {
+	case 1:
+		return Version{Major: version.Major + 1, PreRelease: "0"}
+	case 2:
+		return Version{Major: version.Major, Minor: version.Minor + 1, PreRelease: "0"}
+	default:
+		return Version{Major: version.Major, Minor: version.Minor, Patch: version.Patch + 1, PreRelease: "0"}
+	}
+}
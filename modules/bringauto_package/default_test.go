@@ -0,0 +1,615 @@
+package bringauto_package_test
+
+import (
+	"archive/tar"
+	"bringauto/modules/bringauto_package"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testPlatformString = bringauto_package.PlatformString{
+	Mode: bringauto_package.ModeExplicit,
+	String: bringauto_package.PlatformStringExplicit{
+		DistroName:    "ubuntu",
+		DistroRelease: "2204",
+		Machine:       "x86_64",
+	},
+}
+
+// createPackage
+// Creates Package zip archive with one file in a temporary directory and returns its path.
+func createPackage(t *testing.T, pack *bringauto_package.Package) string {
+	sourceDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(sourceDir, "file.txt"), []byte("content"), 0644)
+	if err != nil {
+		t.Fatalf("can't create file - %s", err)
+	}
+	outputDir := t.TempDir()
+	err = pack.CreatePackage(sourceDir, outputDir)
+	if err != nil {
+		t.Fatalf("CreatePackage failed - %s", err)
+	}
+	return filepath.Join(outputDir, pack.GetFullPackageName()+bringauto_package.ZipExt)
+}
+
+func TestCreatePackageMetadata(t *testing.T) {
+	pack := bringauto_package.Package{
+		Name:           "pack1",
+		VersionTag:     "v1.0.0",
+		PlatformString: testPlatformString,
+		Metadata: &bringauto_package.Metadata{
+			GitURI:    "https://github.com/bringauto/pack1.git",
+			GitCommit: "0123456789abcdef0123456789abcdef01234567",
+		},
+	}
+	archivePath := createPackage(t, &pack)
+	metadata, err := bringauto_package.ReadMetadata(archivePath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed - %s", err)
+	}
+	if metadata == nil || *metadata != *pack.Metadata {
+		t.Errorf("wrong metadata read - %v", metadata)
+	}
+}
+
+func TestCreatePackageNoMetadata(t *testing.T) {
+	pack := bringauto_package.Package{
+		Name:           "pack1",
+		VersionTag:     "v1.0.0",
+		PlatformString: testPlatformString,
+	}
+	archivePath := createPackage(t, &pack)
+	metadata, err := bringauto_package.ReadMetadata(archivePath)
+	if err != nil {
+		t.Fatalf("ReadMetadata failed - %s", err)
+	}
+	if metadata != nil {
+		t.Errorf("unexpected metadata read - %v", metadata)
+	}
+}
+
+func TestCreatePackageManifest(t *testing.T) {
+	pack := bringauto_package.Package{
+		Name:           "pack1",
+		VersionTag:     "v1.0.0",
+		PlatformString: testPlatformString,
+		IsLibrary:      true,
+	}
+	pack.Manifest = &bringauto_package.Manifest{
+		Name:         pack.Name,
+		VersionTag:   pack.VersionTag,
+		IsLibrary:    true,
+		CMakeDefines: map[string]string{"CMAKE_BUILD_TYPE": "Release"},
+		Dependencies: []bringauto_package.ManifestDependency{{Name: "pack2", VersionTag: "v2.0.0"}},
+	}
+	archivePath := createPackage(t, &pack)
+	manifest, err := bringauto_package.ReadManifest(archivePath)
+	if err != nil {
+		t.Fatalf("ReadManifest failed - %s", err)
+	}
+	if !reflect.DeepEqual(manifest, pack.Manifest) {
+		t.Errorf("wrong manifest read - %v", manifest)
+	}
+	if pack.GetManifestPath() != "share/bap/libpack1_v1.0.0.manifest.json" {
+		t.Errorf("wrong manifest path - %s", pack.GetManifestPath())
+	}
+}
+
+func TestReadManifestMissing(t *testing.T) {
+	pack := bringauto_package.Package{
+		Name:           "pack1",
+		VersionTag:     "v1.0.0",
+		PlatformString: testPlatformString,
+	}
+	archivePath := createPackage(t, &pack)
+	_, err := bringauto_package.ReadManifest(archivePath)
+	if err == nil {
+		t.Error("missing manifest not detected")
+	}
+}
+
+// createReproducibleSource
+// Creates source directory with files created in the given order and with the given mtime.
+func createReproducibleSource(t *testing.T, names []string, mode os.FileMode, modTime time.Time) string {
+	sourceDir := t.TempDir()
+	for _, name := range names {
+		filePath := filepath.Join(sourceDir, name)
+		err := os.MkdirAll(filepath.Dir(filePath), 0700)
+		if err != nil {
+			t.Fatalf("can't create directory - %s", err)
+		}
+		err = os.WriteFile(filePath, []byte("content of "+name), mode)
+		if err != nil {
+			t.Fatalf("can't create file - %s", err)
+		}
+		err = os.Chtimes(filePath, modTime, modTime)
+		if err != nil {
+			t.Fatalf("can't set file time - %s", err)
+		}
+	}
+	return sourceDir
+}
+
+func TestCreatePackageReproducible(t *testing.T) {
+	epoch := time.Unix(1700000000, 0).UTC()
+	sources := []string{
+		createReproducibleSource(t, []string{"lib/liba.so", "include/a.h", "bin/tool"}, 0600, time.Now()),
+		createReproducibleSource(t, []string{"bin/tool", "include/a.h", "lib/liba.so"}, 0640, time.Unix(1000, 0)),
+	}
+	for _, format := range bringauto_package.ArchiveFormats() {
+		var archives [][]byte
+		for _, sourceDir := range sources {
+			pack := bringauto_package.Package{
+				Name:            "pack1",
+				VersionTag:      "v1.0.0",
+				PlatformString:  testPlatformString,
+				Format:          format,
+				Reproducible:    true,
+				SourceDateEpoch: epoch,
+			}
+			outputDir := t.TempDir()
+			err := pack.CreatePackage(sourceDir, outputDir)
+			if err != nil {
+				t.Fatalf("CreatePackage failed - %s", err)
+			}
+			mbytes, err := os.ReadFile(filepath.Join(outputDir, pack.GetArchiveName()))
+			if err != nil {
+				t.Fatalf("can't read archive - %s", err)
+			}
+			archives = append(archives, mbytes)
+		}
+		if !bytes.Equal(archives[0], archives[1]) {
+			t.Errorf("reproducible %s archives of the same content differ", format)
+		}
+	}
+}
+
+func TestCreatePackageTarFormats(t *testing.T) {
+	for _, format := range []bringauto_package.ArchiveFormat{bringauto_package.FormatTarGz, bringauto_package.FormatTarZst} {
+		pack := bringauto_package.Package{
+			Name:           "pack1",
+			VersionTag:     "v1.0.0",
+			PlatformString: testPlatformString,
+			Format:         format,
+			Metadata: &bringauto_package.Metadata{
+				GitURI:    "https://github.com/bringauto/pack1.git",
+				GitCommit: "0123456789abcdef0123456789abcdef01234567",
+			},
+			Manifest: &bringauto_package.Manifest{Name: "pack1", VersionTag: "v1.0.0"},
+		}
+		sourceDir := createReproducibleSource(t, []string{"lib/liba.so.1"}, 0755, time.Now())
+		err := os.Symlink("liba.so.1", filepath.Join(sourceDir, "lib", "liba.so"))
+		if err != nil {
+			t.Fatalf("can't create symlink - %s", err)
+		}
+		outputDir := t.TempDir()
+		err = pack.CreatePackage(sourceDir, outputDir)
+		if err != nil {
+			t.Fatalf("CreatePackage failed - %s", err)
+		}
+		archivePath := filepath.Join(outputDir, pack.GetArchiveName())
+		if !strings.HasSuffix(archivePath, format.Ext()) {
+			t.Errorf("wrong archive name - %s", archivePath)
+		}
+
+		metadata, err := bringauto_package.ReadMetadata(archivePath)
+		if err != nil {
+			t.Fatalf("ReadMetadata failed - %s", err)
+		}
+		if metadata == nil || *metadata != *pack.Metadata {
+			t.Errorf("wrong metadata read - %v", metadata)
+		}
+		manifest, err := bringauto_package.ReadManifest(archivePath)
+		if err != nil {
+			t.Fatalf("ReadManifest failed - %s", err)
+		}
+		if !reflect.DeepEqual(manifest, pack.Manifest) {
+			t.Errorf("wrong manifest read - %v", manifest)
+		}
+
+		extractDir := t.TempDir()
+		err = bringauto_package.ExtractArchive(archivePath, extractDir)
+		if err != nil {
+			t.Fatalf("ExtractArchive failed - %s", err)
+		}
+		target, err := os.Readlink(filepath.Join(extractDir, "lib", "liba.so"))
+		if err != nil || target != "liba.so.1" {
+			t.Errorf("symlink not preserved - %s %v", target, err)
+		}
+		info, err := os.Stat(filepath.Join(extractDir, "lib", "liba.so.1"))
+		if err != nil || info.Mode().Perm()&0100 == 0 {
+			t.Errorf("permissions not preserved - %v", err)
+		}
+	}
+}
+
+func TestCreatePackageDbgsym(t *testing.T) {
+	debugFile := "lib/debug/.build-id/ab/cdef.debug"
+	pack := bringauto_package.Package{
+		Name:            "pack1",
+		VersionTag:      "v1.0.0",
+		PlatformString:  testPlatformString,
+		Format:          bringauto_package.FormatTarGz,
+		Manifest:        &bringauto_package.Manifest{Name: "pack1", VersionTag: "v1.0.0"},
+		DebugSymbolsDir: createReproducibleSource(t, []string{debugFile}, 0644, time.Now()),
+	}
+	sourceDir := createReproducibleSource(t, []string{"lib/liba.so.1"}, 0755, time.Now())
+	outputDir := t.TempDir()
+	err := pack.CreatePackage(sourceDir, outputDir)
+	if err != nil {
+		t.Fatalf("CreatePackage failed - %s", err)
+	}
+	if pack.GetDbgsymArchiveName() != "pack1_v1.0.0_x86_64-ubuntu-2204-dbgsym.tar.gz" {
+		t.Errorf("wrong debug symbols archive name - %s", pack.GetDbgsymArchiveName())
+	}
+
+	extractDir := t.TempDir()
+	err = bringauto_package.ExtractArchive(filepath.Join(outputDir, pack.GetDbgsymArchiveName()), extractDir)
+	if err != nil {
+		t.Fatalf("ExtractArchive failed - %s", err)
+	}
+	_, err = os.Stat(filepath.Join(extractDir, debugFile))
+	if err != nil {
+		t.Errorf("debug file not archived - %s", err)
+	}
+	_, err = os.Stat(filepath.Join(extractDir, "lib", "liba.so.1"))
+	if !os.IsNotExist(err) {
+		t.Error("installed file archived to the debug symbols archive")
+	}
+}
+
+func TestParseArchiveFormat(t *testing.T) {
+	format, err := bringauto_package.ParseArchiveFormat("")
+	if err != nil || format != bringauto_package.FormatZip {
+		t.Errorf("wrong default format - %s %v", format, err)
+	}
+	format, err = bringauto_package.ParseArchiveFormat("tar.zst")
+	if err != nil || format != bringauto_package.FormatTarZst {
+		t.Errorf("wrong format - %s %v", format, err)
+	}
+	_, err = bringauto_package.ParseArchiveFormat("rar")
+	if err == nil {
+		t.Error("unsupported format not detected")
+	}
+}
+
+// createNativePackage
+// Creates Package with a library, its symlink and the native package of the given format.
+// Returns content of the native package.
+func createNativePackage(t *testing.T, format bringauto_package.NativeFormat) []byte {
+	pack := bringauto_package.Package{
+		Name:           "pack1",
+		VersionTag:     "v1.2.3",
+		PlatformString: testPlatformString,
+		IsLibrary:      true,
+		Native: &bringauto_package.NativePackage{
+			Format: format,
+			Prefix: "/opt/bap",
+			Dependencies: []bringauto_package.Package{
+				{Name: "pack2", VersionTag: "v2.0.0", IsLibrary: true},
+			},
+		},
+	}
+	sourceDir := createReproducibleSource(t, []string{"lib/libpack1.so.1"}, 0755, time.Now())
+	err := os.Symlink("libpack1.so.1", filepath.Join(sourceDir, "lib", "libpack1.so"))
+	if err != nil {
+		t.Fatalf("can't create symlink - %s", err)
+	}
+	outputDir := t.TempDir()
+	err = pack.CreatePackage(sourceDir, outputDir)
+	if err != nil {
+		t.Fatalf("CreatePackage failed - %s", err)
+	}
+	if pack.GetNativeName() != "libpack1" || pack.GetNativeVersion(format) != "1.2.3" {
+		t.Errorf("wrong native name or version - %s %s", pack.GetNativeName(), pack.GetNativeVersion(format))
+	}
+	mbytes, err := os.ReadFile(filepath.Join(outputDir, pack.GetNativeArchiveName(format)))
+	if err != nil {
+		t.Fatalf("can't read native package - %s", err)
+	}
+	return mbytes
+}
+
+func TestCreatePackageDeb(t *testing.T) {
+	mbytes := createNativePackage(t, bringauto_package.NativeFormatDeb)
+	if !bytes.HasPrefix(mbytes, []byte("!<arch>\ndebian-binary   ")) {
+		t.Fatal("Debian package is not an ar archive")
+	}
+	// control.tar.gz is the second member of the ar archive
+	offset := 8 + 60 + 4
+	size, err := strconv.Atoi(strings.TrimSpace(string(mbytes[offset+48 : offset+58])))
+	if err != nil {
+		t.Fatalf("invalid ar header - %s", err)
+	}
+	gzipReader, err := gzip.NewReader(bytes.NewReader(mbytes[offset+60 : offset+60+size]))
+	if err != nil {
+		t.Fatalf("invalid control.tar.gz - %s", err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	control := ""
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			break
+		}
+		if header.Name == "./control" {
+			content, _ := io.ReadAll(tarReader)
+			control = string(content)
+		}
+	}
+	for _, field := range []string{"Package: libpack1\n", "Version: 1.2.3-1\n", "Architecture: amd64\n", "Depends: libpack2 (= 2.0.0-1)\n"} {
+		if !strings.Contains(control, field) {
+			t.Errorf("control file does not contain %q:\n%s", field, control)
+		}
+	}
+}
+
+func TestCreatePackageRpm(t *testing.T) {
+	mbytes := createNativePackage(t, bringauto_package.NativeFormatRpm)
+	if !bytes.HasPrefix(mbytes, []byte{0xed, 0xab, 0xee, 0xdb, 3, 0}) {
+		t.Fatal("RPM package has no lead")
+	}
+	if !bytes.HasPrefix(mbytes[10:], []byte("libpack1-1.2.3-1\x00")) {
+		t.Errorf("wrong name in the lead - %q", mbytes[10:30])
+	}
+	for _, value := range []string{"x86_64", "libpack2\x00", "2.0.0-1\x00", "/opt/bap/lib/\x00", "libpack1.so.1\x00"} {
+		if !bytes.Contains(mbytes, []byte(value)) {
+			t.Errorf("RPM package does not contain %q", value)
+		}
+	}
+}
+
+func TestParseNativeFormat(t *testing.T) {
+	format, err := bringauto_package.ParseNativeFormat("auto", &testPlatformString)
+	if err != nil || format != bringauto_package.NativeFormatDeb {
+		t.Errorf("wrong format of ubuntu - %s %v", format, err)
+	}
+	fedora := bringauto_package.PlatformString{
+		Mode:   bringauto_package.ModeExplicit,
+		String: bringauto_package.PlatformStringExplicit{DistroName: "fedora", DistroRelease: "40", Machine: "aarch64"},
+	}
+	format, err = bringauto_package.ParseNativeFormat("auto", &fedora)
+	if err != nil || format != bringauto_package.NativeFormatRpm {
+		t.Errorf("wrong format of fedora - %s %v", format, err)
+	}
+	_, err = bringauto_package.ParseNativeFormat("apk", &fedora)
+	if err == nil {
+		t.Error("unsupported format not detected")
+	}
+}
+
+func TestSignArchive(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("can't generate key - %s", err)
+	}
+	otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("can't generate key - %s", err)
+	}
+	pack := bringauto_package.Package{Name: "pack1", VersionTag: "v1.0.0", PlatformString: testPlatformString}
+	archivePath := createPackage(t, &pack)
+
+	err = bringauto_package.VerifyArchive(archivePath, []ed25519.PublicKey{publicKey})
+	if err == nil {
+		t.Error("unsigned archive verified")
+	}
+	err = bringauto_package.SignArchive(archivePath, privateKey)
+	if err != nil {
+		t.Fatalf("SignArchive failed - %s", err)
+	}
+	err = bringauto_package.VerifyArchive(archivePath, []ed25519.PublicKey{otherKey, publicKey})
+	if err != nil {
+		t.Errorf("VerifyArchive failed - %s", err)
+	}
+	err = bringauto_package.VerifyArchive(archivePath, []ed25519.PublicKey{otherKey})
+	if err == nil {
+		t.Error("archive verified by untrusted key")
+	}
+
+	file, err := os.OpenFile(archivePath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("can't open archive - %s", err)
+	}
+	_, err = file.Write([]byte("tampered"))
+	file.Close()
+	if err != nil {
+		t.Fatalf("can't write archive - %s", err)
+	}
+	err = bringauto_package.VerifyArchive(archivePath, []ed25519.PublicKey{publicKey})
+	if err == nil {
+		t.Error("tampered archive verified")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("can't generate key - %s", err)
+	}
+	privateDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("can't marshal private key - %s", err)
+	}
+	publicDer, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("can't marshal public key - %s", err)
+	}
+	privatePem := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDer})
+	publicPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer})
+
+	parsedPrivateKey, err := bringauto_package.ParsePrivateKey(privatePem)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey failed - %s", err)
+	}
+	if !parsedPrivateKey.Equal(privateKey) {
+		t.Error("wrong private key parsed")
+	}
+	parsedPublicKeys, err := bringauto_package.ParsePublicKeys(append(publicPem, publicPem...))
+	if err != nil {
+		t.Fatalf("ParsePublicKeys failed - %s", err)
+	}
+	if len(parsedPublicKeys) != 2 || !parsedPublicKeys[1].Equal(publicKey) {
+		t.Errorf("wrong public keys parsed - %v", parsedPublicKeys)
+	}
+	_, err = bringauto_package.ParsePublicKeys(privatePem)
+	if err == nil {
+		t.Error("private key parsed as public key")
+	}
+	_, err = bringauto_package.ParsePublicKeys([]byte("no key"))
+	if err == nil {
+		t.Error("missing public key not detected")
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	versions := []string{"v0.9.0", "v1.0.0-0", "v1.0.0-alpha", "v1.0.0-alpha.1", "v1.0.0-alpha.beta",
+		"v1.0.0-beta.2", "v1.0.0-beta.11", "v1.0.0-rc.1", "v1.0.0", "v1.0.0+ba1", "v1.0.0+ba2", "v1.0.0.1-rc1",
+		"v1.0.0.1", "v1.2.0", "v1.10.0", "v2.0.0", "v2024.10.1.2"}
+	for i := 1; i < len(versions); i++ {
+		lower, err := bringauto_package.ParseVersion(versions[i-1])
+		if err != nil {
+			t.Fatalf("ParseVersion failed - %s", err)
+		}
+		greater, err := bringauto_package.ParseVersion(versions[i])
+		if err != nil {
+			t.Fatalf("ParseVersion failed - %s", err)
+		}
+		if lower.Compare(greater) != -1 || greater.Compare(lower) != 1 || greater.Compare(greater) != 0 {
+			t.Errorf("wrong order of %s and %s", lower, greater)
+		}
+		if "v" + greater.String() != versions[i] {
+			t.Errorf("wrong string of %s - %s", versions[i], greater)
+		}
+	}
+	rebuild, err := bringauto_package.ParseVersion("v1.0.0+ba1")
+	if err != nil {
+		t.Fatalf("ParseVersion failed - %s", err)
+	}
+	if rebuild.ComparePrecedence(bringauto_package.Version{Major: 1}) != 0 {
+		t.Errorf("build metadata of %s are not ignored", rebuild)
+	}
+	for _, version := range []string{"v1.0", "1.0.0.0.0", "v1.0.0-", "v1.0.0+", "v1.0.0-rc..1", "va.b.c"} {
+		_, err := bringauto_package.ParseVersion(version)
+		if err == nil {
+			t.Errorf("invalid version %s parsed", version)
+		}
+	}
+}
+
+func TestVersionConstraint(t *testing.T) {
+	constraints := map[string]struct {
+		satisfied    []string
+		notSatisfied []string
+	}{
+		">=1.2.11 <1.3":    {[]string{"v1.2.11", "v1.2.99"}, []string{"v1.2.10", "v1.3.0", "v1.3.0-rc1"}},
+		"1.2":              {[]string{"v1.2.0", "v1.2.5"}, []string{"v1.1.9", "v1.3.0"}},
+		"=1.2.3":           {[]string{"v1.2.3", "v1.2.3.4", "v1.2.3+ba1"}, []string{"v1.2.4", "v1.2.3-rc1"}},
+		"=2024.10.1.2":     {[]string{"v2024.10.1.2"}, []string{"v2024.10.1.3", "v2024.10.1"}},
+		"=1.2.3-rc1":       {[]string{"v1.2.3-rc1"}, []string{"v1.2.3"}},
+		">1.2.3":           {[]string{"v1.2.4-rc1", "v1.3.0"}, []string{"v1.2.3.5", "v1.2.3+ba1"}},
+		"<=1.2.3":          {[]string{"v1.2.3.9", "v1.2.3"}, []string{"v1.2.4-rc1"}},
+		"!=1.2.3":          {[]string{"v1.2.4"}, []string{"v1.2.3"}},
+		">1.2":             {[]string{"v1.3.0"}, []string{"v1.2.9"}},
+		"<=1.2":            {[]string{"v1.2.9"}, []string{"v1.3.0"}},
+		"~1.2.3":           {[]string{"v1.2.3", "v1.2.9"}, []string{"v1.2.2", "v1.3.0"}},
+		"^1.2.3":           {[]string{"v1.2.3", "v1.9.0"}, []string{"v1.2.2", "v2.0.0"}},
+		"^0.2.3":           {[]string{"v0.2.3", "v0.2.9"}, []string{"v0.3.0"}},
+		">= 2.0 || ~1.2.0": {[]string{"v1.2.5", "v2.0.0"}, []string{"v1.3.0"}},
+	}
+	for constraintString, versions := range constraints {
+		constraint, err := bringauto_package.ParseVersionConstraint(constraintString)
+		if err != nil {
+			t.Fatalf("ParseVersionConstraint failed - %s", err)
+		}
+		for _, version := range versions.satisfied {
+			satisfied, err := constraint.CheckVersionTag(version)
+			if err != nil || !satisfied {
+				t.Errorf("%s does not satisfy %s", version, constraint)
+			}
+		}
+		for _, version := range versions.notSatisfied {
+			satisfied, err := constraint.CheckVersionTag(version)
+			if err != nil || satisfied {
+				t.Errorf("%s satisfies %s", version, constraint)
+			}
+		}
+	}
+	for _, constraintString := range []string{"", ">=", "1.x", "!=1.2", ">=1.0 ||", "=>1.0"} {
+		_, err := bringauto_package.ParseVersionConstraint(constraintString)
+		if err == nil {
+			t.Errorf("invalid constraint '%s' parsed", constraintString)
+		}
+	}
+}
+
+func TestCheckVersionTag(t *testing.T) {
+	for _, versionTag := range []string{"v1.2.3", "v1.2.3-rc1", "v2024.10.1.2", "v1.2.3+ba1", "v1.2.3.4-rc.1+ba2"} {
+		pack := bringauto_package.Package{Name: "pack1", VersionTag: versionTag}
+		err := pack.CheckPrerequisites(nil)
+		if err != nil {
+			t.Errorf("valid VersionTag %s rejected - %s", versionTag, err)
+		}
+	}
+	for _, versionTag := range []string{"1.2.3", "v1.2", "v1.2.3_rc1", "v1.2.3-rc1 "} {
+		pack := bringauto_package.Package{Name: "pack1", VersionTag: versionTag}
+		err := pack.CheckPrerequisites(nil)
+		if err == nil {
+			t.Errorf("invalid VersionTag %s accepted", versionTag)
+		}
+	}
+}
+
+func TestGetNativeVersion(t *testing.T) {
+	versions := map[string][2]string{
+		"v1.2.3":          {"1.2.3", "1.2.3"},
+		"v1.2.3-rc1":      {"1.2.3~rc1", "1.2.3~rc1"},
+		"v2024.10.1.2":    {"2024.10.1.2", "2024.10.1.2"},
+		"v1.2.3-rc-2+ba1": {"1.2.3~rc-2+ba1", "1.2.3~rc_2+ba1"},
+		"v1.2.3+ba-1":     {"1.2.3+ba-1", "1.2.3+ba_1"},
+	}
+	for versionTag, nativeVersions := range versions {
+		pack := bringauto_package.Package{VersionTag: versionTag}
+		for i, format := range []bringauto_package.NativeFormat{bringauto_package.NativeFormatDeb, bringauto_package.NativeFormatRpm} {
+			if pack.GetNativeVersion(format) != nativeVersions[i] {
+				t.Errorf("wrong %s version of %s - %s", format, versionTag, pack.GetNativeVersion(format))
+			}
+		}
+	}
+}
+
+func TestComparePackages(t *testing.T) {
+	packages := []bringauto_package.Package{
+		{Name: "pack2", VersionTag: "v1.0.0"},
+		{Name: "pack1", VersionTag: "v1.10.0"},
+		{Name: "pack1", VersionTag: "v1.9.0", IsDebug: true},
+		{Name: "pack1", VersionTag: "v1.9.0+ba1"},
+		{Name: "pack1", VersionTag: "v1.9.0"},
+		{Name: "pack1", VersionTag: "v1.10.0-rc1"},
+	}
+	slices.SortFunc(packages, bringauto_package.ComparePackages)
+	var sorted []string
+	for _, pack := range packages {
+		sorted = append(sorted, pack.Name + " " + pack.VersionTag + " " + strconv.FormatBool(pack.IsDebug))
+	}
+	expected := []string{
+		"pack1 v1.9.0 false", "pack1 v1.9.0+ba1 false", "pack1 v1.10.0-rc1 false", "pack1 v1.10.0 false",
+		"pack1 v1.9.0 true", "pack2 v1.0.0 false",
+	}
+	if !slices.Equal(sorted, expected) {
+		t.Errorf("wrong order of packages - %s", sorted)
+	}
+}
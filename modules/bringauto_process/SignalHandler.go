@@ -7,7 +7,8 @@
 // registered signal is received, all added (and not yet removed) handlers will be executed in
 // reverse order and then the program exits with status code 1.
 //
-// Note: Do not use with concurrent programming. Can behave unexpectedly!
+// Handlers can be added and removed from multiple goroutines. Each returned function removes
+// exactly the handler it was created for, regardless of the order in which handlers are removed.
 
 package bringauto_process
 
@@ -19,8 +20,14 @@ import (
 )
 
 
+type handlerEntry struct {
+	id      uint64
+	handler func() error
+}
+
 var lock sync.Mutex
-var handlers []func() error
+var handlers []handlerEntry
+var lastHandlerId uint64
 
 // SignalHandlerRegisterSignal
 // Registers handling of specified signals to bringauto_process package
@@ -47,7 +54,9 @@ func SignalHandlerRegisterSignal(sig ...os.Signal) {
 func SignalHandlerAddHandler(handler func() error) func() {
 	lock.Lock()
 	defer lock.Unlock()
-	handlers = append(handlers, handler)
+	lastHandlerId++
+	id := lastHandlerId
+	handlers = append(handlers, handlerEntry{id: id, handler: handler})
 	return func() {
 		lock.Lock()
 		defer lock.Unlock()
@@ -55,20 +64,24 @@ func SignalHandlerAddHandler(handler func() error) func() {
 		if err != nil {
 			bringauto_log.GetLogger().Error("Handler returned error - %s", err)
 		}
-		removeLastHandler()
+		removeHandler(id)
 	}
 }
 
-func removeLastHandler() {
-	if len(handlers) == 0 {
-		return
+// removeHandler
+// Removes handler with given id from handlers. If there is no such handler, nothing happens.
+func removeHandler(id uint64) {
+	for i, entry := range handlers {
+		if entry.id == id {
+			handlers = append(handlers[:i], handlers[i+1:]...)
+			return
+		}
 	}
-	handlers = handlers[:len(handlers) - 1]
 }
 
 func executeAllHandlers() {
 	for i := len(handlers)-1; i >= 0; i-- {
-		err := handlers[i]()
+		err := handlers[i].handler()
 		if err != nil {
 			bringauto_log.GetLogger().Error("Handler returned error - %s", err)
 		}
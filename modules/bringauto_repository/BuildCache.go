@@ -0,0 +1,96 @@
+package bringauto_repository
+
+import (
+	"bringauto/modules/bringauto_package"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	// Extension of the file with cache key stored next to the Package archive
+	CacheKeyExt = ".cachekey"
+)
+
+// GetPackageArchivePath
+// Returns path of the pack archive in the Format of the repository inside Git Lfs.
+func (lfs *GitLFSRepository) GetPackageArchivePath(pack bringauto_package.Package) string {
+	return path.Join(lfs.CreatePackagePath(pack), pack.GetFullPackageName()+lfs.Format.Ext())
+}
+
+// GetDbgsymArchivePath
+// Returns path of the debug symbols archive of the pack in the Format of the repository inside
+// Git Lfs.
+func (lfs *GitLFSRepository) GetDbgsymArchivePath(pack bringauto_package.Package) string {
+	pack.Format = lfs.Format
+	return path.Join(lfs.CreatePackagePath(pack), pack.GetDbgsymArchiveName())
+}
+
+// IsPackageSigned
+// Returns true if the signature of the pack archive in the Format of the repository is in Git
+// Lfs, else returns false. The signature is not verified.
+func (lfs *GitLFSRepository) IsPackageSigned(pack bringauto_package.Package) bool {
+	_, err := os.Stat(lfs.GetPackageArchivePath(pack) + bringauto_package.SignatureExt)
+	return err == nil
+}
+
+// IsNativePackageSigned
+// Returns true if the signature of the native package of the pack in the given format is in Git
+// Lfs, else returns false. The signature is not verified.
+func (lfs *GitLFSRepository) IsNativePackageSigned(pack bringauto_package.Package, format bringauto_package.NativeFormat) bool {
+	nativePath := path.Join(lfs.CreatePackagePath(pack), pack.GetNativeArchiveName(format))
+	_, err := os.Stat(nativePath + bringauto_package.SignatureExt)
+	return err == nil
+}
+
+// FindPackageArchivePath
+// Returns path of the existing pack archive inside Git Lfs. The archive in the Format of the
+// repository is preferred, archives in other formats are tried then. Returns empty string if
+// there is no archive of the pack.
+func (lfs *GitLFSRepository) FindPackageArchivePath(pack bringauto_package.Package) string {
+	archivePath := lfs.GetPackageArchivePath(pack)
+	if _, err := os.Stat(archivePath); err == nil {
+		return archivePath
+	}
+	for _, archiveFormat := range bringauto_package.ArchiveFormats() {
+		archivePath = path.Join(lfs.CreatePackagePath(pack), pack.GetFullPackageName()+archiveFormat.Ext())
+		if _, err := os.Stat(archivePath); err == nil {
+			return archivePath
+		}
+	}
+	return ""
+}
+
+// getCacheKeyPath
+// Returns path of the file with cache key of the pack inside Git Lfs.
+func (lfs *GitLFSRepository) getCacheKeyPath(pack bringauto_package.Package) string {
+	return path.Join(lfs.CreatePackagePath(pack), pack.GetFullPackageName()+CacheKeyExt)
+}
+
+// SaveCacheKey
+// Stores cacheKey of the pack next to the pack archive in Git Lfs.
+func (lfs *GitLFSRepository) SaveCacheKey(pack bringauto_package.Package, cacheKey string) error {
+	err := os.MkdirAll(lfs.CreatePackagePath(pack), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(lfs.getCacheKeyPath(pack), []byte(cacheKey+"\n"), 0644)
+}
+
+// IsPackageCached
+// Returns true if the pack archive in the Format of the repository is in Git Lfs and it was built
+// with the same cacheKey, else returns false.
+func (lfs *GitLFSRepository) IsPackageCached(pack bringauto_package.Package, cacheKey string) bool {
+	if cacheKey == "" {
+		return false
+	}
+	_, err := os.Stat(lfs.GetPackageArchivePath(pack))
+	if err != nil {
+		return false
+	}
+	storedKey, err := os.ReadFile(lfs.getCacheKeyPath(pack))
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(storedKey)) == cacheKey
+}
@@ -7,6 +7,7 @@ import (
 	"bringauto/modules/bringauto_context"
 	"bringauto/modules/bringauto_config"
 	"bytes"
+	"crypto/ed25519"
 	"fmt"
 	"io/fs"
 	"os"
@@ -14,17 +15,25 @@ import (
 	"path"
 	"path/filepath"
 	"slices"
+	"strings"
 )
 
 // GitLFSRepository represents Package repository based on Git LFS
 type GitLFSRepository struct {
 	GitRepoPath string
+	// Format of the Package archives created in the repository, FormatZip if empty
+	Format bringauto_package.ArchiveFormat
+	// SigningKey key used to sign the Package archives created in the repository, archives are
+	// not signed if nil
+	SigningKey ed25519.PrivateKey
 }
 
 const (
 	gitExecutablePath = "/usr/bin/git"
 	// Count of files which will be list in warnings
 	listFileCount = 10
+	// Message of the commit made by CommitAllChanges
+	commitMessageConst = "Build packages"
 )
 
 func (lfs *GitLFSRepository) FillDefault(args *bringauto_prerequisites.Args) error {
@@ -51,13 +60,17 @@ func (lfs *GitLFSRepository) CheckPrerequisites(*bringauto_prerequisites.Args) e
 }
 
 // CommitAllChanges
-// Adds all changes to staged and then makes a commit.
+// Adds all changes to staged and then makes a commit. If there are no changes (e.g. all
+// Packages were taken from the cache), no commit is made.
 func (lfs *GitLFSRepository) CommitAllChanges() error {
+	if lfs.gitIsStatusEmpty() {
+		return nil
+	}
 	err := lfs.gitAddAll()
 	if err != nil {
 		return err
 	}
-	err = lfs.gitCommit()
+	err = lfs.gitCommit(commitMessageConst)
 	if err != nil {
 		return err
 	}
@@ -65,6 +78,26 @@ func (lfs *GitLFSRepository) CommitAllChanges() error {
 	return nil
 }
 
+// CommitPackage
+// Adds all changes to staged and makes a commit of the pack. Should be called right after the
+// pack is copied to the repository, so the pack stays in the repository even if a later build
+// fails. The Git source of the pack (if known) is part of the commit message. If there are no
+// changes, no commit is made.
+func (lfs *GitLFSRepository) CommitPackage(pack bringauto_package.Package) error {
+	if lfs.gitIsStatusEmpty() {
+		return nil
+	}
+	err := lfs.gitAddAll()
+	if err != nil {
+		return err
+	}
+	message := "Build package " + pack.GetFullPackageName()
+	if pack.Metadata != nil && pack.Metadata.GitCommit != "" {
+		message += "\n\nSource: " + pack.Metadata.GitURI + " " + pack.Metadata.GitCommit
+	}
+	return lfs.gitCommit(message)
+}
+
 // RestoreAllChanges
 // Restores all changes in repository and cleans all untracked changes.
 func (lfs *GitLFSRepository) RestoreAllChanges() error {
@@ -85,6 +118,7 @@ func (lfs *GitLFSRepository) RestoreAllChanges() error {
 
 // dividePackagesForCurrentImage
 // Divides allConfigs to packages for imageName and not for imageName and returns 2 slices.
+// Both slices are sorted by bringauto_package.ComparePackages.
 func dividePackagesForCurrentImage(allConfigs []*bringauto_config.Config, imageName string) ([]bringauto_package.Package, []bringauto_package.Package) {
 	var packagesForImage []bringauto_package.Package
 	var packagesNotForImage []bringauto_package.Package
@@ -96,6 +130,8 @@ func dividePackagesForCurrentImage(allConfigs []*bringauto_config.Config, imageN
 			packagesNotForImage = append(packagesNotForImage, config.Package)
 		}
 	}
+	slices.SortFunc(packagesForImage, bringauto_package.ComparePackages)
+	slices.SortFunc(packagesNotForImage, bringauto_package.ComparePackages)
 
 	return packagesForImage, packagesNotForImage
 }
@@ -103,7 +139,8 @@ func dividePackagesForCurrentImage(allConfigs []*bringauto_config.Config, imageN
 // CheckGitLfsConsistency
 // Checks Git Lfs consistency based on Context. Prints and returns errors if in Git Lfs is any
 // Package which is not in Context. Prints warnings if the Git Lfs is missing any Packages present
-// in Context and prints warnings if any Package won't build for current imageName.
+// in Context and prints warnings if any Package won't build for current imageName. Package
+// archives of all supported formats are recognized.
 func (lfs *GitLFSRepository) CheckGitLfsConsistency(contextManager *bringauto_context.ContextManager, platformString *bringauto_package.PlatformString, imageName string) error {
 	packConfigs, err := contextManager.GetAllPackagesConfigs(platformString)
 	if err != nil {
@@ -114,14 +151,16 @@ func (lfs *GitLFSRepository) CheckGitLfsConsistency(contextManager *bringauto_co
 
 	var expectedPackForImagePaths, expectedPackNotForImagePaths []string
 	for _, pack := range packagesForImage {
-		packPath := filepath.Join(lfs.CreatePackagePath(pack) + "/" + pack.GetFullPackageName() + ".zip")
+		packPath := filepath.Join(lfs.CreatePackagePath(pack) + "/" + pack.GetFullPackageName())
 		expectedPackForImagePaths = append(expectedPackForImagePaths, packPath)
 	}
 	for _, pack := range packagesNotForImage {
-		packPath := filepath.Join(lfs.CreatePackagePath(pack) + "/" + pack.GetFullPackageName() + ".zip")
+		packPath := filepath.Join(lfs.CreatePackagePath(pack) + "/" + pack.GetFullPackageName())
 		expectedPackNotForImagePaths = append(expectedPackNotForImagePaths, packPath)
 	}
 
+	allExpectedPackPaths := slices.Concat(expectedPackForImagePaths, expectedPackNotForImagePaths)
+
 	lookupPath := filepath.Join(lfs.GitRepoPath, platformString.String.DistroName, platformString.String.DistroRelease, platformString.String.Machine)
 
 	var errorPackPaths []string
@@ -132,13 +171,16 @@ func (lfs *GitLFSRepository) CheckGitLfsConsistency(contextManager *bringauto_co
 				return filepath.SkipDir
 			}
 			if !d.IsDir() {
-				if !slices.Contains(expectedPackForImagePaths, path) {
+				if isSidecarOfPackage(path, allExpectedPackPaths) {
+					return nil
+				}
+				packPath, isArchive := trimArchiveExt(path)
+				if !isArchive || !slices.Contains(expectedPackForImagePaths, packPath) {
 					errorPackPaths = append(errorPackPaths, path)
 				} else {
 					// Remove element from expected package paths
-					index := slices.Index(expectedPackForImagePaths, path)
-					expectedPackForImagePaths[index] = expectedPackForImagePaths[len(expectedPackForImagePaths) - 1]
-					expectedPackForImagePaths = expectedPackForImagePaths[:len(expectedPackForImagePaths) - 1]
+					index := slices.Index(expectedPackForImagePaths, packPath)
+					expectedPackForImagePaths = slices.Delete(expectedPackForImagePaths, index, index + 1)
 				}
 			}
 			return nil
@@ -154,6 +196,42 @@ func (lfs *GitLFSRepository) CheckGitLfsConsistency(contextManager *bringauto_co
 	return nil
 }
 
+// isSidecarOfPackage
+// Returns true if filePath is a cache key file, a native package (deb or rpm), a debug symbols
+// archive or a signature of the archive of one of the packPaths (without archive extension),
+// else returns false.
+func isSidecarOfPackage(filePath string, packPaths []string) bool {
+	if strings.HasSuffix(filePath, bringauto_package.SignatureExt) {
+		signedPath := strings.TrimSuffix(filePath, bringauto_package.SignatureExt)
+		if strings.HasSuffix(signedPath, bringauto_package.SignatureExt) || strings.HasSuffix(signedPath, CacheKeyExt) {
+			return false
+		}
+		packPath, isArchive := trimArchiveExt(signedPath)
+		return (isArchive && slices.Contains(packPaths, packPath)) || isSidecarOfPackage(signedPath, packPaths)
+	}
+	for _, ext := range []string{CacheKeyExt, bringauto_package.DebExt, bringauto_package.RpmExt} {
+		if strings.HasSuffix(filePath, ext) {
+			return slices.Contains(packPaths, strings.TrimSuffix(filePath, ext))
+		}
+	}
+	archivePath, isArchive := trimArchiveExt(filePath)
+	if isArchive && strings.HasSuffix(archivePath, bringauto_package.DbgsymSuffix) {
+		return slices.Contains(packPaths, strings.TrimSuffix(archivePath, bringauto_package.DbgsymSuffix))
+	}
+	return false
+}
+
+// trimArchiveExt
+// Returns filePath without the extension of the Package archive. Returns false if filePath is
+// not a Package archive of any supported format.
+func trimArchiveExt(filePath string) (string, bool) {
+	archiveFormat, err := bringauto_package.GetArchiveFormat(filePath)
+	if err != nil {
+		return filePath, false
+	}
+	return strings.TrimSuffix(filePath, archiveFormat.Ext()), true
+}
+
 // printErrors
 // Prints errors and warnings for Git Lfs consistency check.
 func printErrors(errorPackPaths []string, expectedPackForImagePaths []string, expectedPackNotForImagePaths []string) error {
@@ -206,6 +284,10 @@ func (lfs *GitLFSRepository) CreatePackagePath(pack bringauto_package.Package) s
 // Copies the pack to the Git LFS repository. Each package is stored in different directory
 // structure represented by
 // PlatformString.DistroName / PlatformString.DistroRelease / PlatformString.Machine / <package>
+// The pack archive is created in the Format of the repository. If the SigningKey is set, the
+// archives and the native package are signed (<archive>.sig). Archives of the pack in other
+// formats, native packages, debug symbols archives and signatures which are not created by this
+// copy are removed.
 func (lfs *GitLFSRepository) CopyToRepository(pack bringauto_package.Package, sourceDir string) error {
 	archiveDirectory := lfs.CreatePackagePath(pack)
 
@@ -215,14 +297,70 @@ func (lfs *GitLFSRepository) CopyToRepository(pack bringauto_package.Package, so
 		return err
 	}
 
+	pack.Format = lfs.Format
 	err = pack.CreatePackage(sourceDir, archiveDirectory)
 	if err != nil {
 		return err
 	}
 
+	archiveNames := []string{pack.GetArchiveName()}
+	if pack.DebugSymbolsDir != "" {
+		archiveNames = append(archiveNames, pack.GetDbgsymArchiveName())
+	}
+	if pack.Native != nil {
+		archiveNames = append(archiveNames, pack.GetNativeArchiveName(pack.Native.Format))
+	}
+	for _, archiveName := range archiveNames {
+		archivePath := filepath.Join(archiveDirectory, archiveName)
+		if lfs.SigningKey != nil {
+			err = bringauto_package.SignArchive(archivePath, lfs.SigningKey)
+		} else {
+			err = os.Remove(archivePath + bringauto_package.SignatureExt)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	for _, archiveFormat := range bringauto_package.ArchiveFormats() {
+		var staleArchives []string
+		if archiveFormat.Ext() != lfs.Format.Ext() {
+			staleArchives = append(staleArchives, pack.GetFullPackageName()+archiveFormat.Ext())
+		}
+		if archiveFormat.Ext() != lfs.Format.Ext() || pack.DebugSymbolsDir == "" {
+			staleArchives = append(staleArchives, pack.GetFullPackageName()+bringauto_package.DbgsymSuffix+archiveFormat.Ext())
+		}
+		for _, staleArchive := range staleArchives {
+			for _, stalePath := range []string{staleArchive, staleArchive + bringauto_package.SignatureExt} {
+				err = os.Remove(filepath.Join(archiveDirectory, stalePath))
+				if err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			}
+		}
+	}
+	for _, nativeFormat := range bringauto_package.NativeFormats() {
+		if pack.Native != nil && pack.Native.Format == nativeFormat {
+			continue
+		}
+		nativePath := filepath.Join(archiveDirectory, pack.GetNativeArchiveName(nativeFormat))
+		for _, stalePath := range []string{nativePath, nativePath + bringauto_package.SignatureExt} {
+			err = os.Remove(stalePath)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
+// CopyFromRepository
+// Extracts the pack archive stored in the Git LFS repository to the outputDir.
+func (lfs *GitLFSRepository) CopyFromRepository(pack bringauto_package.Package, outputDir string) error {
+	return bringauto_package.ExtractArchive(lfs.GetPackageArchivePath(pack), outputDir)
+}
+
 // gitIsStatusEmpty
 // Returns true, if the git status in Git Lfs is empty, else returns false.
 func (lfs *GitLFSRepository) gitIsStatusEmpty() bool {
@@ -255,12 +393,12 @@ func (lfs *GitLFSRepository) gitAddAll() error {
 }
 
 // gitCommit
-// Commits all in Git Lfs.
-func (lfs *GitLFSRepository) gitCommit() error {
+// Commits all in Git Lfs with the given message.
+func (lfs *GitLFSRepository) gitCommit(message string) error {
 	var ok, _ = lfs.prepareAndRun([]string{
 		"commit",
 		"-m",
-		"Build packages",
+		message,
 	},
 	)
 	if !ok {
@@ -4,6 +4,8 @@ import (
 	"bringauto/modules/bringauto_testing"
 	"bringauto/modules/bringauto_package"
 	"bringauto/modules/bringauto_prerequisites"
+	"crypto/ed25519"
+	"crypto/rand"
 	"fmt"
 	"os"
 	"os/exec"
@@ -167,6 +169,207 @@ func TestCopyToRepositoryMultiplePackages(t *testing.T) {
 	}
 }
 
+func TestCopyToRepositoryFormat(t *testing.T) {
+	repo, err := initGitRepo()
+	if err != nil {
+		t.Fatalf("can't initialize Git repository or struct - %s", err)
+	}
+
+	err = repo.CopyToRepository(pack1, bringauto_testing.Pack1Name)
+	if err != nil {
+		t.Errorf("CopyToRepository failed - %s", err)
+	}
+	repo.Format = bringauto_package.FormatTarZst
+	err = repo.CopyToRepository(pack1, bringauto_testing.Pack1Name)
+	if err != nil {
+		t.Errorf("CopyToRepository failed - %s", err)
+	}
+
+	zipFilePath := filepath.Join(repo.CreatePackagePath(pack1), pack1.GetFullPackageName() + ZipExtension)
+	_, err = os.Stat(zipFilePath)
+	if !os.IsNotExist(err) {
+		t.Error("archive in the previous format not removed")
+	}
+	if repo.FindPackageArchivePath(pack1) != repo.GetPackageArchivePath(pack1) {
+		t.Errorf("wrong archive found - %s", repo.FindPackageArchivePath(pack1))
+	}
+	if filepath.Ext(repo.GetPackageArchivePath(pack1)) != ".zst" {
+		t.Errorf("wrong archive path - %s", repo.GetPackageArchivePath(pack1))
+	}
+
+	outputDir := t.TempDir()
+	err = repo.CopyFromRepository(pack1, outputDir)
+	if err != nil {
+		t.Errorf("CopyFromRepository failed - %s", err)
+	}
+
+	err = deleteGitRepo()
+	if err != nil {
+		t.Fatalf("can't delete Git repository - %s", err)
+	}
+}
+
+func TestCopyToRepositoryDbgsym(t *testing.T) {
+	repo, err := initGitRepo()
+	if err != nil {
+		t.Fatalf("can't initialize Git repository or struct - %s", err)
+	}
+
+	dbgsymPack := pack1
+	dbgsymPack.DebugSymbolsDir = bringauto_testing.Pack2Name
+	err = repo.CopyToRepository(dbgsymPack, bringauto_testing.Pack1Name)
+	if err != nil {
+		t.Errorf("CopyToRepository failed - %s", err)
+	}
+	dbgsymPath := repo.GetDbgsymArchivePath(pack1)
+	_, err = os.Stat(dbgsymPath)
+	if err != nil {
+		t.Errorf("debug symbols archive not created - %s", err)
+	}
+	packPath := filepath.Join(repo.CreatePackagePath(pack1), pack1.GetFullPackageName())
+	if !isSidecarOfPackage(dbgsymPath, []string{packPath}) {
+		t.Errorf("debug symbols archive is not a sidecar of the package - %s", dbgsymPath)
+	}
+
+	err = repo.CopyToRepository(pack1, bringauto_testing.Pack1Name)
+	if err != nil {
+		t.Errorf("CopyToRepository failed - %s", err)
+	}
+	_, err = os.Stat(dbgsymPath)
+	if !os.IsNotExist(err) {
+		t.Error("stale debug symbols archive not removed")
+	}
+
+	err = deleteGitRepo()
+	if err != nil {
+		t.Fatalf("can't delete Git repository - %s", err)
+	}
+}
+
+func TestCopyToRepositorySigned(t *testing.T) {
+	repo, err := initGitRepo()
+	if err != nil {
+		t.Fatalf("can't initialize Git repository or struct - %s", err)
+	}
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("can't generate key - %s", err)
+	}
+
+	repo.SigningKey = privateKey
+	err = repo.CopyToRepository(pack1, bringauto_testing.Pack1Name)
+	if err != nil {
+		t.Errorf("CopyToRepository failed - %s", err)
+	}
+	if !repo.IsPackageSigned(pack1) {
+		t.Error("package archive not signed")
+	}
+	archivePath := repo.GetPackageArchivePath(pack1)
+	err = bringauto_package.VerifyArchive(archivePath, []ed25519.PublicKey{publicKey})
+	if err != nil {
+		t.Errorf("VerifyArchive failed - %s", err)
+	}
+	packPath := filepath.Join(repo.CreatePackagePath(pack1), pack1.GetFullPackageName())
+	if !isSidecarOfPackage(archivePath+bringauto_package.SignatureExt, []string{packPath}) {
+		t.Error("signature is not a sidecar of the package")
+	}
+
+	repo.SigningKey = nil
+	err = repo.CopyToRepository(pack1, bringauto_testing.Pack1Name)
+	if err != nil {
+		t.Errorf("CopyToRepository failed - %s", err)
+	}
+	if repo.IsPackageSigned(pack1) {
+		t.Error("stale signature not removed")
+	}
+
+	err = deleteGitRepo()
+	if err != nil {
+		t.Fatalf("can't delete Git repository - %s", err)
+	}
+}
+
+func TestCopyToRepositorySignedNative(t *testing.T) {
+	repo, err := initGitRepo()
+	if err != nil {
+		t.Fatalf("can't initialize Git repository or struct - %s", err)
+	}
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("can't generate key - %s", err)
+	}
+
+	nativePack := pack1
+	nativePack.Native = &bringauto_package.NativePackage{
+		Format: bringauto_package.NativeFormatDeb,
+		Prefix: "/usr",
+	}
+	repo.SigningKey = privateKey
+	err = repo.CopyToRepository(nativePack, bringauto_testing.Pack1Name)
+	if err != nil {
+		t.Fatalf("CopyToRepository failed - %s", err)
+	}
+	if !repo.IsNativePackageSigned(pack1, bringauto_package.NativeFormatDeb) {
+		t.Error("native package not signed")
+	}
+	nativePath := filepath.Join(repo.CreatePackagePath(pack1), pack1.GetNativeArchiveName(bringauto_package.NativeFormatDeb))
+	err = bringauto_package.VerifyArchive(nativePath, []ed25519.PublicKey{publicKey})
+	if err != nil {
+		t.Errorf("VerifyArchive of native package failed - %s", err)
+	}
+	packPath := filepath.Join(repo.CreatePackagePath(pack1), pack1.GetFullPackageName())
+	if !isSidecarOfPackage(nativePath+bringauto_package.SignatureExt, []string{packPath}) {
+		t.Error("native package signature is not a sidecar of the package")
+	}
+
+	err = repo.CopyToRepository(pack1, bringauto_testing.Pack1Name)
+	if err != nil {
+		t.Errorf("CopyToRepository failed - %s", err)
+	}
+	if repo.IsNativePackageSigned(pack1, bringauto_package.NativeFormatDeb) {
+		t.Error("stale native package signature not removed")
+	}
+
+	err = deleteGitRepo()
+	if err != nil {
+		t.Fatalf("can't delete Git repository - %s", err)
+	}
+}
+
+func TestIsPackageCached(t *testing.T) {
+	repo, err := initGitRepo()
+	if err != nil {
+		t.Fatalf("can't initialize Git repository or struct - %s", err)
+	}
+
+	err = repo.SaveCacheKey(pack1, "key1")
+	if err != nil {
+		t.Errorf("SaveCacheKey failed - %s", err)
+	}
+	if repo.IsPackageCached(pack1, "key1") {
+		t.Error("package without zip archive is cached")
+	}
+
+	err = repo.CopyToRepository(pack1, bringauto_testing.Pack1Name)
+	if err != nil {
+		t.Errorf("CopyToRepository failed - %s", err)
+	}
+	if !repo.IsPackageCached(pack1, "key1") {
+		t.Error("package with the same cache key is not cached")
+	}
+	if repo.IsPackageCached(pack1, "key2") {
+		t.Error("package with different cache key is cached")
+	}
+	if repo.IsPackageCached(pack2, "key1") {
+		t.Error("package not in repository is cached")
+	}
+
+	err = deleteGitRepo()
+	if err != nil {
+		t.Fatalf("can't delete Git repository - %s", err)
+	}
+}
+
 func TestCommitAllChanges(t *testing.T) {
 	repo, err := initGitRepo()
 	if err != nil {
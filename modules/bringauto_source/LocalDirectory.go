@@ -0,0 +1,103 @@
+package bringauto_source
+
+import (
+	"bringauto/modules/bringauto_prerequisites"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// LocalDirectory
+// Project sources in a directory on the host (e.g. a local checkout during development). The
+// directory is mounted to the container and copied to the source directory, so the build does
+// not modify the host directory.
+type LocalDirectory struct {
+	// Path of the directory on the host. Relative path is relative to the directory of the
+	// Package JSON definition
+	Path string
+	// MountPath directory inside the container where the host directory is mounted
+	MountPath string `json:"-"`
+	// CopyPath directory inside the container where the sources are copied
+	CopyPath string `json:"-"`
+}
+
+func (local *LocalDirectory) FillDefault(*bringauto_prerequisites.Args) error {
+	return nil
+}
+
+func (local *LocalDirectory) FillDynamic(*bringauto_prerequisites.Args) error {
+	return nil
+}
+
+func (local *LocalDirectory) CheckPrerequisites(*bringauto_prerequisites.Args) error {
+	if local.Path == "" {
+		return fmt.Errorf("local directory path is empty")
+	}
+	return nil
+}
+
+// GetContentHash
+// Returns SHA-256 hash of the directory content - names, permissions and content of all files.
+// The hash changes whenever any file in the directory changes.
+func (local *LocalDirectory) GetContentHash() (string, error) {
+	info, err := os.Stat(local.Path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", local.Path)
+	}
+	hash := sha256.New()
+	err = filepath.WalkDir(local.Path, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(local.Path, filePath)
+		if err != nil {
+			return err
+		}
+		entryInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		hash.Write([]byte(relPath + "\x00" + strconv.FormatUint(uint64(entryInfo.Mode()), 8) + "\x00"))
+		switch {
+		case entry.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(filePath)
+			if err != nil {
+				return err
+			}
+			hash.Write([]byte(target))
+		case entry.Type().IsRegular():
+			file, err := os.Open(filePath)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(hash, file)
+			file.Close()
+			if err != nil {
+				return err
+			}
+		}
+		hash.Write([]byte{0})
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot hash local directory %s - %s", local.Path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (local *LocalDirectory) ConstructCMDLine() []string {
+	validateSourcePath(local.MountPath)
+	validateSourcePath(local.CopyPath)
+	return []string{
+		"mkdir -p " + escapeValue(local.CopyPath),
+		"cp -a " + escapeValue(local.MountPath+"/.") + " " + escapeValue(local.CopyPath),
+	}
+}
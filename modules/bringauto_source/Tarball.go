@@ -0,0 +1,222 @@
+package bringauto_source
+
+import (
+	"bringauto/modules/bringauto_prerequisites"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Maximum time to wait for the response headers of the download
+	downloadResponseTimeoutConst = time.Minute
+	// Maximum time of the whole download, including reading of the archive
+	downloadTimeoutConst = 30 * time.Minute
+)
+
+var sha256Regexp = regexp.MustCompile("^[0-9a-f]{64}$")
+
+// downloadClient
+// HTTP client used to download archives. Timeouts ensure a stalled server does not block the
+// build forever.
+var downloadClient = newDownloadClient()
+
+// Tarball
+// Project sources distributed as a tar archive (e.g. upstream release tarball). The archive is
+// downloaded on the host, its checksum is verified and it is extracted inside the container.
+type Tarball struct {
+	// URL of the archive. http, https and file schemes are supported
+	URL string
+	// SHA256 hex encoded SHA-256 checksum of the archive. Required
+	SHA256 string
+	// StripComponents number of leading path components stripped from the extracted file names,
+	// as the tar --strip-components option. Release tarballs usually need 1
+	StripComponents int
+	// ArchivePath path of the archive inside the container
+	ArchivePath string `json:"-"`
+	// ExtractPath directory inside the container where the archive is extracted
+	ExtractPath string `json:"-"`
+}
+
+func (tarball *Tarball) FillDefault(*bringauto_prerequisites.Args) error {
+	return nil
+}
+
+func (tarball *Tarball) FillDynamic(*bringauto_prerequisites.Args) error {
+	return nil
+}
+
+func (tarball *Tarball) CheckPrerequisites(*bringauto_prerequisites.Args) error {
+	sourceUrl, err := url.Parse(tarball.URL)
+	if err != nil {
+		return fmt.Errorf("invalid tarball URL '%s' - %s", tarball.URL, err)
+	}
+	switch sourceUrl.Scheme {
+	case "http", "https", "file":
+	default:
+		return fmt.Errorf("unsupported tarball URL scheme '%s', use http, https or file", sourceUrl.Scheme)
+	}
+	if !sha256Regexp.MatchString(tarball.SHA256) {
+		return fmt.Errorf("tarball SHA256 must be 64 lowercase hex characters, got '%s'", tarball.SHA256)
+	}
+	if tarball.StripComponents < 0 {
+		return fmt.Errorf("tarball StripComponents must not be negative")
+	}
+	return nil
+}
+
+// GetFileName
+// Returns name of the archive file, it is the last element of the URL path.
+func (tarball *Tarball) GetFileName() string {
+	sourceUrl, err := url.Parse(tarball.URL)
+	if err != nil {
+		return tarball.SHA256
+	}
+	fileName := path.Base(sourceUrl.Path)
+	if fileName == "." || fileName == "/" {
+		return tarball.SHA256
+	}
+	return fileName
+}
+
+// Fetch
+// Downloads the archive to downloadDir and verifies its checksum. Archives already present in
+// downloadDir are not downloaded again, only their checksum is verified. Returns path of the
+// archive.
+func (tarball *Tarball) Fetch(downloadDir string) (string, error) {
+	err := tarball.CheckPrerequisites(nil)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(downloadDir, 0755)
+	if err != nil {
+		return "", fmt.Errorf("cannot create download directory %s - %s", downloadDir, err)
+	}
+	archivePath := filepath.Join(downloadDir, tarball.SHA256+"-"+tarball.GetFileName())
+	if _, err = os.Stat(archivePath); err == nil {
+		err = verifyChecksum(archivePath, tarball.SHA256)
+		if err == nil {
+			return archivePath, nil
+		}
+		os.Remove(archivePath)
+	}
+
+	reader, err := tarball.open()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	tmpFile, err := os.CreateTemp(downloadDir, tarball.SHA256+"-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpFile.Name())
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmpFile, hash), reader)
+	closeErr := tmpFile.Close()
+	if err != nil {
+		return "", fmt.Errorf("cannot download %s - %s", tarball.URL, err)
+	}
+	if closeErr != nil {
+		return "", closeErr
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if checksum != tarball.SHA256 {
+		return "", fmt.Errorf("checksum mismatch of %s - expected %s, got %s", tarball.URL, tarball.SHA256, checksum)
+	}
+	err = os.Rename(tmpFile.Name(), archivePath)
+	if err != nil {
+		return "", err
+	}
+	return archivePath, nil
+}
+
+// open
+// Opens the archive for reading.
+func (tarball *Tarball) open() (io.ReadCloser, error) {
+	sourceUrl, err := url.Parse(tarball.URL)
+	if err != nil {
+		return nil, err
+	}
+	if sourceUrl.Scheme == "file" {
+		return os.Open(sourceUrl.Path)
+	}
+	response, err := downloadClient.Get(tarball.URL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot download %s - %s", tarball.URL, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("cannot download %s - %s", tarball.URL, response.Status)
+	}
+	return response.Body, nil
+}
+
+// newDownloadClient
+// Returns HTTP client with the download timeouts.
+func newDownloadClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = downloadResponseTimeoutConst
+	return &http.Client{
+		Transport: transport,
+		Timeout:   downloadTimeoutConst,
+	}
+}
+
+// verifyChecksum
+// Returns error if the SHA-256 checksum of the file is not expectedChecksum.
+func verifyChecksum(filePath string, expectedChecksum string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return err
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if checksum != expectedChecksum {
+		return fmt.Errorf("checksum mismatch of %s - expected %s, got %s", filePath, expectedChecksum, checksum)
+	}
+	return nil
+}
+
+func (tarball *Tarball) ConstructCMDLine() []string {
+	validateSourcePath(tarball.ArchivePath)
+	validateSourcePath(tarball.ExtractPath)
+	cmdExtract := []string{
+		"tar",
+		"-xf", escapeValue(tarball.ArchivePath),
+		"-C", escapeValue(tarball.ExtractPath),
+	}
+	if tarball.StripComponents > 0 {
+		cmdExtract = append(cmdExtract, "--strip-components="+strconv.Itoa(tarball.StripComponents))
+	}
+	return []string{
+		"mkdir -p " + escapeValue(tarball.ExtractPath),
+		strings.Join(cmdExtract, " "),
+	}
+}
+
+func validateSourcePath(path string) {
+	if path == "" {
+		panic(fmt.Errorf("source path is empty"))
+	}
+}
+
+func escapeValue(value string) string {
+	return "\"" + value + "\""
+}
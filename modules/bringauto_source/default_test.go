@@ -0,0 +1,148 @@
+package bringauto_source_test
+
+import (
+	"bringauto/modules/bringauto_source"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const (
+	tarballContent = "tarball content"
+)
+
+// createTarball
+// Creates file with tarballContent in a temporary directory and returns Tarball which refers to it.
+func createTarball(t *testing.T) *bringauto_source.Tarball {
+	archivePath := filepath.Join(t.TempDir(), "pack1-1.0.0.tar.gz")
+	err := os.WriteFile(archivePath, []byte(tarballContent), 0644)
+	if err != nil {
+		t.Fatalf("can't create tarball - %s", err)
+	}
+	hash := sha256.Sum256([]byte(tarballContent))
+	return &bringauto_source.Tarball{
+		URL:    "file://" + archivePath,
+		SHA256: hex.EncodeToString(hash[:]),
+	}
+}
+
+func TestTarball_Fetch(t *testing.T) {
+	tarball := createTarball(t)
+	downloadDir := t.TempDir()
+	archivePath, err := tarball.Fetch(downloadDir)
+	if err != nil {
+		t.Fatalf("Fetch failed - %s", err)
+	}
+	content, err := os.ReadFile(archivePath)
+	if err != nil || string(content) != tarballContent {
+		t.Errorf("wrong downloaded tarball %s", archivePath)
+	}
+	if filepath.Base(archivePath) != tarball.SHA256+"-pack1-1.0.0.tar.gz" {
+		t.Errorf("wrong tarball name %s", archivePath)
+	}
+}
+
+func TestTarball_FetchHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(tarballContent))
+	}))
+	defer server.Close()
+	tarball := createTarball(t)
+	tarball.URL = server.URL + "/pack1-1.0.0.tar.gz"
+	archivePath, err := tarball.Fetch(t.TempDir())
+	if err != nil {
+		t.Fatalf("Fetch failed - %s", err)
+	}
+	content, err := os.ReadFile(archivePath)
+	if err != nil || string(content) != tarballContent {
+		t.Errorf("wrong downloaded archive - %s", content)
+	}
+}
+
+func TestTarball_FetchChecksumMismatch(t *testing.T) {
+	tarball := createTarball(t)
+	tarball.SHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	downloadDir := t.TempDir()
+	_, err := tarball.Fetch(downloadDir)
+	if err == nil {
+		t.Fatal("checksum mismatch not detected")
+	}
+	entries, _ := os.ReadDir(downloadDir)
+	if len(entries) != 0 {
+		t.Errorf("download directory not clean after failed fetch - %v", entries)
+	}
+}
+
+func TestTarball_CheckPrerequisites(t *testing.T) {
+	tarball := bringauto_source.Tarball{
+		URL:    "ftp://example.com/pack1.tar.gz",
+		SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}
+	if tarball.CheckPrerequisites(nil) == nil {
+		t.Error("unsupported URL scheme not detected")
+	}
+	tarball.URL = "https://example.com/pack1.tar.gz"
+	tarball.SHA256 = ""
+	if tarball.CheckPrerequisites(nil) == nil {
+		t.Error("missing checksum not detected")
+	}
+}
+
+func TestTarball_ConstructCMDLine(t *testing.T) {
+	tarball := bringauto_source.Tarball{
+		StripComponents: 1,
+		ArchivePath:     "/source_archive/pack1.tar.gz",
+		ExtractPath:     "/git",
+	}
+	validCmdLine := []string{
+		"mkdir -p \"/git\"",
+		"tar -xf \"/source_archive/pack1.tar.gz\" -C \"/git\" --strip-components=1",
+	}
+	if !reflect.DeepEqual(tarball.ConstructCMDLine(), validCmdLine) {
+		t.Errorf("tarball CMD line is not valid! %s", tarball.ConstructCMDLine())
+	}
+}
+
+func TestLocalDirectory_GetContentHash(t *testing.T) {
+	local := bringauto_source.LocalDirectory{Path: t.TempDir()}
+	err := os.WriteFile(filepath.Join(local.Path, "file.txt"), []byte("a"), 0644)
+	if err != nil {
+		t.Fatalf("can't create file - %s", err)
+	}
+	hash1, err := local.GetContentHash()
+	if err != nil {
+		t.Fatalf("GetContentHash failed - %s", err)
+	}
+	hash2, _ := local.GetContentHash()
+	if hash1 != hash2 {
+		t.Error("content hash is not stable")
+	}
+	err = os.WriteFile(filepath.Join(local.Path, "file.txt"), []byte("b"), 0644)
+	if err != nil {
+		t.Fatalf("can't update file - %s", err)
+	}
+	hash3, _ := local.GetContentHash()
+	if hash1 == hash3 {
+		t.Error("content hash not changed after file change")
+	}
+}
+
+func TestLocalDirectory_ConstructCMDLine(t *testing.T) {
+	local := bringauto_source.LocalDirectory{
+		Path:      "/home/user/pack1",
+		MountPath: "/source_local",
+		CopyPath:  "/git",
+	}
+	validCmdLine := []string{
+		"mkdir -p \"/git\"",
+		"cp -a \"/source_local/.\" \"/git\"",
+	}
+	if !reflect.DeepEqual(local.ConstructCMDLine(), validCmdLine) {
+		t.Errorf("local directory CMD line is not valid! %s", local.ConstructCMDLine())
+	}
+}
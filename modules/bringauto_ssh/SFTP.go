@@ -1,7 +1,6 @@
 package bringauto_ssh
 
 import (
-	"bringauto/modules/bringauto_const"
 	"bringauto/modules/bringauto_prerequisites"
 	"bufio"
 	"fmt"
@@ -36,7 +35,7 @@ type SFTP struct {
 func (sftpd *SFTP) DownloadDirectory() error {
 	var err error
 
-	tar := bringauto_prerequisites.CreateAndInitialize[Tar](archiveName, bringauto_const.DockerInstallDirConst)
+	tar := bringauto_prerequisites.CreateAndInitialize[Tar](archiveName, sftpd.RemoteDir)
 
 	shellEvaluator := ShellEvaluator{
 		Commands: tar.ConstructCMDLine(),
@@ -46,7 +45,7 @@ func (sftpd *SFTP) DownloadDirectory() error {
 	err = shellEvaluator.RunOverSSH(*sftpd.SSHCredentials)
 
 	if err != nil {
-		return fmt.Errorf("cannot archive %s dir in docker container - %s", bringauto_const.DockerInstallDirConst, err)
+		return fmt.Errorf("cannot archive %s dir in docker container - %s", sftpd.RemoteDir, err)
 	}
 
 	sshSession := SSHSession{}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"slices"
 )
 
 const (
@@ -18,8 +19,16 @@ type BuiltPackages struct {
 }
 
 // AddToBuiltPackages
-// Adds packageName to built Packages.
+// Adds packageName to built Packages if it is not there yet. The built_packages.json is read
+// before the update, so Packages added by other Sysroot instances are preserved.
 func (builtPackages *BuiltPackages) AddToBuiltPackages(packageName string) error {
+	err := builtPackages.UpdateBuiltPackages()
+	if err != nil {
+		return err
+	}
+	if slices.Contains(builtPackages.Packages, packageName) {
+		return nil
+	}
 	builtPackages.Packages = append(builtPackages.Packages, packageName)
 	bytes, err := json.Marshal(builtPackages.Packages)
 	if err != nil {
@@ -7,27 +7,36 @@ import (
 	"fmt"
 	"github.com/otiai10/copy"
 	"os"
-	"io"
 	"io/fs"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"slices"
 )
 
 const (
 	sysrootDirectoryName = "install_sysroot"
+	// Directory in sysrootDirectoryName where installed files of each built Package are stored
+	packagesDirectoryName = "packages"
 	// Constant for number of problematic files which will be printed when trying to overwrite files
 	// in sysroot
 	listFilesCount = 10
 )
 
 // Sysroot represents a standard Linux sysroot with all needed libraries installed.
-// Sysroot for each build type (Release, Debug) the separate sysroot is created
+// Sysroot for each build type (Release, Debug) the separate sysroot is created.
+//
+// Installed files of each built Package (each version separately) are stored in the packages
+// directory. The sysroot directory used by a build is filled by FillSysroot with only the Packages
+// the build depends on, so different versions of one Package never meet in one sysroot.
 type Sysroot struct {
 	// IsDebug - if true, it marks given sysroot as a sysroot with Debud builds
 	IsDebug bool
 	// PlatformString
 	PlatformString *bringauto_package.PlatformString
+	// Slot of the build which uses the sysroot. Builds running at the same time must use different
+	// slots, the sysroot directory of the slot 0 has no suffix
+	Slot int
 	builtPackages BuiltPackages
 }
 
@@ -50,9 +59,13 @@ func (sysroot *Sysroot) CheckPrerequisites(args *bringauto_prerequisites.Args) e
 	return nil
 }
 
-// CopyToSysroot copy source to a sysroot
+// CopyToSysroot
+// Stores source as installed files of the Package packageName (the full package name). Files
+// stored before for the same Package are replaced. The Package is copied to the sysroot directory
+// by FillSysroot.
 func (sysroot *Sysroot) CopyToSysroot(source string, packageName string) error {
-	err := sysroot.checkForOverwritingFiles(source)
+	packagePath := sysroot.getPackagePath(packageName)
+	err := os.RemoveAll(packagePath)
 	if err != nil {
 		return err
 	}
@@ -61,7 +74,7 @@ func (sysroot *Sysroot) CopyToSysroot(source string, packageName string) error {
 		PreserveOwner: true,
 		PreserveTimes: true,
 	}
-	err = copy.Copy(source, sysroot.GetSysrootPath(), copyOptions)
+	err = copy.Copy(source, packagePath, copyOptions)
 	if err != nil {
 		return err
 	}
@@ -72,13 +85,70 @@ func (sysroot *Sysroot) CopyToSysroot(source string, packageName string) error {
 	return nil
 }
 
+// FillSysroot
+// Recreates the sysroot directory with installed files of the given Packages (full package names)
+// only. Returns error if a Package is not stored by CopyToSysroot or if the Packages install
+// the same file.
+func (sysroot *Sysroot) FillSysroot(packageNames []string) error {
+	sysrootPath := sysroot.GetSysrootPath()
+	err := os.RemoveAll(sysrootPath)
+	if err != nil {
+		return err
+	}
+	sysroot.CreateSysrootDir()
+	copyOptions := copy.Options{
+		OnSymlink:     onSymlink,
+		PreserveOwner: true,
+		PreserveTimes: true,
+	}
+	for _, packageName := range packageNames {
+		if !sysroot.IsPackageStored(packageName) {
+			return fmt.Errorf("package %s is not in sysroot", packageName)
+		}
+		packagePath := sysroot.getPackagePath(packageName)
+		err = sysroot.checkForOverwritingFiles(packagePath)
+		if err != nil {
+			return fmt.Errorf("cannot copy %s to sysroot - %s", packageName, err)
+		}
+		err = copy.Copy(packagePath, sysrootPath, copyOptions)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckPackageFiles
+// Returns error if installed files of a Package in source would overwrite files in the sysroot
+// directory. A built Package is checked against the sysroot filled by FillSysroot for its build,
+// so it must not install files of its dependencies.
+func (sysroot *Sysroot) CheckPackageFiles(source string) error {
+	return sysroot.checkForOverwritingFiles(source)
+}
+
 // IsPackageInSysroot
-// Returns true if packageName is built in sysroot, else false.
-func (sysroot *Sysroot) IsPackageInSysroot(packageName string) bool {
-	if slices.Contains(sysroot.builtPackages.Packages, packageName) {
-		return true
+// Returns true if pack is built in sysroot, else false. Sysroots created by older versions of
+// the packager recorded only short package names, these are accepted too.
+func (sysroot *Sysroot) IsPackageInSysroot(pack bringauto_package.Package) bool {
+	return slices.Contains(sysroot.builtPackages.Packages, pack.GetFullPackageName()) ||
+		slices.Contains(sysroot.builtPackages.Packages, pack.GetShortPackageName())
+}
+
+// IsPackageStored
+// Returns true if installed files of packageName (the full package name) are stored by
+// CopyToSysroot, else false. Packages recorded by older versions of the packager are not stored.
+func (sysroot *Sysroot) IsPackageStored(packageName string) bool {
+	if !slices.Contains(sysroot.builtPackages.Packages, packageName) {
+		return false
 	}
-	return false
+	_, err := os.Stat(sysroot.getPackagePath(packageName))
+	return err == nil
+}
+
+// IsEmpty
+// Returns true if no Package is built in sysroot, else false.
+func (sysroot *Sysroot) IsEmpty() bool {
+	return len(sysroot.builtPackages.Packages) == 0
 }
 
 // checkForOverwritingFiles
@@ -111,8 +181,9 @@ func (sysroot *Sysroot) printOverwriteFilesError(problematicFiles []string, n in
 	logger := bringauto_log.GetLogger()
 	logger.Error("Trying to overwrite files in sysroot - sysroot consistency interrupted.")
 	logger.Error("Listing first %d problematic files:", n)
+	sysrootDirName := sysrootDirectoryName + "/" + filepath.Base(sysroot.GetSysrootPath())
 	for i, filePath := range problematicFiles {
-		logger.ErrorIndent(sysrootDirectoryName + "/" + sysroot.PlatformString.Serialize() + filePath)
+		logger.ErrorIndent(sysrootDirName + filePath)
 		if i == n - 1 {
 			break
 		}
@@ -132,11 +203,24 @@ func (sysroot *Sysroot) GetSysrootPath() string {
 	if sysroot.IsDebug {
 		sysrootDirName += "_debug"
 	}
+	if sysroot.Slot > 0 {
+		sysrootDirName += "_" + strconv.Itoa(sysroot.Slot)
+	}
 
 	sysrootDir := filepath.Join(workingDir, sysrootDirectoryName, sysrootDirName)
 	return sysrootDir
 }
 
+// getPackagePath
+// Returns absolute path to the directory with stored installed files of packageName.
+func (sysroot *Sysroot) getPackagePath(packageName string) string {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		panic(fmt.Errorf("cannot call Getwd - %s", err))
+	}
+	return filepath.Join(workingDir, sysrootDirectoryName, packagesDirectoryName, packageName)
+}
+
 // CreateSysrootDir
 // Creates a Sysroot dir. If not succeed the panic occurrs.
 func (sysroot *Sysroot) CreateSysrootDir() {
@@ -150,26 +234,6 @@ func (sysroot *Sysroot) CreateSysrootDir() {
 	}
 }
 
-// IsSysrootDirectoryEmpty
-// Returns true if specified dir do not exists or exists but is empty, otherwise returns false.
-func (sysroot *Sysroot) IsSysrootDirectoryEmpty() bool {
-	f, err := os.Open(sysroot.GetSysrootPath())
-	if err != nil { // The directory do not exists
-		return true
-	}
-	defer f.Close()
-
-	_, err = f.Readdirnames(1)
-
-	if err == io.EOF { // The directory exists, but is empty
-		return true
-	} else if err != nil {
-		bringauto_log.GetLogger().Warn("Sysroot directory is not readable: %s", err)
-	}
-
-	return false
-}
-
 func onSymlink(src string) copy.SymlinkAction {
 	return copy.Shallow
 }
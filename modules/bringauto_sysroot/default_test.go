@@ -99,12 +99,35 @@ func TestCreateSysrootDir(t *testing.T) {
 	}
 }
 
+func TestGetSysrootPathSlot(t *testing.T) {
+	sysroot := Sysroot {
+		IsDebug: true,
+		PlatformString: &defaultPlatformString,
+		Slot: 2,
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("can't get workinǵ dir - %s", err)
+	}
+	testPath := filepath.Join(workingDir, sysrootDirectoryName, defaultSysroot.PlatformString.Serialize() + "_debug_2")
+
+	if sysroot.GetSysrootPath() != testPath {
+		t.Fail()
+	}
+}
+
 func TestCopyToSysrootOnePackage(t *testing.T) {
 	err := defaultSysroot.CopyToSysroot(bringauto_testing.Pack1Name, bringauto_testing.Pack1Name)
 	if err != nil {
 		t.Errorf("CopyToSysroot failed - %s", err)
 	}
 
+	err = defaultSysroot.FillSysroot([]string{bringauto_testing.Pack1Name})
+	if err != nil {
+		t.Errorf("FillSysroot failed - %s", err)
+	}
+
 	pack1Path := filepath.Join(defaultSysroot.GetSysrootPath(), bringauto_testing.Pack1FileName)
 	_, err = os.ReadFile(pack1Path)
 	if os.IsNotExist(err) {
@@ -133,6 +156,11 @@ func TestCopyToSysrootMultiplePackages(t *testing.T) {
 		t.Errorf("CopyToSysroot failed - %s", err)
 	}
 
+	err = defaultSysroot.FillSysroot([]string{bringauto_testing.Pack1Name, bringauto_testing.Pack3Name})
+	if err != nil {
+		t.Errorf("FillSysroot failed - %s", err)
+	}
+
 	pack1Path := filepath.Join(defaultSysroot.GetSysrootPath(), bringauto_testing.Pack1FileName)
 	_, err = os.ReadFile(pack1Path)
 	if os.IsNotExist(err) {
@@ -141,8 +169,8 @@ func TestCopyToSysrootMultiplePackages(t *testing.T) {
 
 	pack2Path := filepath.Join(defaultSysroot.GetSysrootPath(), bringauto_testing.Pack2FileName)
 	_, err = os.ReadFile(pack2Path)
-	if os.IsNotExist(err) {
-		t.Fail()
+	if !os.IsNotExist(err) {
+		t.Error("package which is not requested is in sysroot")
 	}
 
 	pack3Path := filepath.Join(defaultSysroot.GetSysrootPath(), bringauto_testing.Pack3FileName)
@@ -157,13 +185,55 @@ func TestCopyToSysrootMultiplePackages(t *testing.T) {
 	}
 }
 
-func TestCopyToSysrootOvewriteFiles(t *testing.T) {
+func TestCopyToSysrootReplacePackage(t *testing.T) {
 	err := defaultSysroot.CopyToSysroot(bringauto_testing.Pack1Name, bringauto_testing.Pack1Name)
 	if err != nil {
 		t.Errorf("CopyToSysroot failed - %s", err)
 	}
 
-	err = defaultSysroot.CopyToSysroot(bringauto_testing.Pack1Name, bringauto_testing.Pack1Name)
+	err = defaultSysroot.CopyToSysroot(bringauto_testing.Pack2Name, bringauto_testing.Pack1Name)
+	if err != nil {
+		t.Errorf("CopyToSysroot of rebuilt package failed - %s", err)
+	}
+
+	err = defaultSysroot.FillSysroot([]string{bringauto_testing.Pack1Name})
+	if err != nil {
+		t.Errorf("FillSysroot failed - %s", err)
+	}
+
+	_, err = os.ReadFile(filepath.Join(defaultSysroot.GetSysrootPath(), bringauto_testing.Pack1FileName))
+	if !os.IsNotExist(err) {
+		t.Error("files of the replaced package are in sysroot")
+	}
+	_, err = os.ReadFile(filepath.Join(defaultSysroot.GetSysrootPath(), bringauto_testing.Pack2FileName))
+	if os.IsNotExist(err) {
+		t.Error("files of the rebuilt package are not in sysroot")
+	}
+
+	err = clearSysroot()
+	if err != nil {
+		t.Errorf("can't delete sysroot dir - %s", err)
+	}
+}
+
+func TestFillSysrootOvewriteFiles(t *testing.T) {
+	// Two versions of one package install the same files
+	err := defaultSysroot.CopyToSysroot(bringauto_testing.Pack1Name, bringauto_testing.Pack1Name + "_v1")
+	if err != nil {
+		t.Errorf("CopyToSysroot failed - %s", err)
+	}
+
+	err = defaultSysroot.CopyToSysroot(bringauto_testing.Pack1Name, bringauto_testing.Pack1Name + "_v2")
+	if err != nil {
+		t.Errorf("CopyToSysroot of another version failed - %s", err)
+	}
+
+	err = defaultSysroot.FillSysroot([]string{bringauto_testing.Pack1Name + "_v2"})
+	if err != nil {
+		t.Errorf("FillSysroot with one version failed - %s", err)
+	}
+
+	err = defaultSysroot.FillSysroot([]string{bringauto_testing.Pack1Name + "_v1", bringauto_testing.Pack1Name + "_v2"})
 	if err == nil {
 		t.Error("ovewriting files not detected")
 	}
@@ -174,31 +244,77 @@ func TestCopyToSysrootOvewriteFiles(t *testing.T) {
 	}
 }
 
+func TestCheckPackageFiles(t *testing.T) {
+	err := defaultSysroot.CopyToSysroot(bringauto_testing.Pack1Name, bringauto_testing.Pack1Name)
+	if err != nil {
+		t.Errorf("CopyToSysroot failed - %s", err)
+	}
+
+	err = defaultSysroot.FillSysroot([]string{bringauto_testing.Pack1Name})
+	if err != nil {
+		t.Errorf("FillSysroot failed - %s", err)
+	}
+
+	err = defaultSysroot.CheckPackageFiles(bringauto_testing.Pack2Name)
+	if err != nil {
+		t.Errorf("CheckPackageFiles failed - %s", err)
+	}
+
+	// Built package installs the same files as its dependency
+	err = defaultSysroot.CheckPackageFiles(bringauto_testing.Pack1Name)
+	if err == nil {
+		t.Error("ovewriting files not detected")
+	}
+
+	err = clearSysroot()
+	if err != nil {
+		t.Errorf("can't delete sysroot dir - %s", err)
+	}
+}
+
+func TestFillSysrootNotStoredPackage(t *testing.T) {
+	err := defaultSysroot.FillSysroot([]string{bringauto_testing.Pack1Name})
+	if err == nil {
+		t.Error("FillSysroot succeeded with not stored package")
+	}
+
+	err = clearSysroot()
+	if err != nil {
+		t.Errorf("can't delete sysroot dir - %s", err)
+	}
+}
+
 func TestIsPackageInSysroot(t *testing.T) {
 	sysroot := Sysroot {
 		IsDebug: false,
 		PlatformString: &defaultPlatformString,
 	}
-	err := bringauto_prerequisites.Initialize(&defaultSysroot)
+	err := bringauto_prerequisites.Initialize(&sysroot)
 	if err != nil {
 		t.Fatalf("sysroot initialization failed - %s", err)
 	}
 
-	err = sysroot.CopyToSysroot(bringauto_testing.Pack1Name, bringauto_testing.Pack1Name)
+	pack1 := newTestPackage(bringauto_testing.Pack1Name)
+	pack2 := newTestPackage(bringauto_testing.Pack2Name)
+	err = sysroot.CopyToSysroot(bringauto_testing.Pack1Name, pack1.GetFullPackageName())
 	if err != nil {
 		t.Errorf("CopyToSysroot failed - %s", err)
 	}
 
-	if !sysroot.IsPackageInSysroot(bringauto_testing.Pack1Name) {
+	if !sysroot.IsPackageInSysroot(pack1) {
 		t.Error("IsPackageInSysroot returned false after copying package to sysroot")
 	}
+	if !sysroot.IsPackageStored(pack1.GetFullPackageName()) {
+		t.Error("IsPackageStored returned false after copying package to sysroot")
+	}
 
-	if sysroot.IsPackageInSysroot(bringauto_testing.Pack2Name) {
+	if sysroot.IsPackageInSysroot(pack2) {
 		t.Error("IsPackageInSysroot returned true for not copied package")
 	}
 
-	if sysroot.IsPackageInSysroot(bringauto_testing.Pack3Name) {
-		t.Error("IsPackageInSysroot returned true for not copied package")
+	pack1.VersionTag = "v2.0.0"
+	if sysroot.IsPackageInSysroot(pack1) {
+		t.Error("IsPackageInSysroot returned true for not copied version")
 	}
 
 	err = clearSysroot()
@@ -207,6 +323,48 @@ func TestIsPackageInSysroot(t *testing.T) {
 	}
 }
 
+func TestIsPackageInSysrootLegacyName(t *testing.T) {
+	sysroot := Sysroot {
+		IsDebug: false,
+		PlatformString: &defaultPlatformString,
+	}
+	err := bringauto_prerequisites.Initialize(&sysroot)
+	if err != nil {
+		t.Fatalf("sysroot initialization failed - %s", err)
+	}
+
+	// Older versions of the packager recorded short package names
+	pack := newTestPackage(bringauto_testing.Pack1Name)
+	err = os.MkdirAll(sysrootDirectoryName, 0755)
+	if err != nil {
+		t.Fatalf("can't create sysroot dir - %s", err)
+	}
+	err = sysroot.builtPackages.AddToBuiltPackages(pack.GetShortPackageName())
+	if err != nil {
+		t.Fatalf("AddToBuiltPackages failed - %s", err)
+	}
+
+	if !sysroot.IsPackageInSysroot(pack) {
+		t.Error("IsPackageInSysroot returned false for package recorded by short name")
+	}
+	if sysroot.IsPackageStored(pack.GetFullPackageName()) {
+		t.Error("IsPackageStored returned true for package recorded by short name")
+	}
+
+	err = clearSysroot()
+	if err != nil {
+		t.Errorf("can't delete sysroot dir - %s", err)
+	}
+}
+
+func newTestPackage(name string) bringauto_package.Package {
+	return bringauto_package.Package{
+		Name:           name,
+		VersionTag:     "v1.0.0",
+		PlatformString: defaultPlatformString,
+	}
+}
+
 func clearSysroot() error {
 	sysrootPath := defaultSysroot.GetSysrootPath()
 	return os.RemoveAll(filepath.Dir(sysrootPath))